@@ -6,11 +6,19 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
 	"github.com/b3nk3/bifrost/internal/sso"
 	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
 	"github.com/spf13/cobra"
 )
 
@@ -34,11 +42,12 @@ Examples:
 		prompt := ui.NewPrompt()
 
 		profileName, _ := cmd.Flags().GetString("profile")
+		authTimeout, _ := cmd.Flags().GetDuration("auth-timeout")
 
 		// Load existing profiles
 		cfg, err := cfgManager.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			output.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -50,31 +59,26 @@ Examples:
 
 		// If no profile specified, let user select
 		if profileName == "" {
-			profileNames := make([]string, 0, len(cfg.SSOProfiles))
-			for name := range cfg.SSOProfiles {
-				profileNames = append(profileNames, name)
-			}
-
-			selected, err := prompt.Select("Select SSO profile to login with", profileNames)
+			selected, err := promptSSOProfile(prompt, "Select SSO profile to login with", cfg.SSOProfiles)
 			if err != nil {
-				fmt.Printf("Error selecting profile: %v\n", err)
+				output.Printf("Error selecting profile: %v\n", err)
 				os.Exit(1)
 			}
 			profileName = selected
 		}
 
 		// Get the selected profile
-		ssoProfile, err := cfgManager.GetSSOProfile(profileName)
+		ssoProfile, err := cfg.SSOProfile(profileName)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			output.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Perform authentication
-		fmt.Printf("🔐 Authenticating with profile '%s'...\n", profileName)
+		output.Printf("🔐 Authenticating with profile '%s'...\n", profileName)
 
 		ctx := context.Background()
-		ssoClient := sso.NewClient(ssoProfile.SSORegion, ssoProfile.StartURL)
+		ssoClient := connect.NewSSOClient(ssoProfile, authTimeout, connect.TokenCacheOptions(cfg)...)
 
 		// Authenticate and get token
 		_, err = ssoClient.Authenticate(ctx)
@@ -83,7 +87,7 @@ Examples:
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ Successfully authenticated with profile '%s'\n", profileName)
+		output.Printf("✅ Successfully authenticated with profile '%s'\n", profileName)
 	},
 }
 
@@ -92,9 +96,20 @@ var authConfigureCmd = &cobra.Command{
 	Short: "Create or update SSO profile configuration",
 	Long: `Create or update SSO profile configuration (SSO URL and region).
 
+With --import-from-aws, the sso-session blocks and SSO profiles in ~/.aws/config (or
+AWS_CONFIG_FILE) are offered for import instead, each as an SSO profile of the same name.
+
+--client-name and --scopes change how bifrost registers itself with IAM Identity Center for
+device logins, e.g. when your organisation audits client names or requires specific scopes.
+
 Examples:
   bifrost auth configure --profile work --sso-url https://company.awsapps.com/start --sso-region us-east-1
-  bifrost auth configure --profile work`,
+  bifrost auth configure --profile work
+  bifrost auth configure --profile work --description "Company Production SSO"
+  bifrost auth configure --profile work --default-region eu-west-1
+  bifrost auth configure --profile work --default-service rds --default-port 5432
+  bifrost auth configure --profile work --client-name bifrost-platform --scopes sso:account:access
+  bifrost auth configure --import-from-aws`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfgManager := config.NewManager()
 		prompt := ui.NewPrompt()
@@ -103,12 +118,35 @@ Examples:
 		ssoURL, _ := cmd.Flags().GetString("sso-url")
 		ssoRegion, _ := cmd.Flags().GetString("sso-region")
 		noAutoDetect, _ := cmd.Flags().GetBool("no-auto-detect")
+		importFromAWS, _ := cmd.Flags().GetBool("import-from-aws")
+		clientName, _ := cmd.Flags().GetString("client-name")
+		scopes, _ := cmd.Flags().GetStringSlice("scopes")
+		description, _ := cmd.Flags().GetString("description")
+		defaultRegion, _ := cmd.Flags().GetString("default-region")
+		defaultService, _ := cmd.Flags().GetString("default-service")
+		defaultPort, _ := cmd.Flags().GetString("default-port")
+
+		if importFromAWS {
+			importAWSSSOSessions(cfgManager, prompt)
+			return
+		}
+
+		if defaultService != "" && !slices.Contains(connect.ServiceTypes, defaultService) {
+			fmt.Printf("Invalid default service '%s'. Must be one of: %s.\n", defaultService, strings.Join(connect.ServiceTypes, ", "))
+			os.Exit(1)
+		}
+		if defaultPort != "" {
+			if err := validateRemotePort(defaultPort); err != nil {
+				output.Printf("Error: invalid default port: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
 		// Prompt for profile name if not provided
 		if profileName == "" {
 			result, err := prompt.Input("Profile name", nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			profileName = result
@@ -123,12 +161,15 @@ Examples:
 			if existingProfile != nil {
 				defaultValue = existingProfile.StartURL
 			}
-			result, err := prompt.Input("SSO Start URL (e.g. https://a-123456789.awsapps.com/start)", nil, defaultValue)
+			result, err := prompt.Input("SSO Start URL (e.g. https://a-123456789.awsapps.com/start)", validateSSOURL, defaultValue)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			ssoURL = result
+		} else if err := validateSSOURL(ssoURL); err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
 
 		// Prompt for SSO region if not provided
@@ -138,36 +179,71 @@ Examples:
 				defaultValue = existingProfile.SSORegion
 			} else if ssoURL != "" && !noAutoDetect {
 				// Try to auto-detect region from SSO URL
-				fmt.Printf("🔍 Auto-detecting SSO region from URL...\n")
-				if detectedRegion, err := sso.ExtractRegionFromSSO(ssoURL); err == nil {
+				output.Printf("🔍 Auto-detecting SSO region from URL...\n")
+				var caBundle string
+				if globalCfg, err := cfgManager.LoadGlobal(); err == nil {
+					caBundle = globalCfg.CABundle
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				detectedRegion, err := sso.ExtractRegionFromSSO(ctx, ssoURL, sso.CABundle(caBundle))
+				stop()
+				if err == nil {
 					defaultValue = detectedRegion
-					fmt.Printf("✅ Detected SSO region: %s\n", detectedRegion)
+					output.Printf("✅ Detected SSO region: %s\n", detectedRegion)
 				} else {
-					fmt.Printf("⚠️ Could not auto-detect region: %v\n", err)
+					output.Printf("⚠️ Could not auto-detect region: %v\n", err)
 				}
 			}
 
 			result, err := prompt.Input("SSO region (e.g. us-east-1)", nil, defaultValue)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			ssoRegion = result
 		}
 
+		// Keep the description, defaults and client registration when reconfiguring without the flags
+		if existingProfile != nil {
+			if !cmd.Flags().Changed("description") {
+				description = existingProfile.Description
+			}
+			if !cmd.Flags().Changed("default-region") {
+				defaultRegion = existingProfile.DefaultRegion
+			}
+			if !cmd.Flags().Changed("default-service") {
+				defaultService = existingProfile.DefaultService
+			}
+			if !cmd.Flags().Changed("default-port") {
+				defaultPort = existingProfile.DefaultPort
+			}
+			if !cmd.Flags().Changed("client-name") {
+				clientName = existingProfile.ClientName
+			}
+			if !cmd.Flags().Changed("scopes") {
+				scopes = existingProfile.Scopes
+			}
+		}
+
 		// Create SSO profile
 		ssoProfile := config.SSOProfile{
-			StartURL:  ssoURL,
-			SSORegion: ssoRegion,
+			StartURL:       ssoURL,
+			SSORegion:      ssoRegion,
+			Description:    description,
+			DefaultRegion:  defaultRegion,
+			DefaultService: defaultService,
+			DefaultPort:    defaultPort,
+			ClientName:     clientName,
+			Scopes:         scopes,
 		}
 
 		// Save the profile
 		if err := cfgManager.AddSSOProfile(profileName, ssoProfile); err != nil {
-			fmt.Printf("Error saving profile: %v\n", err)
+			output.Printf("Error saving profile: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ SSO profile '%s' configured\n", profileName)
+		output.Printf("✅ SSO profile '%s' configured\n", profileName)
 		fmt.Println("Use 'bifrost auth login' to authenticate with this profile.")
 	},
 }
@@ -180,52 +256,305 @@ var authListCmd = &cobra.Command{
 		cfgManager := config.NewManager()
 		cfg, err := cfgManager.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			output.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
+		if isJSONOutput(cmd) {
+			printJSON(cfg.SSOProfiles)
+			return
+		}
+
 		if len(cfg.SSOProfiles) == 0 {
 			fmt.Println("No SSO profiles configured. Use 'bifrost auth configure' to create one.")
 			return
 		}
 
-		fmt.Println("📋 SSO Profiles:")
+		output.Println("📋 SSO Profiles:")
 		for name, profile := range cfg.SSOProfiles {
-			fmt.Printf("  • %s\n", name)
+			output.Printf("  • %s\n", name)
+			if profile.Description != "" {
+				fmt.Printf("    Description: %s\n", profile.Description)
+			}
 			fmt.Printf("    SSO URL: %s\n", profile.StartURL)
 			fmt.Printf("    Region: %s\n", profile.SSORegion)
+			if profile.DefaultRegion != "" {
+				fmt.Printf("    Default workload region: %s\n", profile.DefaultRegion)
+			}
+			if profile.DefaultService != "" {
+				fmt.Printf("    Default service: %s\n", profile.DefaultService)
+			}
+			if profile.DefaultPort != "" {
+				fmt.Printf("    Default port: %s\n", profile.DefaultPort)
+			}
 			fmt.Println()
 		}
 	},
 }
 
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show cached SSO token validity per profile",
+	Long: `Show whether each SSO profile has a cached token, when it expires and how long it remains valid.
+
+Examples:
+  bifrost auth status
+  bifrost auth status --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		cfg, err := cfgManager.Load()
+		if err != nil {
+			output.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.SSOProfiles) == 0 && !isJSONOutput(cmd) {
+			fmt.Println("No SSO profiles configured. Use 'bifrost auth configure' to create one.")
+			return
+		}
+
+		type tokenStatus struct {
+			Profile      string     `json:"profile"`
+			HasToken     bool       `json:"has_token"`
+			Valid        bool       `json:"valid"`
+			Refreshable  bool       `json:"refreshable"`
+			ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+			RemainingSec int64      `json:"remaining_seconds"`
+		}
+
+		names := make([]string, 0, len(cfg.SSOProfiles))
+		for name := range cfg.SSOProfiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		statuses := make([]tokenStatus, 0, len(names))
+		for _, name := range names {
+			status := tokenStatus{Profile: name}
+			token, err := sso.LoadTokenCache(cfg.SSOProfiles[name].StartURL)
+			if err != nil {
+				output.Fprintf(os.Stderr, "⚠️ Warning: failed to read token cache for '%s': %v\n", name, err)
+			}
+			if token != nil {
+				remaining := time.Until(token.ExpiresAt)
+				status.HasToken = true
+				status.Valid = remaining > 0
+				status.Refreshable = token.Refreshable()
+				status.ExpiresAt = &token.ExpiresAt
+				status.RemainingSec = max(int64(remaining.Seconds()), 0)
+			}
+			statuses = append(statuses, status)
+		}
+
+		if isJSONOutput(cmd) {
+			printJSON(statuses)
+			return
+		}
+
+		output.Println("🔐 SSO Token Status:")
+		for _, status := range statuses {
+			switch {
+			case !status.HasToken:
+				output.Printf("  ⚪ %s: not logged in\n", status.Profile)
+			case status.Valid:
+				remaining := time.Duration(status.RemainingSec) * time.Second
+				output.Printf("  ✅ %s: valid for %s (expires %s)\n", status.Profile, remaining, status.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+			default:
+				note := ""
+				if status.Refreshable {
+					note = ", will refresh on next use"
+				}
+				output.Printf("  ❌ %s: expired %s%s\n", status.Profile, status.ExpiresAt.Local().Format("2006-01-02 15:04:05"), note)
+			}
+		}
+	},
+}
+
 var authLogoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Clear cached SSO tokens",
-	Long:  `Clear cached SSO tokens for all profiles.`,
+	Long: `Clear the cached SSO token for one profile. If no profile is specified, you'll be prompted to select one.
+Use --all to clear every cached SSO token, including ones created by the AWS CLI.
+
+Examples:
+  bifrost auth logout --profile work
+  bifrost auth logout --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		prompt := ui.NewPrompt()
+
+		profileName, _ := cmd.Flags().GetString("profile")
+		allFlag, _ := cmd.Flags().GetBool("all")
+
+		if allFlag {
+			// Clear token cache
+			if err := sso.ClearTokenCache(); err != nil {
+				output.Printf("Error clearing token cache: %v\n", err)
+				os.Exit(1)
+			}
+			output.Println("✅ Token cache cleared")
+			return
+		}
+
+		if profileName == "" {
+			profileName = selectSSOProfile(cfgManager, prompt)
+		}
+
+		ssoProfile, err := cfgManager.GetSSOProfile(profileName)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := sso.RemoveTokenCache(ssoProfile.StartURL); err != nil {
+			output.Printf("Error clearing cached token for profile '%s': %v\n", profileName, err)
+			os.Exit(1)
+		}
+		output.Printf("✅ Logged out of profile '%s'\n", profileName)
+	},
+}
+
+var authCleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove expired SSO tokens from the cache",
+	Long: `Remove the cached SSO tokens that have expired and can no longer be refreshed, including
+ones created by the AWS CLI. Valid tokens are kept, so no active session is logged out, and so are
+expired ones that can still be refreshed without a new login.
+
+Examples:
+  bifrost auth cleanup`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Clear token cache
-		if err := sso.ClearTokenCache(); err != nil {
-			fmt.Printf("Error clearing token cache: %v\n", err)
+		removed, valid, refreshable, err := sso.PruneExpiredTokenCache()
+		if err != nil {
+			output.Printf("Error cleaning up token cache: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("✅ Token cache cleared")
+		output.Printf("✅ Removed %d expired token(s), kept %d valid and %d expired but refreshable token(s)\n", removed, valid, refreshable)
 	},
 }
 
+// importAWSSSOSessions offers the SSO sessions of the AWS CLI config for import and saves the picked ones
+func importAWSSSOSessions(cfgManager *config.Manager, prompt *ui.Prompt) {
+	path, err := config.AWSConfigPath()
+	if err != nil {
+		output.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	sessions, err := config.ReadAWSSSOSessions(path)
+	if err != nil {
+		output.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	globalCfg, err := cfgManager.LoadGlobal()
+	if err != nil {
+		output.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	configured := make(map[string]string, len(globalCfg.SSOProfiles))
+	for name, profile := range globalCfg.SSOProfiles {
+		configured[strings.TrimSuffix(profile.StartURL, "/")] = name
+	}
+
+	// Sessions bifrost already has, or whose name is taken by another start URL, are left out
+	var options []string
+	candidates := make(map[string]config.AWSSSOSession)
+	for _, session := range sessions {
+		if name, exists := configured[strings.TrimSuffix(session.StartURL, "/")]; exists {
+			output.Printf("⏭️ %s: %s is already configured as SSO profile '%s'\n", session.Section, session.StartURL, name)
+			continue
+		}
+		if _, exists := globalCfg.SSOProfiles[session.Name]; exists {
+			output.Printf("⚠️ %s: an SSO profile named '%s' already exists with another URL, add it under a new name with 'bifrost auth configure'\n", session.Section, session.Name)
+			continue
+		}
+		if session.Region == "" || validateSSOURL(session.StartURL) != nil {
+			output.Printf("⚠️ %s: missing sso_region or invalid sso_start_url, skipped\n", session.Section)
+			continue
+		}
+		label := fmt.Sprintf("%s (%s, %s)", session.Name, session.StartURL, session.Region)
+		options = append(options, label)
+		candidates[label] = session
+	}
+
+	if len(options) == 0 {
+		fmt.Printf("No new SSO sessions found in %s.\n", path)
+		return
+	}
+
+	selected, err := prompt.MultiSelect("Select SSO sessions to import", options, options...)
+	if err != nil {
+		output.Printf("Error selecting sessions: %v\n", err)
+		os.Exit(1)
+	}
+	if len(selected) == 0 {
+		fmt.Println("Nothing imported.")
+		return
+	}
+
+	for _, label := range selected {
+		session := candidates[label]
+		if err := cfgManager.AddSSOProfile(session.Name, session.SSOProfile()); err != nil {
+			output.Printf("Error saving profile '%s': %v\n", session.Name, err)
+			os.Exit(1)
+		}
+		output.Printf("📥 Imported SSO profile '%s' from %s\n", session.Name, session.Section)
+	}
+
+	output.Printf("✅ Imported %d SSO profile(s)\n", len(selected))
+	fmt.Println("Use 'bifrost auth login --profile <name>' to authenticate with them.")
+}
+
+// validateSSOURL checks the SSO Start URL looks like an AWS access portal URL
+func validateSSOURL(input string) error {
+	parsed, err := url.Parse(strings.TrimSpace(input))
+	if err != nil {
+		return fmt.Errorf("invalid SSO Start URL: %v", err)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("SSO Start URL must start with https:// (e.g. https://my-org.awsapps.com/start)")
+	}
+	if parsed.Hostname() == "" || !strings.Contains(parsed.Hostname(), ".") {
+		return fmt.Errorf("SSO Start URL must include a full hostname (e.g. https://my-org.awsapps.com/start)")
+	}
+	if strings.HasSuffix(parsed.Hostname(), ".awsapps.com") && !strings.HasPrefix(parsed.Path, "/start") {
+		return fmt.Errorf("AWS SSO Start URLs end in /start (e.g. https://%s/start)", parsed.Hostname())
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(authLoginCmd)
 	authCmd.AddCommand(authConfigureCmd)
 	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authStatusCmd)
 	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authCleanupCmd)
 
 	// Login command flags
 	authLoginCmd.Flags().StringP("profile", "p", "", "Profile name")
+	authLoginCmd.Flags().Duration("auth-timeout", sso.DefaultAuthTimeout, "How long to wait for the SSO login to be approved in the browser")
 
 	// Configure command flags
 	authConfigureCmd.Flags().StringP("profile", "p", "", "Profile name")
 	authConfigureCmd.Flags().String("sso-url", "", "SSO Start URL")
 	authConfigureCmd.Flags().String("sso-region", "", "SSO region")
 	authConfigureCmd.Flags().Bool("no-auto-detect", false, "Disable automatic region detection from SSO URL")
+	authConfigureCmd.Flags().Bool("import-from-aws", false, "Import SSO sessions and SSO profiles from ~/.aws/config")
+	authConfigureCmd.Flags().String("client-name", "", "Name to register the OIDC client under (default \"bifrost\")")
+	authConfigureCmd.Flags().String("default-region", "", "Region connect uses when neither --region nor the connection profile sets one")
+	authConfigureCmd.Flags().String("default-service", "", "Service type connect uses when neither --service nor a connection profile sets one")
+	authConfigureCmd.Flags().String("default-port", "", "Local port connect uses when neither --port nor a connection profile sets one")
+	authConfigureCmd.Flags().String("description", "", "Description shown next to the profile name when selecting an SSO profile")
+	authConfigureCmd.Flags().StringSlice("scopes", nil, "Scopes to request when registering the OIDC client (default sso:account:access)")
+
+	// Logout command flags
+	authLogoutCmd.Flags().StringP("profile", "p", "", "Profile name")
+	authLogoutCmd.Flags().Bool("all", false, "Clear all cached SSO tokens, including ones created by the AWS CLI")
+
+	_ = authLoginCmd.RegisterFlagCompletionFunc("profile", completeSSOProfiles)
+	_ = authLogoutCmd.RegisterFlagCompletionFunc("profile", completeSSOProfiles)
+	_ = authConfigureCmd.RegisterFlagCompletionFunc("profile", completeSSOProfiles)
+	_ = authConfigureCmd.RegisterFlagCompletionFunc("default-service", completeServiceTypes)
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// selectBastionAcrossAccounts signs in with the SSO client, searches every account it can access for
+// bastions carrying tag, signing into each with roleName, and lets the user pick one. It returns the
+// account and instance ID of the picked bastion.
+func selectBastionAcrossAccounts(prompt *ui.Prompt, client *sso.Client, region, roleName, tag string, awsTimeout time.Duration) (string, string, error) {
+	tagKey, tagValue, err := connect.ParseBastionTag(tag)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := client.Authenticate(context.Background())
+	if err != nil {
+		return "", "", fmt.Errorf("authentication failed: %v", err)
+	}
+
+	ctx, cancel := awsContext(awsTimeout)
+	accounts, err := client.ListAccounts(ctx, token)
+	cancel()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list accounts: %v", err)
+	}
+
+	var bastions []connect.AccountBastion
+	label := fmt.Sprintf("Searching %d accounts for bastions tagged %s in %s…", len(accounts), tag, region)
+	err = ui.WithSpinner(label, func() error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		bastions, err = connect.FindBastionsInAccounts(ctx, client, token, accounts, roleName, region, tagKey, tagValue, awsTimeout)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	labels := make([]string, len(bastions))
+	byLabel := make(map[string]connect.AccountBastion, len(bastions))
+	for i, bastion := range bastions {
+		labels[i] = bastion.Label()
+		byLabel[labels[i]] = bastion
+	}
+	selected, err := prompt.SelectFilterable("Select bastion instance", labels, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to select bastion: %v", err)
+	}
+	bastion := byLabel[selected]
+	output.Printf("🔎 Found bastion %s in account %s (%s)\n", bastion.InstanceID, bastion.AccountName, bastion.AccountID)
+	return bastion.AccountID, bastion.InstanceID, nil
+}
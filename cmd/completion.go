@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"sort"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/spf13/cobra"
+)
+
+// completeConnectionProfiles completes flag values with configured connection profile names
+func completeConnectionProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(cfg.ConnectionProfiles))
+	for name := range cfg.ConnectionProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSSOProfiles completes flag values with configured SSO profile names
+func completeSSOProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(cfg.SSOProfiles))
+	for name := range cfg.SSOProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServiceTypes completes flag values with the supported service types
+func completeServiceTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return connect.ServiceTypes, cobra.ShellCompDirectiveNoFileComp
+}
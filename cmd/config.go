@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// configValidation is the JSON shape of bifrost config validate
+type configValidation struct {
+	Files    []string         `json:"files"`
+	Problems []config.Problem `json:"problems"`
+	Valid    bool             `json:"valid"`
+}
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect bifrost's config files",
+	Long:  `Inspect the global config (~/.bifrost/config.yaml) and the local project config (.bifrost.config.yaml).`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config files for unknown keys and broken references",
+	Long: `Read the config files strictly and report every problem with its file and key:
+  - YAML syntax errors
+  - Unknown keys (typos such as start_url instead of sso_url)
+  - Values of the wrong type
+  - Connection profiles whose sso_profile doesn't exist
+
+Without --file the global config and the local config in use are checked. A file with
+sso_profiles or version at the top level is checked as a global config, otherwise as a local one.
+
+Exits with status 1 if any problem is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fileFlag, _ := cmd.Flags().GetString("file")
+
+		globalPath, err := config.GlobalConfigPath()
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result := configValidation{Problems: []config.Problem{}}
+		validate := func(path string) *config.Config {
+			result.Files = append(result.Files, path)
+			cfg, problems := config.ValidateFile(path)
+			result.Problems = append(result.Problems, problems...)
+			return cfg
+		}
+
+		// Connection profiles may use SSO profiles from the global config
+		ssoProfiles := make(map[string]config.SSOProfile)
+		var files []string
+		if fileFlag != "" {
+			if _, err := os.Stat(globalPath); err == nil {
+				if globalCfg, _ := config.ValidateFile(globalPath); globalCfg != nil {
+					maps.Copy(ssoProfiles, globalCfg.SSOProfiles)
+				}
+			}
+			files = []string{fileFlag}
+		} else {
+			for _, path := range []string{globalPath, config.LocalConfigPath()} {
+				if _, err := os.Stat(path); err == nil {
+					files = append(files, path)
+				}
+			}
+		}
+
+		parsed := make(map[string]*config.Config, len(files))
+		for _, path := range files {
+			if cfg := validate(path); cfg != nil {
+				parsed[path] = cfg
+				maps.Copy(ssoProfiles, cfg.SSOProfiles)
+			}
+		}
+		for _, path := range files {
+			if cfg := parsed[path]; cfg != nil {
+				result.Problems = append(result.Problems, config.ValidateReferences(path, cfg, ssoProfiles)...)
+			}
+		}
+		result.Valid = len(result.Problems) == 0
+
+		if isJSONOutput(cmd) {
+			printJSON(result)
+		} else {
+			printConfigValidation(result)
+		}
+		if !result.Valid {
+			os.Exit(1)
+		}
+	},
+}
+
+// printConfigValidation lists each checked file and the problems found in it
+func printConfigValidation(result configValidation) {
+	if len(result.Files) == 0 {
+		fmt.Println("No config files found, nothing to validate.")
+		return
+	}
+
+	for _, path := range result.Files {
+		var found []config.Problem
+		for _, problem := range result.Problems {
+			if problem.File == path {
+				found = append(found, problem)
+			}
+		}
+
+		if len(found) == 0 {
+			output.Printf("✅ %s\n", path)
+			continue
+		}
+		output.Printf("❌ %s\n", path)
+		for _, problem := range found {
+			if problem.Key == "" {
+				fmt.Printf("   %s\n", problem.Message)
+			} else {
+				fmt.Printf("   %s: %s\n", problem.Key, problem.Message)
+			}
+		}
+	}
+
+	if !result.Valid {
+		fmt.Printf("\n%d problem(s) found\n", len(result.Problems))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().String("file", "", "Validate only this config file")
+}
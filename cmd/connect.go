@@ -5,29 +5,62 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/aws/aws-sdk-go-v2/service/elasticache"
-	"github.com/aws/aws-sdk-go-v2/service/rds"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
-	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/b3nk3/bifrost/internal/cache"
 	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/events"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/process"
+	"github.com/b3nk3/bifrost/internal/session"
 	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/state"
 	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
 	"github.com/spf13/cobra"
 )
 
+// promptSentinel as a flag value drops the value the connection profile or last connection would supply
+const promptSentinel = "-"
+
+// serviceResourceLabels describes the kind of resource each service type connects to
+var serviceResourceLabels = map[string]string{
+	"rds":        "RDS instance",
+	"redis":      "Redis cluster",
+	"documentdb": "DocumentDB cluster",
+	"neptune":    "Neptune cluster",
+	"opensearch": "OpenSearch domain",
+	"kafka":      "MSK cluster",
+	"keyspaces":  "Keyspaces endpoint",
+	"custom":     "custom endpoint",
+}
+
+// serviceDefaultPorts holds the usual local port suggestion for each service type
+var serviceDefaultPorts = map[string]string{
+	"rds":        "3306",
+	"redis":      "6379",
+	"documentdb": "27017",
+	"neptune":    "8182",
+	"opensearch": "9200",
+	"kafka":      "9092",
+	"keyspaces":  "9142",
+}
+
 // connectCmd represents the connect command
 var connectCmd = &cobra.Command{
 	Use:   "connect",
@@ -35,76 +68,357 @@ var connectCmd = &cobra.Command{
 	Long: `Initiate a connection to an AWS RDS/Redis instance through a bastion host with AWS SSM Session Manager.
 	
 For example:
-bifrost connect --service rds --port 3306 --bastion-instance-id i-1234567890abcdef0`,
+bifrost connect --service rds --port 3306 --bastion-instance-id i-1234567890abcdef0
+
+Any other host the bastion can reach is forwarded with --service custom, skipping discovery:
+bifrost connect --service custom --host prometheus.internal --remote-port 9090 --port 9090
+
+Flags override the connection profile (and --last). Set one to "-" to ignore the profile's value
+and be prompted for it instead, e.g. to browse for a bastion when the stored one is stale:
+bifrost connect --profile dev-rds --bastion-instance-id=-`,
 	Run: func(cmd *cobra.Command, args []string) {
 		prompt := ui.NewPrompt()
 		cfgManager := config.NewManager()
 
 		profileFlag, _ := cmd.Flags().GetString("profile")
 		ssoProfileFlag, _ := cmd.Flags().GetString("sso-profile")
+		awsProfileFlag, _ := cmd.Flags().GetString("aws-profile")
 		accountIdFlag, _ := cmd.Flags().GetString("account-id")
+		accountNameFlag, _ := cmd.Flags().GetString("account-name")
 		roleNameFlag, _ := cmd.Flags().GetString("role-name")
 		regionFlag, _ := cmd.Flags().GetString("region")
 		serviceTypeFlag, _ := cmd.Flags().GetString("service")
 		portFlag, _ := cmd.Flags().GetString("port")
+		remotePortFlag, _ := cmd.Flags().GetInt("remote-port")
+		hostFlag, _ := cmd.Flags().GetString("host")
 		bastionInstanceIDFlag, _ := cmd.Flags().GetString("bastion-instance-id")
 		keepAliveFlag, _ := cmd.Flags().GetBool("keep-alive")
 		keepAliveInterval, _ := cmd.Flags().GetDuration("keep-alive-interval")
+		keepAliveProbeFlag, _ := cmd.Flags().GetString("keep-alive-probe")
+		keepAliveMaxFailures, _ := cmd.Flags().GetInt("keep-alive-max-failures")
+		watchFlag, _ := cmd.Flags().GetBool("watch")
+		traceProtocolFlag, _ := cmd.Flags().GetBool("trace-protocol")
+		tokenWarning, _ := cmd.Flags().GetDuration("sso-token-warning")
+		noPreflightFlag, _ := cmd.Flags().GetBool("no-preflight")
+		bindAddressFlag, _ := cmd.Flags().GetString("bind-address")
+		dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+		jsonParamsFlag, _ := cmd.Flags().GetBool("json-params")
+		includeCredsFlag, _ := cmd.Flags().GetBool("include-creds")
+		backgroundFlag, _ := cmd.Flags().GetBool("background")
+		commandFlag, _ := cmd.Flags().GetString("command")
+		lastFlag, _ := cmd.Flags().GetBool("last")
+		selectMultiFlag, _ := cmd.Flags().GetBool("select-multi")
+		eventsFlag, _ := cmd.Flags().GetBool("events")
+		awsTimeout, _ := cmd.Flags().GetDuration("aws-timeout")
+		reconnectFlag, _ := cmd.Flags().GetBool("reconnect")
+		maxReconnects, _ := cmd.Flags().GetInt("max-reconnects")
+		maxDuration, _ := cmd.Flags().GetDuration("max-duration")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+		authTimeout, _ := cmd.Flags().GetDuration("auth-timeout")
+		ssmDocumentFlag, _ := cmd.Flags().GetString("ssm-document")
+		ssmParametersFlag, _ := cmd.Flags().GetString("ssm-parameters")
+		rememberFlag, _ := cmd.Flags().GetBool("remember")
+		endpointTypeFlag, _ := cmd.Flags().GetString("endpoint-type")
+		filterFlag, _ := cmd.Flags().GetString("filter")
+		listResourcesFlag, _ := cmd.Flags().GetBool("list-resources")
+		regionFromProfileFlag, _ := cmd.Flags().GetBool("region-from-profile")
+		envFlag, _ := cmd.Flags().GetString("env")
+		yesFlag, _ := cmd.Flags().GetBool("yes")
+		assumeRoleARNFlag, _ := cmd.Flags().GetString("assume-role-arn")
+		externalIDFlag, _ := cmd.Flags().GetString("external-id")
+		noCacheFlag, _ := cmd.Flags().GetBool("no-cache")
+		cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+		credentialSecretARNFlag, _ := cmd.Flags().GetString("credential-secret-arn")
+		printCredentialsFlag, _ := cmd.Flags().GetBool("print-credentials")
+		showPasswordFlag, _ := cmd.Flags().GetBool("show-password")
+		printConnectionStringFlag, _ := cmd.Flags().GetBool("print-connection-string")
+		dbUserFlag, _ := cmd.Flags().GetString("db-user")
+		dbNameFlag, _ := cmd.Flags().GetString("db-name")
+		allAccountsFlag, _ := cmd.Flags().GetBool("all-accounts")
+		bastionTagFlag, _ := cmd.Flags().GetString("bastion-tag")
+		if noCacheFlag {
+			cacheTTL = 0
+		}
+
+		if eventsFlag {
+			events.Enable(logging.Tee(os.Stderr))
+		}
+
+		// With --json-params, --output json or --list-resources stdout only gets the result, status messages,
+		// prompts and the SSM session's own output go to stderr
+		jsonResult := isJSONOutput(cmd)
+		jsonOutput := os.Stdout
+		if jsonParamsFlag || jsonResult || listResourcesFlag {
+			output.StatusTo(os.Stderr)
+		}
+		if jsonResult && (jsonParamsFlag || selectMultiFlag) {
+			output.Println("--output json can't be combined with --json-params or --select-multi.")
+			os.Exit(1)
+		}
+		if includeCredsFlag && !jsonParamsFlag {
+			output.Println("--include-creds only works with --json-params.")
+			os.Exit(1)
+		}
+		if jsonParamsFlag && (selectMultiFlag || backgroundFlag || commandFlag != "") {
+			output.Println("--json-params can't be combined with --select-multi, --background or --command, it only prints the session parameters.")
+			os.Exit(1)
+		}
+
+		if listResourcesFlag && (selectMultiFlag || backgroundFlag || commandFlag != "" || dryRunFlag || jsonParamsFlag) {
+			output.Println("--list-resources can't be combined with --select-multi, --background, --command, --dry-run or --json-params, it only lists resources.")
+			os.Exit(1)
+		}
+
+		// Check for the plugin before signing in, only the printing modes can do without it
+		if !dryRunFlag && !jsonParamsFlag && !listResourcesFlag {
+			ensureSessionManagerPlugin(prompt)
+		}
+
+		if !slices.Contains(connect.KeepAliveProbeModes, keepAliveProbeFlag) {
+			output.Printf("Error: invalid keep alive probe '%s'. Must be one of: %s\n", keepAliveProbeFlag, strings.Join(connect.KeepAliveProbeModes, ", "))
+			os.Exit(1)
+		}
+
+		if keepAliveMaxFailures < 0 {
+			output.Println("Error: --keep-alive-max-failures can't be negative")
+			os.Exit(1)
+		}
+
+		if watchFlag && !keepAliveFlag {
+			output.Println("Error: --watch shows the keep alive checks, it can't be combined with --keep-alive=false")
+			os.Exit(1)
+		}
+
+		if err := connect.ValidateBindAddress(bindAddressFlag); err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if bindAddressFlag != connect.DefaultBindAddress && backgroundFlag {
+			output.Println("--bind-address is not supported in background mode, the relay needs bifrost to keep running.")
+			os.Exit(1)
+		}
+		if traceProtocolFlag && backgroundFlag {
+			output.Println("--trace-protocol is not supported in background mode, the relay it traces needs bifrost to keep running.")
+			os.Exit(1)
+		}
+		if commandFlag != "" && backgroundFlag {
+			output.Println("--command can't be combined with --background, the tunnel closes when the command exits.")
+			os.Exit(1)
+		}
+		if maxDuration < 0 || idleTimeout < 0 {
+			output.Println("Error: --max-duration and --idle-timeout can't be negative")
+			os.Exit(1)
+		}
+		if (maxDuration > 0 || idleTimeout > 0) && backgroundFlag {
+			output.Println("--max-duration and --idle-timeout are not supported in background mode, enforcing them needs bifrost to keep running.")
+			os.Exit(1)
+		}
+
+		if cmd.Flags().Changed("remote-port") && (remotePortFlag < 1 || remotePortFlag > 65535) {
+			output.Println("Error: --remote-port must be between 1 and 65535")
+			os.Exit(1)
+		}
+		if hostFlag != "" {
+			if err := connect.ValidateCustomHost(hostFlag); err != nil {
+				output.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if showPasswordFlag && !printCredentialsFlag {
+			output.Println("--show-password only works with --print-credentials.")
+			os.Exit(1)
+		}
+
+		if selectMultiFlag && (profileFlag != "" || lastFlag || backgroundFlag || commandFlag != "" || printCredentialsFlag || printConnectionStringFlag || allAccountsFlag) {
+			output.Println("--select-multi can't be combined with --profile, --last, --background, --command, --print-credentials, --print-connection-string or --all-accounts.")
+			os.Exit(1)
+		}
+
+		// --last replays the previous connection, explicit flags still take priority
+		var last *state.LastConnection
+		if lastFlag {
+			loaded, err := state.LoadLastConnection()
+			switch {
+			case err != nil:
+				output.Printf("⚠️ Warning: failed to load the last connection, continuing with prompts: %v\n", err)
+			case loaded == nil:
+				output.Println("⚠️ No previous connection recorded yet, continuing with prompts")
+			default:
+				last = loaded
+			}
+		}
+		if last != nil {
+			output.Printf("⏮️ Repeating last connection from %s\n", last.ConnectedAt.Local().Format("2006-01-02 15:04:05"))
+			if last.Profile != "" {
+				if _, err := cfgManager.GetConnectionProfile(last.Profile); err != nil {
+					output.Printf("⚠️ Connection profile '%s' of the last connection no longer exists\n", last.Profile)
+					last.Profile = ""
+				}
+			}
+			if missing := last.Missing(); len(missing) > 0 {
+				output.Printf("⚠️ Last connection is missing %s, you'll be asked for it\n", strings.Join(missing, ", "))
+			}
+
+			if profileFlag == "" {
+				profileFlag = last.Profile
+			}
+			if awsProfileFlag == "" && ssoProfileFlag == "" {
+				awsProfileFlag = last.AWSProfile
+			}
+			if ssoProfileFlag == "" {
+				ssoProfileFlag = last.SSOProfile
+			}
+			if accountIdFlag == "" && accountNameFlag == "" {
+				accountIdFlag = last.AccountID
+			}
+			if roleNameFlag == "" {
+				roleNameFlag = last.RoleName
+			}
+			if regionFlag == "" {
+				regionFlag = last.Region
+			}
+			if assumeRoleARNFlag == "" {
+				assumeRoleARNFlag = last.AssumeRoleARN
+			}
+			if externalIDFlag == "" {
+				externalIDFlag = last.ExternalID
+			}
+			if serviceTypeFlag == "" {
+				serviceTypeFlag = last.ServiceType
+			}
+			if portFlag == "" {
+				portFlag = last.Port
+			}
+			if !cmd.Flags().Changed("remote-port") {
+				remotePortFlag = last.RemotePort
+			}
+			if !cmd.Flags().Changed("endpoint-type") && last.EndpointType != "" {
+				endpointTypeFlag = last.EndpointType
+			}
+			if bastionInstanceIDFlag == "" {
+				bastionInstanceIDFlag = last.BastionInstanceID
+			}
+			if ssmDocumentFlag == "" {
+				ssmDocumentFlag = last.SSMDocument
+			}
+			if ssmParametersFlag == "" {
+				ssmParametersFlag = last.SSMParameters
+			}
+		}
+
+		tunnelOpts := connect.TunnelOptions{
+			BindAddress:          bindAddressFlag,
+			KeepAlive:            keepAliveFlag,
+			KeepAliveInterval:    keepAliveInterval,
+			KeepAliveProbe:       keepAliveProbeFlag,
+			KeepAliveMaxFailures: keepAliveMaxFailures,
+			Watch:                watchFlag,
+			TraceProtocol:        traceProtocolFlag,
+			Reconnect:            reconnectFlag,
+			MaxReconnects:        maxReconnects,
+			MaxDuration:          maxDuration,
+			IdleTimeout:          idleTimeout,
+		}
+
+		if selectMultiFlag {
+			connectMultipleProfiles(cfgManager, prompt, multiProfileOptions{
+				Env:          envFlag,
+				EndpointType: endpointTypeFlag,
+				AWSTimeout:   awsTimeout,
+				AuthTimeout:  authTimeout,
+				Yes:          yesFlag,
+				NoPreflight:  noPreflightFlag,
+				DryRun:       dryRunFlag,
+				Tunnel:       tunnelOpts,
+			})
+			return
+		}
 
 		// Check if using connection profile (from flag or selection)
 		var selectedProfile *config.ConnectionProfile
+		var selectedProfileName string
 		if profileFlag != "" {
 			// Load specific connection profile
 			profile, err := cfgManager.GetConnectionProfile(profileFlag)
 			if err != nil {
-				fmt.Printf("Error loading connection profile '%s': %v\n", profileFlag, err)
+				output.Printf("Error loading connection profile '%s': %v\n", profileFlag, err)
 				os.Exit(1)
 			}
 			selectedProfile = profile
-			fmt.Printf("🔗 Using connection profile: %s\n", profileFlag)
+			selectedProfileName = profileFlag
+			output.Printf("🔗 Using connection profile: %s\n", profileFlag)
 		} else {
 			// Check for available connection profiles and offer selection
 			cfg, err := cfgManager.Load()
 			if err != nil {
-				fmt.Printf("Error loading config: %v\n", err)
+				output.Printf("Error loading config: %v\n", err)
 				os.Exit(1)
 			}
 
-			if len(cfg.ConnectionProfiles) > 0 {
+			profiles := filterProfilesByEnvironment(cfg.ConnectionProfiles, envFlag)
+
+			// Without prompts, a missing --profile means manual setup from flags
+			if len(profiles) > 0 && prompt.Interactive() && last == nil {
 				// Add manual setup option with clear distinction
-				profileNames := make([]string, 0, len(cfg.ConnectionProfiles)+1)
+				profileNames := make([]string, 0, len(profiles)+1)
 				profileNames = append(profileNames, "⚙️ Manual setup")
-				for name := range cfg.ConnectionProfiles {
-					profileNames = append(profileNames, "🔗 "+name)
+				profileMap := make(map[string]string)
+				for _, name := range sortProfilesByEnvironment(profiles) {
+					display := "🔗 " + name
+					if summary := profileSummary(profiles[name]); summary != "" {
+						display = fmt.Sprintf("🔗 %s (%s)", name, summary)
+					}
+					profileNames = append(profileNames, display)
+					profileMap[display] = name
 				}
 
 				selected, err := prompt.Select("Select connection profile or manual setup", profileNames)
 				if err != nil {
-					fmt.Printf("Error selecting profile: %v\n", err)
+					output.Printf("Error selecting profile: %v\n", err)
 					os.Exit(1)
 				}
 
 				if selected != "⚙️ Manual setup" {
-					// Remove the emoji prefix to get actual profile name
-					profileName := selected[5:] // Remove "🔗 " prefix
+					profileName := profileMap[selected]
 					profile, err := cfgManager.GetConnectionProfile(profileName)
 					if err != nil {
-						fmt.Printf("Error loading connection profile '%s': %v\n", profileName, err)
+						output.Printf("Error loading connection profile '%s': %v\n", profileName, err)
 						os.Exit(1)
 					}
 					selectedProfile = profile
-					fmt.Printf("🔗 Using connection profile: %s\n", profileName)
+					selectedProfileName = profileName
+					output.Printf("🔗 Using connection profile: %s\n", profileName)
 				}
 			}
 		}
 
-		// Use connection profile values as defaults (if available)
+		// Production profiles need an explicit go-ahead
+		if selectedProfile != nil && selectedProfile.IsProduction() && !yesFlag {
+			confirmed, err := prompt.Confirm(fmt.Sprintf("⚠️ '%s' is a production (%s) profile. Connect anyway?", selectedProfileName, selectedProfile.Environment))
+			if err != nil || !confirmed {
+				output.Println("Connection cancelled")
+				os.Exit(1)
+			}
+		}
+
+		// Each value comes from the first of these that has it:
+		//   1. its flag
+		//   2. the last connection (--last)
+		//   3. the connection profile
+		//   4. a prompt, which may preselect a remembered account and role (--remember)
+		// A flag set to "-" skips 2 and 3 so the value is prompted for, e.g. when the profile's is stale.
 		if selectedProfile != nil {
+			if awsProfileFlag == "" && ssoProfileFlag == "" && selectedProfile.AWSProfile != "" {
+				awsProfileFlag = selectedProfile.AWSProfile
+			}
 			if ssoProfileFlag == "" && selectedProfile.SSOProfile != "" {
 				ssoProfileFlag = selectedProfile.SSOProfile
 			}
-			if accountIdFlag == "" && selectedProfile.AccountID != "" {
+			// An account ID or name given as a flag replaces both of the profile's
+			if accountIdFlag == "" && accountNameFlag == "" {
 				accountIdFlag = selectedProfile.AccountID
+				accountNameFlag = selectedProfile.AccountName
 			}
 			if roleNameFlag == "" && selectedProfile.RoleName != "" {
 				roleNameFlag = selectedProfile.RoleName
@@ -121,674 +435,1424 @@ bifrost connect --service rds --port 3306 --bastion-instance-id i-1234567890abcd
 			if bastionInstanceIDFlag == "" && selectedProfile.BastionInstanceID != "" {
 				bastionInstanceIDFlag = selectedProfile.BastionInstanceID
 			}
+			if assumeRoleARNFlag == "" && selectedProfile.AssumeRoleARN != "" {
+				assumeRoleARNFlag = selectedProfile.AssumeRoleARN
+			}
+			if externalIDFlag == "" && selectedProfile.ExternalID != "" {
+				externalIDFlag = selectedProfile.ExternalID
+			}
+			if ssmDocumentFlag == "" && selectedProfile.SSMDocument != "" {
+				ssmDocumentFlag = selectedProfile.SSMDocument
+			}
+			if ssmParametersFlag == "" && selectedProfile.SSMParameters != "" {
+				ssmParametersFlag = selectedProfile.SSMParameters
+			}
+			if credentialSecretARNFlag == "" && selectedProfile.CredentialSecretARN != "" {
+				credentialSecretARNFlag = selectedProfile.CredentialSecretARN
+			}
+			if dbUserFlag == "" && selectedProfile.DBUser != "" {
+				dbUserFlag = selectedProfile.DBUser
+			}
+			if dbNameFlag == "" && selectedProfile.DBName != "" {
+				dbNameFlag = selectedProfile.DBName
+			}
+		}
+		for _, value := range []*string{
+			&awsProfileFlag, &ssoProfileFlag, &accountIdFlag, &accountNameFlag, &roleNameFlag, &regionFlag, &serviceTypeFlag, &portFlag,
+			&bastionInstanceIDFlag, &assumeRoleARNFlag, &externalIDFlag, &ssmDocumentFlag, &ssmParametersFlag,
+			&credentialSecretARNFlag, &dbUserFlag, &dbNameFlag,
+		} {
+			if *value == promptSentinel {
+				*value = ""
+			}
 		}
+		if allAccountsFlag {
+			switch {
+			case awsProfileFlag != "":
+				output.Println("--all-accounts signs into each account through SSO, it can't be combined with an AWS profile.")
+				os.Exit(1)
+			case accountIdFlag != "" || accountNameFlag != "":
+				output.Println("--all-accounts finds the account of the bastion, it can't be combined with an account ID or name.")
+				os.Exit(1)
+			case bastionInstanceIDFlag != "":
+				output.Println("--all-accounts finds the bastion, it can't be combined with a bastion instance ID.")
+				os.Exit(1)
+			case assumeRoleARNFlag != "":
+				output.Println("--all-accounts can't be combined with --assume-role-arn, bastions are searched in the SSO accounts.")
+				os.Exit(1)
+			}
+			if _, _, err := connect.ParseBastionTag(bastionTagFlag); err != nil {
+				output.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if printCredentialsFlag && credentialSecretARNFlag == "" {
+			output.Println("--print-credentials needs a secret to read, set credential_secret_arn on the profile or pass --credential-secret-arn.")
+			os.Exit(1)
+		}
+
+		if !slices.Contains(connect.RedisEndpointTypes, endpointTypeFlag) {
+			output.Printf("Error: invalid endpoint type '%s'. Must be one of: %s\n", endpointTypeFlag, strings.Join(connect.RedisEndpointTypes, ", "))
+			os.Exit(1)
+		}
+
+		tunnelOpts.SSMDocument = ssmDocumentFlag
+		tunnelOpts.SSMParameters = ssmParametersFlag
+
+		chain := connect.RoleChain{RoleARN: assumeRoleARNFlag, ExternalID: externalIDFlag}
+		var awsCfg aws.Config
+		var err error
+
+		// --dry-run and --json-params stop once everything is resolved
+		printResolved := func(summary dryRunSummary) {
+			if jsonParamsFlag {
+				printSessionParams(jsonOutput, summary, awsCfg, includeCredsFlag)
+				return
+			}
+			if jsonResult {
+				printConnectResult(jsonOutput, summary, true)
+				return
+			}
+			printDryRun(summary)
+		}
+		if awsProfileFlag != "" {
+			// 1. Take credentials from the shared AWS config instead of signing in with bifrost
+			awsCfg, accountIdFlag, err = getSharedProfileConfig(awsProfileFlag, regionFlag, chain, awsTimeout)
+			if err != nil {
+				output.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if regionFlag == "" {
+				regionFlag = awsCfg.Region
+			}
+		} else {
+			// Prompt for SSO profile if not provided
+			if ssoProfileFlag == "" {
+				ssoProfileFlag = selectSSOProfile(cfgManager, prompt)
+			}
 
-		// Prompt for SSO profile if not provided
-		if ssoProfileFlag == "" {
-			// Try to get default SSO profile (if only one exists)
-			defaultProfile, err := cfgManager.GetDefaultSSOProfile()
+			loaded, err := cfgManager.Load()
+			if err != nil {
+				output.Printf("Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+			ssoProfile, err := loaded.SSOProfile(ssoProfileFlag)
 			if err != nil {
-				fmt.Printf("Error loading config: %v\n", err)
+				output.Printf("Error: failed to get SSO profile '%s': %v\n", ssoProfileFlag, err)
 				os.Exit(1)
 			}
+			ssoClient := connect.NewSSOClient(ssoProfile, authTimeout, connect.TokenCacheOptions(loaded)...)
 
-			if defaultProfile != "" {
-				ssoProfileFlag = defaultProfile
-				fmt.Printf("🔐 Using SSO profile: %s\n", ssoProfileFlag)
-			} else {
-				// Load config to show available profiles
-				cfg, err := cfgManager.Load()
-				if err != nil {
-					fmt.Printf("Error loading config: %v\n", err)
-					os.Exit(1)
+			// Neither a flag nor the connection profile set a region, fall back to the SSO profile's
+			if regionFlag == "" && ssoProfile.DefaultRegion != "" {
+				regionFlag = ssoProfile.DefaultRegion
+				output.Printf("🌍 Using default region %s of SSO profile '%s'\n", regionFlag, ssoProfileFlag)
+			}
+
+			// The default service and port are for setups without a connection profile
+			if selectedProfile == nil {
+				if serviceTypeFlag == "" && hostFlag == "" && ssoProfile.DefaultService != "" {
+					serviceTypeFlag = ssoProfile.DefaultService
+					output.Printf("🛠️ Using default service %s of SSO profile '%s'\n", serviceTypeFlag, ssoProfileFlag)
+				}
+				if portFlag == "" && ssoProfile.DefaultPort != "" {
+					portFlag = ssoProfile.DefaultPort
+					output.Printf("🌐 Using default port %s of SSO profile '%s'\n", portFlag, ssoProfileFlag)
 				}
+			}
+
+			// Without a region, sign in through the SSO region first so the account's enabled regions can be offered
+			credsRegion := regionFlag
+			if credsRegion == "" {
+				credsRegion = ssoProfile.SSORegion
+			}
 
-				if len(cfg.SSOProfiles) == 0 {
-					fmt.Println("No SSO profiles found. Please create one with 'bifrost auth configure'")
+			// Search the bastion across accounts first, connecting to the account it is in
+			if allAccountsFlag {
+				if regionFlag == "" {
+					output.Println("--all-accounts needs a region to search, pass --region or set a default region on the SSO profile.")
 					os.Exit(1)
 				}
-
-				profileNames := make([]string, 0, len(cfg.SSOProfiles))
-				for name := range cfg.SSOProfiles {
-					profileNames = append(profileNames, name)
+				if roleNameFlag == "" {
+					if st, err := state.Load(); err == nil {
+						roleNameFlag = st.AccountRoles[ssoProfileFlag].RoleName
+					}
 				}
-
-				selected, err := prompt.Select("Select SSO profile", profileNames)
+				if roleNameFlag == "" {
+					output.Println("--all-accounts needs the role to sign into every account with, pass --role-name.")
+					os.Exit(1)
+				}
+				accountIdFlag, bastionInstanceIDFlag, err = selectBastionAcrossAccounts(prompt, ssoClient, regionFlag, roleNameFlag, bastionTagFlag, awsTimeout)
 				if err != nil {
-					fmt.Printf("Error selecting profile: %v\n", err)
+					output.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
-				ssoProfileFlag = selected
 			}
+
+			// 1. Check AWS credentials
+			awsCfg, accountIdFlag, roleNameFlag, err = getAWSConfig(ssoProfileFlag, credsRegion, accountIdFlag, accountNameFlag, roleNameFlag, authTimeout, chain)
+			if err != nil {
+				output.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Watch the SSO token during long sessions so it doesn't expire unnoticed before a reconnect
+			if keepAliveFlag && tokenWarning > 0 {
+				tunnelOpts.SSOClient = ssoClient
+				tunnelOpts.TokenWarning = tokenWarning
+			}
+		}
+
+		// With a role chain, resources live in the account of the assumed role
+		targetAccountID := accountIdFlag
+		if chained := chain.AccountID(); chained != "" {
+			targetAccountID = chained
 		}
 
 		// Prompt for region if not provided
 		if regionFlag == "" {
-			result, err := prompt.Input("AWS region (where your RDS/Redis instances are)", nil)
+			ctx, cancel := awsContext(awsTimeout)
+			result, err := selectRegion(ctx, prompt, &awsCfg, targetAccountID, cacheTTL)
+			cancel()
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			regionFlag = result
+			awsCfg.Region = regionFlag
+		}
+		output.Printf("🌍 Region: %s\n", regionFlag)
+
+		// Protected accounts need the account ID typed back before anything is forwarded
+		if !yesFlag {
+			if err := confirmProtectedAccount(cfgManager, prompt, targetAccountID); err != nil {
+				output.Printf("Connection cancelled: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		// 1. Check AWS credentials
-		awsCfg, accountIdFlag, roleNameFlag, err := getAWSConfig(ssoProfileFlag, regionFlag, accountIdFlag, roleNameFlag)
+		ctx, cancel := awsContext(awsTimeout)
+		err = connect.ValidateSSMDocument(ctx, awsCfg, tunnelOpts)
+		cancel()
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			output.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
+		if tunnelOpts.Document() != connect.DefaultSSMDocument {
+			output.Printf("📄 SSM document: %s\n", tunnelOpts.Document())
+		}
 
-		// Check service type
-
-		if serviceTypeFlag == "" {
-			result, err := prompt.Select("Select service type", []string{"rds", "redis"})
-			if err != nil {
-				fmt.Printf("Prompt failed %v\n", err)
-				return
+		if rememberFlag && awsProfileFlag == "" {
+			if err := state.RememberAccountRole(ssoProfileFlag, accountIdFlag, roleNameFlag); err != nil {
+				output.Printf("⚠️ Warning: failed to remember account and role: %v\n", err)
+			} else {
+				output.Printf("💾 Remembered account %s and role %s as defaults for SSO profile '%s'\n", accountIdFlag, roleNameFlag, ssoProfileFlag)
 			}
-			serviceTypeFlag = result
-		} else if serviceTypeFlag != "rds" && serviceTypeFlag != "redis" {
-			fmt.Println("Invalid service type. Please choose either 'rds' or 'redis'.")
-			return
 		}
-		fmt.Printf("🛠️ Service type: %s\n", serviceTypeFlag)
 
-		if portFlag == "" {
-			result, err := prompt.Input("Enter local port to use for forwarding", validatePort)
-			if err != nil {
-				fmt.Printf("Prompt failed %v\n", err)
+		// Multi-target profiles carry their own service, port and resource per target
+		multiTarget := selectedProfile != nil && len(selectedProfile.Targets) > 0
+		if multiTarget && backgroundFlag {
+			output.Println("Background mode is not supported for multi-target profiles.")
+			os.Exit(1)
+		}
+		if multiTarget && remotePortFlag != 0 {
+			output.Println("--remote-port is not supported for multi-target profiles, each target uses its discovered port.")
+			os.Exit(1)
+		}
+		if multiTarget && hostFlag != "" {
+			output.Println("--host is not supported for multi-target profiles, give custom targets their host:port in the profile.")
+			os.Exit(1)
+		}
+		if multiTarget && listResourcesFlag {
+			output.Println("--list-resources is not supported for multi-target profiles, pick the service with --service instead.")
+			os.Exit(1)
+		}
+
+		var resourceLabel, resourceName string
+		if !multiTarget {
+			// Check service type
+
+			if serviceTypeFlag == "" && hostFlag != "" {
+				serviceTypeFlag = "custom"
+			} else if serviceTypeFlag == "" {
+				result, err := prompt.Select("Select service type", connect.ServiceTypes)
+				if err != nil {
+					output.Printf("Prompt failed %v\n", err)
+					os.Exit(1)
+				}
+				serviceTypeFlag = result
+			} else if !slices.Contains(connect.ServiceTypes, serviceTypeFlag) {
+				output.Printf("Invalid service type. Please choose one of: %s.\n", strings.Join(connect.ServiceTypes, ", "))
 				return
 			}
-			portFlag = result
-		} else if err := validatePort(portFlag); err != nil {
-			fmt.Println(err)
-			return
+			output.Printf("🛠️ Service type: %s\n", serviceTypeFlag)
+			if hostFlag != "" && serviceTypeFlag != "custom" {
+				output.Println("--host is only used with --service custom, other services find their endpoint themselves.")
+				os.Exit(1)
+			}
+			if listResourcesFlag {
+				printResources(jsonOutput, awsCfg, targetAccountID, serviceTypeFlag, filterFlag, jsonResult, awsTimeout, cacheTTL)
+				return
+			}
+
+			// Without --port the local port is asked for once the endpoint is known, defaulting to its port
+			if portFlag != "" {
+				if err := connect.ValidatePort(portFlag); err != nil {
+					output.Println(err)
+					return
+				}
+				output.Printf("🌐 Port: %s\n", portFlag)
+			}
+
+			// Use resource name from the last connection, the profile or prompt for it. It is picked
+			// before the bastion so bastions in its VPC can be suggested.
+			resourceLabel = serviceResourceLabels[serviceTypeFlag]
+			if selectedProfile != nil {
+				resourceName = selectedProfile.ResourceName(serviceTypeFlag)
+			}
+			switch {
+			case serviceTypeFlag == "custom" && hostFlag != "":
+				resourceName = customEndpoint(prompt, hostFlag, remotePortFlag)
+			case last != nil && last.ResourceName != "" && last.ServiceType == serviceTypeFlag:
+				resourceName = last.ResourceName
+				output.Printf("🔗 Using %s from last connection: %s\n", resourceLabel, resourceName)
+			case resourceName != "":
+				output.Printf("🔗 Using %s from profile: %s\n", resourceLabel, resourceName)
+			case serviceTypeFlag == "keyspaces":
+				// Keyspaces has one endpoint per region, there is nothing to pick
+				resourceName = connect.KeyspacesEndpoint(regionFlag)
+			case serviceTypeFlag == "custom":
+				resourceName = customEndpoint(prompt, hostFlag, remotePortFlag)
+			default:
+				resourceName = selectResourceName(prompt, awsCfg, targetAccountID, serviceTypeFlag, filterFlag, awsTimeout, cacheTTL)
+			}
+
+			// A named resource may live in another region than the one chosen, look for it there
+			// before a bastion is picked in the wrong region
+			if regionFromProfileFlag && slices.Contains(connect.RegionSearchServices, serviceTypeFlag) {
+				var candidates []string
+				if selectedProfile != nil {
+					candidates = selectedProfile.CandidateRegions
+				}
+				if region := locateResourceRegion(prompt, awsCfg, targetAccountID, serviceTypeFlag, resourceName, candidates, awsTimeout, cacheTTL); region != "" {
+					regionFlag = region
+					awsCfg.Region = region
+					output.Printf("🌍 Region: %s\n", regionFlag)
+					if bastionInstanceIDFlag != "" {
+						output.Printf("⚠️ Bastion %s must be in %s too, leave it empty to browse that region's bastions\n", bastionInstanceIDFlag, regionFlag)
+					}
+				}
+			}
 		}
-		fmt.Printf("🌐 Port: %s\n", portFlag)
 
 		// 2. Prompt for bastion instance ID if not provided
 		if bastionInstanceIDFlag == "" {
-			result, err := prompt.Input("Enter bastion EC2 instance ID (or leave empty to browse)", nil)
+			result, err := prompt.Input("Enter bastion EC2 instance ID (or leave empty to browse)", validateOptionalInstanceID)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-			
+
 			// If user left it empty, show available SSM managed instances
 			if result == "" {
-				instances, instanceMap, err := listSSMManagedInstances(awsCfg)
-				if err != nil {
-					fmt.Printf("Error listing SSM managed instances: %v\n", err)
-					os.Exit(1)
-				}
-				
-				if len(instances) == 0 {
-					fmt.Println("No SSM managed instances found in this region.")
-					os.Exit(1)
-				}
-				
-				selected, err := prompt.Select("Select bastion instance", instances)
-				if err != nil {
-					fmt.Printf("Error selecting bastion instance: %v\n", err)
+				ctx, cancel := awsContext(awsTimeout)
+				bastions, err := cache.Fetch(cache.Key(targetAccountID, regionFlag, "bastions"), cacheTTL, func() (bastionInstances, error) {
+					var listed bastionInstances
+					err := ui.WithSpinner("Listing SSM instances…", func() (err error) {
+						listed.Names, listed.IDs, listed.VPCs, err = connect.ListSSMManagedInstances(ctx, awsCfg)
+						return err
+					})
+					return listed, err
+				})
+				cancel()
+				var offlineErr *connect.NoOnlineInstancesError
+				switch {
+				case errors.Is(err, connect.ErrNoManagedInstances):
+					output.Println("No SSM managed instances found in this region.")
 					os.Exit(1)
-				}
-				bastionInstanceIDFlag = instanceMap[selected]
-			} else {
-				bastionInstanceIDFlag = result
-			}
-		}
-		fmt.Printf("🏰 Using bastion instance: %s\n", bastionInstanceIDFlag)
-
-		// Get endpoint based on service type
-		var endpoint string
-		var port int32
-		var clusterName, dbName string
-		if serviceTypeFlag == "redis" {
-			// Use Redis cluster name from profile or prompt for it
-			if selectedProfile != nil && selectedProfile.RedisClusterName != "" {
-				clusterName = selectedProfile.RedisClusterName
-				fmt.Printf("🔗 Using Redis cluster from profile: %s\n", clusterName)
-			} else {
-				var err error
-				clusterName, err = prompt.Input("Enter Redis cluster name (or leave empty to browse)", nil)
-				if err != nil {
-					fmt.Printf("Error: %v\n", err)
-					os.Exit(1)
-				}
-				
-				// If user left it empty, show available clusters
-				if clusterName == "" {
-					clusters, err := listRedisClusters(awsCfg)
+				case errors.As(err, &offlineErr):
+					bastionInstanceIDFlag, err = selectOfflineBastion(prompt, offlineErr)
 					if err != nil {
-						fmt.Printf("Error listing Redis clusters: %v\n", err)
+						output.Printf("Error selecting bastion instance: %v\n", err)
 						os.Exit(1)
 					}
-					
-					if len(clusters) == 0 {
-						fmt.Println("No Redis clusters found in this region.")
-						os.Exit(1)
+				case err != nil:
+					output.Printf("Error listing SSM managed instances: %v\n", err)
+					os.Exit(1)
+				default:
+					specs := []config.TargetSpec{{ServiceType: serviceTypeFlag, ResourceName: resourceName}}
+					if multiTarget {
+						specs = selectedProfile.Targets
 					}
-					
-					clusterName, err = prompt.Select("Select Redis cluster", clusters)
+					vpc := targetVPC(awsCfg, specs, awsTimeout)
+					instances, instanceMap, preselected := bastionOptions(bastions, vpc, lastUsedBastion(bastions, targetAccountID, regionFlag, vpc))
+					selected, err := prompt.SelectFilterable("Select bastion instance", instances, preselected)
 					if err != nil {
-						fmt.Printf("Error selecting Redis cluster: %v\n", err)
+						output.Printf("Error selecting bastion instance: %v\n", err)
 						os.Exit(1)
 					}
+					bastionInstanceIDFlag = instanceMap[selected]
+					if err := state.RememberBastion(targetAccountID, regionFlag, bastions.VPCs[bastionInstanceIDFlag], bastionInstanceIDFlag); err != nil {
+						output.Printf("⚠️ Warning: failed to remember bastion: %v\n", err)
+					}
 				}
+			} else {
+				bastionInstanceIDFlag = result
+				verifyBastion(awsCfg, bastionInstanceIDFlag, awsTimeout)
+			}
+		} else {
+			if err := connect.ValidateInstanceID(bastionInstanceIDFlag); err != nil {
+				output.Printf("Error: invalid bastion: %v\n", err)
+				os.Exit(1)
 			}
-			endpoint, port, err = getRedisEndpoint(awsCfg, clusterName)
+			verifyBastion(awsCfg, bastionInstanceIDFlag, awsTimeout)
 		}
-		if serviceTypeFlag == "rds" {
-			// Use RDS instance name from profile or prompt for it
-			if selectedProfile != nil && selectedProfile.RDSInstanceName != "" {
-				dbName = selectedProfile.RDSInstanceName
-				fmt.Printf("🔗 Using RDS instance from profile: %s\n", dbName)
-			} else {
-				var err error
-				dbName, err = prompt.Input("Enter RDS DB instance name (or leave empty to browse)", nil)
-				if err != nil {
-					fmt.Printf("Error: %v\n", err)
-					os.Exit(1)
-				}
-				
-				// If user left it empty, show available instances
-				if dbName == "" {
-					instances, err := listRDSInstances(awsCfg)
-					if err != nil {
-						fmt.Printf("Error listing RDS instances: %v\n", err)
-						os.Exit(1)
-					}
-					
-					if len(instances) == 0 {
-						fmt.Println("No RDS instances found in this region.")
-						os.Exit(1)
-					}
-					
-					dbName, err = prompt.Select("Select RDS instance", instances)
-					if err != nil {
-						fmt.Printf("Error selecting RDS instance: %v\n", err)
-						os.Exit(1)
-					}
+		output.Printf("🏰 Using bastion instance: %s\n", bastionInstanceIDFlag)
+
+		// Recorded for connect --last once the tunnel is up, the resource and local port are filled in below
+		lastConnection := state.LastConnection{
+			Profile:           selectedProfileName,
+			AWSProfile:        awsProfileFlag,
+			SSOProfile:        ssoProfileFlag,
+			AccountID:         accountIdFlag,
+			RoleName:          roleNameFlag,
+			Region:            regionFlag,
+			AssumeRoleARN:     assumeRoleARNFlag,
+			ExternalID:        externalIDFlag,
+			ServiceType:       serviceTypeFlag,
+			RemotePort:        remotePortFlag,
+			EndpointType:      endpointTypeFlag,
+			BastionInstanceID: bastionInstanceIDFlag,
+			SSMDocument:       ssmDocumentFlag,
+			SSMParameters:     ssmParametersFlag,
+		}
+
+		if !noPreflightFlag {
+			preflightServices := []string{serviceTypeFlag}
+			if multiTarget {
+				preflightServices = preflightServices[:0]
+				for _, spec := range selectedProfile.Targets {
+					preflightServices = append(preflightServices, spec.ServiceType)
 				}
 			}
-			endpoint, port, err = getRDSEndpoint(awsCfg, dbName)
+			ctx, cancel := awsContext(awsTimeout)
+			err := checkPermissions(ctx, awsCfg, bastionInstanceIDFlag, preflightServices)
+			cancel()
+			if err != nil {
+				output.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Multi-target profiles list their targets, otherwise the chosen service's resource is the one
+		specs := []config.TargetSpec{{ServiceType: serviceTypeFlag, Port: portFlag, ResourceName: resourceName}}
+		if multiTarget {
+			specs = selectedProfile.Targets
+		}
+		kafka := !multiTarget && serviceTypeFlag == "kafka"
+		if kafka {
+			if backgroundFlag {
+				output.Println("Background mode is not supported for MSK clusters.")
+				os.Exit(1)
+			}
+			if remotePortFlag != 0 {
+				output.Println("--remote-port is not supported for MSK clusters, each broker uses its discovered port.")
+				os.Exit(1)
+			}
+			// Brokers get consecutive local ports from this one, so it's needed before they are resolved
+			if portFlag == "" {
+				portFlag = promptLocalPort(prompt, serviceDefaultPorts["kafka"])
+				specs[0].Port = portFlag
+			}
 		}
 
+		ctx, cancel = awsContext(awsTimeout)
+		targets, err := connect.ResolveTargetSpecs(ctx, awsCfg, promptChooser(prompt), specs, endpointTypeFlag)
+		cancel()
+		// The last connection's resource may have been deleted or renamed since, let the user pick another
+		if err != nil && !multiTarget && last != nil && resourceName == last.ResourceName && prompt.Interactive() {
+			output.Printf("⚠️ Could not resolve %s '%s' from the last connection: %v\n", resourceLabel, resourceName, err)
+			resourceName = selectResourceName(prompt, awsCfg, targetAccountID, serviceTypeFlag, filterFlag, awsTimeout, cacheTTL)
+			specs[0].ResourceName = resourceName
+			ctx, cancel = awsContext(awsTimeout)
+			targets, err = connect.ResolveTargetSpecs(ctx, awsCfg, promptChooser(prompt), specs, endpointTypeFlag)
+			cancel()
+		}
 		if err != nil {
-			fmt.Printf("Error retrieving endpoint: %v\n", err)
+			output.Printf("Error retrieving endpoint: %v\n", err)
 			os.Exit(1)
 		}
 
+		if !multiTarget && !kafka {
+			if remotePortFlag != 0 && int32(remotePortFlag) != targets[0].Port {
+				output.Printf("🎯 Forwarding to remote port %d instead of the discovered %d\n", remotePortFlag, targets[0].Port)
+				targets[0].Port = int32(remotePortFlag)
+			}
+
+			// Default the local port to the endpoint's own port so clients keep their usual settings
+			if portFlag == "" {
+				defaultPort := strconv.Itoa(int(targets[0].Port))
+				if engine := targets[0].Engine; engine != "" {
+					output.Printf("🛠️ %s detected, defaulting local port to %s\n", engine, defaultPort)
+				}
+				portFlag = promptLocalPort(prompt, defaultPort)
+			}
+			targets[0].LocalPort = portFlag
+		}
+
+		summary := dryRunSummary{
+			AccountID:  targetAccountID,
+			RoleName:   roleNameFlag,
+			Region:     regionFlag,
+			InstanceID: bastionInstanceIDFlag,
+			Targets:    targets,
+			Options:    tunnelOpts,
+			Command:    commandFlag,
+		}
+		if dryRunFlag || jsonParamsFlag {
+			printResolved(summary)
+			return
+		}
+
+		if err := confirmTargetsAvailable(prompt, targets); err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if printCredentialsFlag {
+			tunnelOpts.OnReady = credentialsPrinter(fetchDBCredentials(awsCfg, credentialSecretARNFlag, awsTimeout), targets, bindAddressFlag, showPasswordFlag)
+		}
+		if jsonResult {
+			tunnelOpts.OnReady = resultPrinter(jsonOutput, summary, tunnelOpts.OnReady)
+		}
+
 		// 4. Offer to save as profile if manual setup was used (before starting SSM session)
-		if selectedProfile == nil { // Only for manual setup
-			// Get the actual resource names that were used
-			var rdsName, redisName string
-			if serviceTypeFlag == "redis" {
-				redisName = clusterName
-			} else {
-				rdsName = dbName
+		if selectedProfile == nil && last == nil {
+			offerToSaveProfile(cfgManager, prompt, awsProfileFlag, ssoProfileFlag, accountIdFlag, roleNameFlag, regionFlag, serviceTypeFlag, portFlag, bastionInstanceIDFlag, resourceName)
+		}
+		if !multiTarget {
+			lastConnection.ResourceName, lastConnection.Port = resourceName, portFlag
+		}
+
+		if backgroundFlag {
+			endpoint, port := targets[0].Endpoint, targets[0].Port
+			pid, logFile, err := startSSMPortForwardingInBackground(awsCfg, bastionInstanceIDFlag, endpoint, port, portFlag, regionFlag, tunnelOpts)
+			if err != nil {
+				output.Printf("Error starting background SSM session: %v\n", err)
+				os.Exit(1)
+			}
+
+			err = session.AddSession(session.Session{
+				PID:         pid,
+				LocalPort:   portFlag,
+				Target:      bastionInstanceIDFlag,
+				Endpoint:    fmt.Sprintf("%s:%d", endpoint, port),
+				ServiceType: serviceTypeFlag,
+				Profile:     selectedProfileName,
+				LogFile:     logFile,
+				StartedAt:   time.Now(),
+			})
+			if err != nil {
+				output.Printf("⚠️ Warning: failed to record session: %v\n", err)
+			}
+			saveLastConnection(lastConnection)
+
+			output.Printf("🔌 Forwarding `%s` to 127.0.0.1:%s in the background (PID %d)\n", serviceTypeFlag, portFlag, pid)
+			printClientHints(targets[0])
+			if printConnectionStringFlag {
+				printConnectionStrings(targets, bindAddressFlag, dbUserFlag, dbNameFlag)
+			}
+			if tunnelOpts.OnReady != nil {
+				tunnelOpts.OnReady()
+			}
+			output.Printf("💡 Stop it with: bifrost disconnect --port %s\n", portFlag)
+			return
+		}
+
+		switch {
+		case multiTarget:
+			output.Println()
+			for _, target := range targets {
+				output.Printf("🔌 %-10s %s → %s:%s\n", target.ServiceType, target.ResourceName, bindAddressFlag, target.LocalPort)
+				printClientHints(target)
+			}
+		case kafka:
+			output.Println()
+			for _, target := range targets {
+				output.Printf("🔌 broker %s:%d → %s:%s\n", target.Endpoint, target.Port, bindAddressFlag, target.LocalPort)
 			}
-			offerToSaveProfile(cfgManager, prompt, ssoProfileFlag, accountIdFlag, roleNameFlag, regionFlag, serviceTypeFlag, portFlag, bastionInstanceIDFlag, rdsName, redisName)
+			output.Println("💡 Kafka clients connect to the brokers' advertised hostnames after bootstrapping, so map each broker to its local port in your client (or /etc/hosts plus matching ports)")
+		default:
+			output.Printf("🔌 Forwarding `%s` to %s:%s (use this as host in your app or client)\n", serviceTypeFlag, bindAddressFlag, portFlag)
+			printClientHints(targets[0])
+		}
+		if printConnectionStringFlag {
+			printConnectionStrings(targets, bindAddressFlag, dbUserFlag, dbNameFlag)
+		}
+		saveLastConnection(lastConnection)
+		if commandFlag != "" {
+			runClientCommand(awsCfg, bastionInstanceIDFlag, targets, tunnelOpts, commandFlag)
+			return
+		}
+		if len(targets) > 1 {
+			output.Printf("📝 Press Ctrl+C to stop all connections\n\n")
+		} else {
+			output.Printf("📝 Press Ctrl+C to stop the connection\n\n")
 		}
-
-		fmt.Printf("🔌 Forwarding `%s` to 127.0.0.1:%s (use this as host in your app or client)\n", serviceTypeFlag, portFlag)
-		fmt.Printf("📝 Press Ctrl+C to stop the connection\n\n")
 
 		// 5. Set up port forwarding using SSM with keep alive
 		if keepAliveFlag {
-			fmt.Printf("💓 Keep alive enabled (interval: %v)\n", keepAliveInterval)
+			output.Printf("💓 Keep alive enabled (interval: %v)\n", keepAliveInterval)
 		}
-		err = startSSMPortForwardingWithKeepAlive(awsCfg, bastionInstanceIDFlag, endpoint, port, portFlag, regionFlag, keepAliveFlag, keepAliveInterval)
-		if err != nil {
-			fmt.Printf("Error starting SSM session: %v\n", err)
+		if reconnectFlag {
+			output.Printf("🔁 Reconnect enabled (up to %d attempts)\n", maxReconnects)
+		}
+		printSessionLimits(tunnelOpts)
+		if err := startPortForwarding(awsCfg, bastionInstanceIDFlag, targets, tunnelOpts); err != nil {
+			output.Printf("Error running SSM sessions: %v\n", err)
 			os.Exit(1)
 		}
-
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(connectCmd)
 
-	connectCmd.Flags().StringP("service", "s", "", "Service type (rds or redis)")
+	connectCmd.Flags().StringP("service", "s", "", "Service type (rds, redis, documentdb, neptune, opensearch, kafka, keyspaces or custom)")
 	connectCmd.Flags().StringP("port", "p", "", "Local port to use for forwarding")
+	connectCmd.Flags().Int("remote-port", 0, "Port to reach on the resource instead of the one AWS reports (e.g. a proxy on a non-standard port), or on the --host of a custom service")
+	connectCmd.Flags().String("host", "", "Host the bastion forwards to with --service custom (e.g. an internal API or Prometheus)")
 	connectCmd.Flags().StringP("account-id", "a", "", "AWS account ID")
+	connectCmd.Flags().String("account-name", "", "AWS account name, looked up case-insensitively instead of passing --account-id")
 	connectCmd.Flags().StringP("role-name", "r", "", "AWS role name")
 	connectCmd.Flags().String("sso-profile", "", "SSO profile to use for authentication")
+	connectCmd.Flags().String("aws-profile", "", "Profile from ~/.aws/config to take credentials from instead of bifrost's SSO sign-in")
 	connectCmd.Flags().String("region", "", "AWS region where workloads are deployed")
 	connectCmd.Flags().StringP("profile", "P", "", "Connection profile to use")
+	connectCmd.Flags().String("env", "", "Only offer connection profiles for this environment (e.g. dev, stg, prd)")
+	connectCmd.Flags().String("assume-role-arn", "", "Role to assume with the SSO credentials before connecting (for hub and spoke accounts)")
+	connectCmd.Flags().String("external-id", "", "External ID to pass when assuming --assume-role-arn")
 	connectCmd.Flags().String("bastion-instance-id", "", "EC2 instance ID of bastion host (required)")
+	connectCmd.Flags().Bool("all-accounts", false, "Search every SSO account for bastions with --bastion-tag, signing into each with --role-name, instead of selecting the account first")
+	connectCmd.Flags().String("bastion-tag", connect.DefaultBastionTag, "Tag (key=value) that marks bastions for --all-accounts")
 	connectCmd.Flags().Bool("keep-alive", true, "Enable keep alive to maintain SSM connection")
 	connectCmd.Flags().Duration("keep-alive-interval", 30*time.Second, "Interval between keep alive checks")
+	connectCmd.Flags().String("bind-address", connect.DefaultBindAddress, "Local address to accept connections on (e.g. the Docker bridge IP), relayed to the tunnel on 127.0.0.1")
+	connectCmd.Flags().Bool("dry-run", false, "Resolve credentials, bastion and endpoint and print the SSM command without opening the tunnel")
+	connectCmd.Flags().Bool("json-params", false, "Like --dry-run, but print the SSM StartSession request of each tunnel as JSON for tools that start the session themselves")
+	connectCmd.Flags().Bool("include-creds", false, "With --json-params, also print the temporary role credentials (sensitive)")
+	connectCmd.Flags().Bool("no-preflight", false, "Skip the permission probe of the bastion and resources before connecting")
+	connectCmd.Flags().Int("keep-alive-max-failures", 0, "Treat the tunnel as dead after this many keep alive checks fail in a row, reconnecting with --reconnect or exiting otherwise (0 only logs failures)")
+	connectCmd.Flags().Duration("sso-token-warning", connect.DefaultTokenWarning, "With keep alive, refresh the SSO token or warn when less than this is left before it expires (0 disables)")
+	connectCmd.Flags().Bool("trace-protocol", false, "Relay connections through bifrost and report when each opens and closes, the bytes moved and its Postgres/MySQL handshake (never the payload), also to --log-file")
+	connectCmd.Flags().Bool("watch", false, "Show the latency and success rate of the keep alive checks while connected, flagging latency spikes")
+	connectCmd.Flags().String("keep-alive-probe", connect.KeepAliveProbeTCP, "Keep alive check: tcp (local connect only) or protocol (MySQL/Postgres handshake or Redis PING through the tunnel)")
+	connectCmd.Flags().Duration("aws-timeout", connect.DefaultAWSTimeout, "How long to wait for each AWS lookup (bastions, resources, endpoints) before giving up")
+	connectCmd.Flags().Duration("auth-timeout", sso.DefaultAuthTimeout, "How long to wait for the SSO login to be approved in the browser")
+	connectCmd.Flags().Bool("remember", false, "Remember the selected account and role as defaults for the next connect")
+	connectCmd.Flags().Bool("reconnect", false, "Restart the SSM session with backoff if it drops unexpectedly")
+	connectCmd.Flags().Int("max-reconnects", 5, "Maximum number of reconnect attempts when --reconnect is set")
+	connectCmd.Flags().Duration("max-duration", 0, "Close the connection after this long, whether or not it is in use (e.g. 8h, 0 for no limit)")
+	connectCmd.Flags().Duration("idle-timeout", 0, "Close the connection once no client has sent traffic through it for this long (e.g. 30m, 0 for no limit)")
+	connectCmd.Flags().String("credential-secret-arn", "", "Secrets Manager secret holding the database credentials, for --print-credentials")
+	connectCmd.Flags().Bool("print-credentials", false, "Print the database username (and a client command) from the profile's credential secret once the tunnel is up")
+	connectCmd.Flags().Bool("show-password", false, "Also print the password with --print-credentials")
+	connectCmd.Flags().Bool("print-connection-string", false, "Print a connection URL for the tunnel, e.g. postgresql://user@127.0.0.1:5432/db (RDS Postgres and MySQL, Redis)")
+	connectCmd.Flags().String("db-user", "", "Database user for --print-connection-string (overrides the profile's db_user)")
+	connectCmd.Flags().String("db-name", "", "Database name for --print-connection-string (overrides the profile's db_name)")
+	connectCmd.Flags().String("ssm-document", "", "SSM document to start the session with (default "+connect.DefaultSSMDocument+")")
+	connectCmd.Flags().String("ssm-parameters", "", "SSM parameter template for custom documents using {{host}}, {{port}} and {{local_port}}")
+	connectCmd.Flags().Bool("region-from-profile", false, "If the RDS instance or Redis cluster isn't in the region, look for it in the profile's candidate_regions (or all enabled regions) and offer to switch")
+	connectCmd.Flags().Bool("list-resources", false, "List the service's resources in the account and region instead of connecting, one per line or as JSON with --output json")
+	connectCmd.Flags().String("filter", "", "When browsing for the resource, only list those whose name contains this (case-insensitive); a single match is used without asking")
+	connectCmd.Flags().String("endpoint-type", connect.RedisEndpointPrimary, "Redis or Neptune endpoint to connect to (primary or reader)")
+	connectCmd.Flags().Bool("no-cache", false, "Fetch bastion and resource lists from AWS instead of using cached results")
+	connectCmd.Flags().Duration("cache-ttl", cache.DefaultTTL, "How long listed bastions and resources are cached")
+	connectCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation for production profiles and protected accounts")
+	connectCmd.Flags().String("command", "", "Run this client command once the tunnel is ready and close the tunnel when it exits ({{host}} and {{port}} are replaced with the local address)")
+	connectCmd.Flags().Bool("last", false, "Repeat the last successful connection without prompting (other flags override its values)")
+	connectCmd.Flags().Bool("select-multi", false, "Pick several connection profiles and open all of them at once (Ctrl+C stops them all)")
+	connectCmd.Flags().Bool("events", false, "Write lifecycle events (auth_started, token_cached, endpoint_resolved, tunnel_ready, keepalive_ok, tunnel_closed) to stderr as JSON lines for tools wrapping bifrost")
+	connectCmd.Flags().Bool("background", false, "Run the tunnel in the background (stop it with 'bifrost disconnect')")
+
+	_ = connectCmd.RegisterFlagCompletionFunc("profile", completeConnectionProfiles)
+	_ = connectCmd.RegisterFlagCompletionFunc("sso-profile", completeSSOProfiles)
+	_ = connectCmd.RegisterFlagCompletionFunc("service", completeServiceTypes)
+	_ = connectCmd.RegisterFlagCompletionFunc("keep-alive-probe", cobra.FixedCompletions(connect.KeepAliveProbeModes, cobra.ShellCompDirectiveNoFileComp))
+	_ = connectCmd.RegisterFlagCompletionFunc("endpoint-type", cobra.FixedCompletions(connect.RedisEndpointTypes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// profileSummary describes a connection profile's service, environment and region for pickers
+func profileSummary(profile config.ConnectionProfile) string {
+	service := profile.ServiceType
+	if len(profile.Targets) > 0 {
+		services := make([]string, 0, len(profile.Targets))
+		for _, target := range profile.Targets {
+			if !slices.Contains(services, target.ServiceType) {
+				services = append(services, target.ServiceType)
+			}
+		}
+		service = strings.Join(services, "+")
+	}
+
+	parts := make([]string, 0, 3)
+	for _, part := range []string{service, profile.Environment, profile.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Check and load AWS credentials using SSO profile, assuming the chained role if one is given.
+// Without an account ID, the account is looked up by accountName when that is set.
+func getAWSConfig(ssoProfileName, region, accountId, accountName, roleName string, authTimeout time.Duration, chain connect.RoleChain) (aws.Config, string, string, error) {
+	roleCreds, accountId, roleName, err := getRoleCredentials(ssoProfileName, accountId, accountName, roleName, authTimeout)
+	if err != nil {
+		return aws.Config{}, "", "", err
+	}
+
+	awsCfg, err := connect.NewAWSConfig(roleCreds, region, chain)
+	if err != nil {
+		return aws.Config{}, "", "", err
+	}
+
+	return awsCfg, accountId, roleName, nil
+}
+
+// Load credentials from a shared AWS config profile and look up the account they belong to
+func getSharedProfileConfig(awsProfile, region string, chain connect.RoleChain, timeout time.Duration) (aws.Config, string, error) {
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+
+	awsCfg, err := connect.SharedProfileConfig(ctx, awsProfile, region, chain)
+	if err != nil {
+		return aws.Config{}, "", err
+	}
+
+	accountID, err := connect.CallerAccountID(ctx, awsCfg)
+	if err != nil {
+		return aws.Config{}, "", err
+	}
+
+	output.Printf("🔑 Using AWS profile: %s (account %s)\n", awsProfile, accountID)
+	return awsCfg, accountID, nil
 }
 
-// Check and load AWS credentials using SSO profile
-func getAWSConfig(ssoProfileName, region, accountId, roleName string) (aws.Config, string, string, error) {
+// Run the SSO flow and fetch temporary credentials for the account and role, prompting for any that are missing.
+// An account name is resolved to its ID when no account ID is given.
+func getRoleCredentials(ssoProfileName, accountId, accountName, roleName string, authTimeout time.Duration) (*ssotypes.RoleCredentials, string, string, error) {
 	ctx := context.Background()
 	cfgManager := config.NewManager()
 	prompt := ui.NewPrompt()
 
 	// Get SSO profile
-	ssoProfile, err := cfgManager.GetSSOProfile(ssoProfileName)
+	cfg, err := cfgManager.Load()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	ssoProfile, err := cfg.SSOProfile(ssoProfileName)
 	if err != nil {
-		return aws.Config{}, "", "", fmt.Errorf("failed to get SSO profile '%s': %v", ssoProfileName, err)
+		return nil, "", "", fmt.Errorf("failed to get SSO profile '%s': %v", ssoProfileName, err)
 	}
 
 	// Initialize SSO client
-	ssoClient := sso.NewClient(ssoProfile.SSORegion, ssoProfile.StartURL)
+	ssoClient := connect.NewSSOClient(ssoProfile, authTimeout, connect.TokenCacheOptions(cfg)...)
 
 	// Authenticate and get token
 	token, err := ssoClient.Authenticate(ctx)
 	if err != nil {
-		return aws.Config{}, "", "", fmt.Errorf("authentication failed: %v", err)
+		return nil, "", "", fmt.Errorf("authentication failed: %v", err)
+	}
+
+	// Offer the account and role remembered from a previous connect as defaults
+	var remembered state.AccountRole
+	if st, err := state.Load(); err == nil {
+		remembered = st.AccountRoles[ssoProfileName]
 	}
 
 	// List accounts if account ID not provided
 	if accountId == "" {
 		accounts, err := ssoClient.ListAccounts(ctx, token)
 		if err != nil {
-			return aws.Config{}, "", "", fmt.Errorf("failed to list accounts: %v", err)
+			return nil, "", "", fmt.Errorf("failed to list accounts: %v", err)
 		}
 
-		// Select account
-		_, accountId, err = prompt.SelectAccount(accounts)
-		if err != nil {
-			return aws.Config{}, "", "", fmt.Errorf("failed to select account: %v", err)
+		if accountName != "" {
+			accountId, err = sso.AccountIDByName(accounts, accountName)
+			if err != nil {
+				return nil, "", "", err
+			}
+		} else {
+			// Select account
+			_, accountId, err = prompt.SelectAccount(accounts, remembered.AccountID)
+			if err != nil {
+				return nil, "", "", fmt.Errorf("failed to select account: %v", err)
+			}
 		}
 	}
-	fmt.Printf("🪪 Account ID: %s\n", accountId)
+	output.Printf("🪪 Account ID: %s\n", accountId)
 
 	// List roles if role name not provided
 	if roleName == "" {
 		roles, err := ssoClient.ListAccountRoles(ctx, token, accountId)
 		if err != nil {
-			return aws.Config{}, "", "", fmt.Errorf("failed to list roles: %v", err)
+			return nil, "", "", fmt.Errorf("failed to list roles: %v", err)
 		}
 
 		// Select role
-		roleName, err = prompt.SelectRole(roles)
+		roleName, err = prompt.SelectRole(roles, remembered.RoleName)
 		if err != nil {
-			return aws.Config{}, "", "", fmt.Errorf("failed to select role: %v", err)
+			return nil, "", "", fmt.Errorf("failed to select role: %v", err)
 		}
 	}
-	fmt.Printf("👤 Role: %s\n", roleName)
+	output.Printf("👤 Role: %s\n", roleName)
 
 	// Get role credentials
 	roleCreds, err := ssoClient.GetRoleCredentials(ctx, token, accountId, roleName)
 	if err != nil {
-		return aws.Config{}, "", "", fmt.Errorf("failed to get role credentials: %v", err)
-	}
-
-	// Create AWS config with the role credentials and region
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx,
-		awsconfig.WithRegion(region),
-		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			*roleCreds.RoleCredentials.AccessKeyId,
-			*roleCreds.RoleCredentials.SecretAccessKey,
-			*roleCreds.RoleCredentials.SessionToken,
-		)),
-	)
-	if err != nil {
-		return aws.Config{}, "", "", fmt.Errorf("failed to create AWS config: %v", err)
+		return nil, "", "", fmt.Errorf("failed to get role credentials: %v", err)
 	}
 
-	return awsCfg, accountId, roleName, nil
+	return roleCreds.RoleCredentials, accountId, roleName, nil
 }
 
-// List all SSM managed instances that can be used as bastion hosts
-func listSSMManagedInstances(cfg aws.Config) ([]string, map[string]string, error) {
-	ssmSvc := ssm.NewFromConfig(cfg)
-	ec2Svc := ec2.NewFromConfig(cfg)
-	
-	// Get all SSM managed instances
-	ssmResult, err := ssmSvc.DescribeInstanceInformation(context.Background(), &ssm.DescribeInstanceInformationInput{})
+// confirmProtectedAccount asks the user to type the account ID when it is listed in protected_accounts
+func confirmProtectedAccount(cfgManager *config.Manager, prompt *ui.Prompt, accountID string) error {
+	cfg, err := cfgManager.Load()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to list SSM managed instances: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	
-	if len(ssmResult.InstanceInformationList) == 0 {
-		return []string{}, map[string]string{}, nil
+	if !cfg.IsProtectedAccount(accountID) {
+		return nil
 	}
-	
-	// Get instance IDs that are online or connection lost (still manageable)
-	var instanceIds []string
-	for _, instance := range ssmResult.InstanceInformationList {
-		if instance.InstanceId != nil && 
-		   (instance.PingStatus == types.PingStatusOnline || instance.PingStatus == types.PingStatusConnectionLost) {
-			instanceIds = append(instanceIds, *instance.InstanceId)
+
+	output.Printf("🛡️ Account %s is protected\n", accountID)
+	_, err = prompt.Input("Type the account ID to continue", func(s string) error {
+		if s != accountID {
+			return fmt.Errorf("account ID does not match")
 		}
+		return nil
+	})
+	return err
+}
+
+// selectSSOProfile picks the only configured SSO profile or prompts for one, exiting when none exist
+func selectSSOProfile(cfgManager *config.Manager, prompt *ui.Prompt) string {
+	// Try to get default SSO profile (if only one exists)
+	defaultProfile, err := cfgManager.GetDefaultSSOProfile()
+	if err != nil {
+		output.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
 	}
-	
-	if len(instanceIds) == 0 {
-		return []string{}, map[string]string{}, nil
+
+	if defaultProfile != "" {
+		output.Printf("🔐 Using SSO profile: %s\n", defaultProfile)
+		return defaultProfile
 	}
-	
-	// Get EC2 instance details to fetch Name tags
-	ec2Result, err := ec2Svc.DescribeInstances(context.Background(), &ec2.DescribeInstancesInput{
-		InstanceIds: instanceIds,
-	})
+
+	// Load config to show available profiles
+	cfg, err := cfgManager.Load()
 	if err != nil {
-		// If EC2 call fails, just return instance IDs without names
-		displayNames := make([]string, len(instanceIds))
-		instanceMap := make(map[string]string)
-		for i, id := range instanceIds {
-			displayNames[i] = id
-			instanceMap[id] = id
-		}
-		return displayNames, instanceMap, nil
+		output.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
 	}
-	
-	// Build display names and mapping
-	displayNames := make([]string, 0, len(instanceIds))
-	instanceMap := make(map[string]string)
-	
-	for _, reservation := range ec2Result.Reservations {
-		for _, instance := range reservation.Instances {
-			if instance.InstanceId == nil {
-				continue
-			}
-			
-			instanceId := *instance.InstanceId
-			
-			// Find Name tag
-			var name string
-			for _, tag := range instance.Tags {
-				if tag.Key != nil && *tag.Key == "Name" && tag.Value != nil {
-					name = *tag.Value
-					break
-				}
-			}
-			
-			// Create display name
-			var displayName string
-			if name != "" {
-				displayName = fmt.Sprintf("%s (%s)", name, instanceId)
-			} else {
-				displayName = instanceId
-			}
-			
-			displayNames = append(displayNames, displayName)
-			instanceMap[displayName] = instanceId
-		}
+
+	if len(cfg.SSOProfiles) == 0 {
+		output.Println("No SSO profiles found. Please create one with 'bifrost auth configure'")
+		os.Exit(1)
 	}
-	
-	return displayNames, instanceMap, nil
-}
 
-// List all RDS instances in the region
-func listRDSInstances(cfg aws.Config) ([]string, error) {
-	svc := rds.NewFromConfig(cfg)
-	
-	result, err := svc.DescribeDBInstances(context.Background(), &rds.DescribeDBInstancesInput{})
+	selected, err := promptSSOProfile(prompt, "Select SSO profile", cfg.SSOProfiles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list RDS instances: %w", err)
+		output.Printf("Error selecting profile: %v\n", err)
+		os.Exit(1)
 	}
-	
-	if len(result.DBInstances) == 0 {
-		return []string{}, nil
+	return selected
+}
+
+// promptSSOProfile asks for one of the SSO profiles, listed by name with their descriptions, and returns its name
+func promptSSOProfile(prompt *ui.Prompt, label string, profiles map[string]config.SSOProfile) (string, error) {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
 	}
-	
-	instances := make([]string, 0, len(result.DBInstances))
-	for _, db := range result.DBInstances {
-		if db.DBInstanceIdentifier != nil {
-			instances = append(instances, *db.DBInstanceIdentifier)
-		}
+	slices.Sort(names)
+
+	options := make([]string, 0, len(names))
+	optionNames := make(map[string]string, len(names))
+	for _, name := range names {
+		label := profiles[name].Label(name)
+		options = append(options, label)
+		optionNames[label] = name
 	}
-	
-	return instances, nil
+
+	selected, err := prompt.Select(label, options)
+	if err != nil {
+		return "", err
+	}
+	return optionNames[selected], nil
 }
 
-// Get the RDS database endpoint by DB instance name
-func getRDSEndpoint(cfg aws.Config, dbInstanceName string) (string, int32, error) {
-	if dbInstanceName == "" {
-		return "", 0, fmt.Errorf("RDS instance name cannot be empty")
+// selectResourceName asks for the resource to connect to, listing the region's resources if left empty.
+// With a filter it goes straight to the list, narrowed to the resources whose name contains it.
+func selectResourceName(prompt *ui.Prompt, cfg aws.Config, accountID, serviceType, filter string, awsTimeout, cacheTTL time.Duration) string {
+	resourceLabel := serviceResourceLabels[serviceType]
+	if filter == "" {
+		resourceName, err := prompt.Input(fmt.Sprintf("Enter %s name (or leave empty to browse)", resourceLabel), nil)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if resourceName != "" {
+			return resourceName
+		}
 	}
-	svc := rds.NewFromConfig(cfg)
 
-	// Get specific DB instance by name
-	result, err := svc.DescribeDBInstances(context.Background(), &rds.DescribeDBInstancesInput{
-		DBInstanceIdentifier: &dbInstanceName,
-	})
+	// If user left it empty, show available resources
+	resources, err := listResources(cfg, accountID, serviceType, awsTimeout, cacheTTL)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to describe DB instance '%s': %w", dbInstanceName, err)
+		output.Printf("Error listing %ss: %v\n", resourceLabel, err)
+		os.Exit(1)
 	}
 
-	if len(result.DBInstances) == 0 {
-		return "", 0, fmt.Errorf("DB instance '%s' not found", dbInstanceName)
+	if len(resources) == 0 {
+		output.Printf("No %ss found in this region.\n", resourceLabel)
+		os.Exit(1)
 	}
 
-	db := result.DBInstances[0]
-	if db.Endpoint == nil {
-		return "", 0, fmt.Errorf("DB instance '%s' does not have an endpoint (may not be available)", dbInstanceName)
+	if filter != "" {
+		resources = filterResources(resources, filter)
+		switch len(resources) {
+		case 0:
+			output.Printf("No %ss matching '%s' found in this region.\n", resourceLabel, filter)
+			os.Exit(1)
+		case 1:
+			output.Printf("🔍 Only %s matching '%s': %s\n", resourceLabel, filter, resources[0])
+			return resources[0]
+		}
+		output.Printf("🔍 %d %ss match '%s'\n", len(resources), resourceLabel, filter)
 	}
 
-	fmt.Printf("🎯 Connecting to RDS instance: %s\n", *db.DBInstanceIdentifier)
-	return *db.Endpoint.Address, int32(*db.Endpoint.Port), nil
+	resourceName, err := prompt.SelectFilterable("Select "+resourceLabel, resources)
+	if err != nil {
+		output.Printf("Error selecting %s: %v\n", resourceLabel, err)
+		os.Exit(1)
+	}
+	return resourceName
 }
 
-// List all Redis clusters in the region
-func listRedisClusters(cfg aws.Config) ([]string, error) {
-	svc := elasticache.NewFromConfig(cfg)
-	
-	result, err := svc.DescribeReplicationGroups(context.Background(), &elasticache.DescribeReplicationGroupsInput{})
+// listResources lists the region's resources of a service type, from the cache if they were listed recently
+func listResources(cfg aws.Config, accountID, serviceType string, awsTimeout, cacheTTL time.Duration) ([]string, error) {
+	ctx, cancel := awsContext(awsTimeout)
+	defer cancel()
+	return cache.Fetch(cache.Key(accountID, cfg.Region, serviceType), cacheTTL, func() (resources []string, err error) {
+		err = ui.WithSpinner(fmt.Sprintf("Listing %ss…", serviceResourceLabels[serviceType]), func() error {
+			resources, err = connect.ListResources(ctx, cfg, serviceType)
+			return err
+		})
+		return resources, err
+	})
+}
+
+// resourceList is what connect --list-resources prints with --output json
+type resourceList struct {
+	AccountID string   `json:"account_id"`
+	Region    string   `json:"region"`
+	Service   string   `json:"service"`
+	Resources []string `json:"resources"`
+}
+
+// printResources writes the region's resources of a service type to w, one per line or as JSON,
+// narrowed to those containing filter when it is set
+func printResources(w io.Writer, cfg aws.Config, accountID, serviceType, filter string, asJSON bool, awsTimeout, cacheTTL time.Duration) {
+	resources, err := listResources(cfg, accountID, serviceType, awsTimeout, cacheTTL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list Redis clusters: %w", err)
+		output.Printf("Error listing %ss: %v\n", serviceResourceLabels[serviceType], err)
+		os.Exit(1)
 	}
-	
-	if len(result.ReplicationGroups) == 0 {
-		return []string{}, nil
+	if filter != "" {
+		resources = filterResources(resources, filter)
 	}
-	
-	clusters := make([]string, 0, len(result.ReplicationGroups))
-	for _, cluster := range result.ReplicationGroups {
-		if cluster.ReplicationGroupId != nil {
-			clusters = append(clusters, *cluster.ReplicationGroupId)
+
+	if asJSON {
+		writeJSON(w, resourceList{
+			AccountID: accountID,
+			Region:    cfg.Region,
+			Service:   serviceType,
+			Resources: append([]string{}, resources...),
+		})
+		return
+	}
+	if len(resources) == 0 {
+		output.Printf("No %ss found in this region.\n", serviceResourceLabels[serviceType])
+		return
+	}
+	for _, resource := range resources {
+		_, _ = fmt.Fprintln(w, resource)
+	}
+}
+
+// customEndpoint returns the host:port of a custom service, asking for whichever of host and port
+// wasn't given as a flag
+func customEndpoint(prompt *ui.Prompt, host string, port int) string {
+	if host == "" {
+		result, err := prompt.Input("Enter the host to forward to (as the bastion resolves it)", connect.ValidateCustomHost)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
+		host = result
 	}
-	
-	return clusters, nil
+	if port == 0 {
+		result, err := prompt.Input(fmt.Sprintf("Enter the port on %s", host), validateRemotePort)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		port, _ = strconv.Atoi(result)
+	}
+	return connect.CustomEndpoint(host, port)
 }
 
-// Get the Redis cluster endpoint by replication group name
-func getRedisEndpoint(cfg aws.Config, clusterName string) (string, int32, error) {
-	if clusterName == "" {
-		return "", 0, fmt.Errorf("redis cluster name cannot be empty")
+// validateOptionalInstanceID accepts an empty answer or a bastion instance ID
+func validateOptionalInstanceID(input string) error {
+	if input == "" {
+		return nil
 	}
-	svc := elasticache.NewFromConfig(cfg)
+	return connect.ValidateInstanceID(input)
+}
 
-	ctx := context.Background()
-	result, err := svc.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
-		ReplicationGroupId: &clusterName,
-	})
+// verifyBastion exits when the bastion isn't registered with SSM in the region, which would otherwise
+// only show up as a failing session. Lookup errors (e.g. missing permissions) don't stop the connection.
+func verifyBastion(cfg aws.Config, instanceID string, timeout time.Duration) {
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+
+	info, err := describeBastion(ctx, cfg, instanceID)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to describe Redis cluster '%s': %w", clusterName, err)
+		slog.Debug("could not verify bastion", "instance_id", instanceID, "error", connect.AWSError(ctx, err))
+		return
 	}
-
-	if len(result.ReplicationGroups) == 0 {
-		return "", 0, fmt.Errorf("redis cluster '%s' not found", clusterName)
+	if info == nil {
+		output.Printf("Error: bastion %s is not registered with SSM in %s, check the ID and region or leave it empty to browse\n", instanceID, cfg.Region)
+		os.Exit(1)
 	}
+}
 
-	cluster := result.ReplicationGroups[0]
-
-	// Ensure NodeGroups is non-empty and PrimaryEndpoint is not nil
-	if len(cluster.NodeGroups) == 0 {
-		return "", 0, fmt.Errorf("redis cluster '%s' has no node groups", clusterName)
+// validateRemotePort checks a port on a remote host, which unlike a local port needn't be free here
+func validateRemotePort(input string) error {
+	port, err := strconv.Atoi(input)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("port must be a number between 1 and 65535")
 	}
+	return nil
+}
 
-	if cluster.NodeGroups[0].PrimaryEndpoint == nil {
-		return "", 0, fmt.Errorf("redis cluster '%s' does not have a primary endpoint (may not be available)", clusterName)
+// filterResources keeps the resource names containing filter, ignoring case
+func filterResources(resources []string, filter string) []string {
+	filter = strings.ToLower(filter)
+	var matches []string
+	for _, resource := range resources {
+		if strings.Contains(strings.ToLower(resource), filter) {
+			matches = append(matches, resource)
+		}
 	}
+	return matches
+}
 
-	fmt.Printf("🎯 Connecting to Redis cluster: %s\n", *cluster.ReplicationGroupId)
-	return *cluster.NodeGroups[0].PrimaryEndpoint.Address, int32(*cluster.NodeGroups[0].PrimaryEndpoint.Port), nil
+// saveLastConnection records the connection for connect --last
+func saveLastConnection(last state.LastConnection) {
+	last.ConnectedAt = time.Now()
+	if err := state.SaveLastConnection(last); err != nil {
+		output.Printf("⚠️ Warning: failed to record last connection: %v\n", err)
+	}
 }
 
-// Start SSM port forwarding session with keep alive functionality
-func startSSMPortForwardingWithKeepAlive(cfg aws.Config, instanceID, endpoint string, port int32, localPort string, workloadRegion string, keepAlive bool, keepAliveInterval time.Duration) error {
-	// Construct the SSM command
-	ssmArgs := []string{
-		"ssm", "start-session",
-		"--target", instanceID,
-		"--region", workloadRegion,
-		"--document-name", "AWS-StartPortForwardingSessionToRemoteHost",
-		"--parameters", fmt.Sprintf("host=%s,portNumber=%d,localPortNumber=%s", endpoint, port, localPort),
+// selectOfflineBastion explains why no bastion is selectable and lets the user pick an offline one anyway
+func selectOfflineBastion(prompt *ui.Prompt, offlineErr *connect.NoOnlineInstancesError) (string, error) {
+	output.Printf("⚠️ %v:\n", offlineErr)
+	labels := make([]string, 0, len(offlineErr.Instances))
+	instanceMap := make(map[string]string)
+	for _, instance := range offlineErr.Instances {
+		lastPing := "never"
+		if !instance.LastPing.IsZero() {
+			lastPing = instance.LastPing.Local().Format(time.DateTime)
+		}
+		output.Printf("   %s - %s, last ping %s\n", instance.DisplayName, instance.PingStatus, lastPing)
+
+		label := fmt.Sprintf("%s [%s]", instance.DisplayName, instance.PingStatus)
+		labels = append(labels, label)
+		instanceMap[label] = instance.InstanceID
 	}
+	output.Println("💡 Check the SSM agent is running and the instance can reach the SSM endpoints")
 
-	// Create command
-	cmd := exec.Command("aws", ssmArgs...)
+	if !prompt.Interactive() {
+		return "", offlineErr
+	}
 
-	// Get AWS credentials from the config
-	creds, err := cfg.Credentials.Retrieve(context.Background())
+	selected, err := prompt.SelectFilterable("Select an offline bastion anyway (the session will likely fail)", labels)
 	if err != nil {
-		return fmt.Errorf("failed to get credentials from config: %w", err)
+		return "", err
 	}
+	return instanceMap[selected], nil
+}
 
-	// Set AWS credentials from the config
-	cmd.Env = append(os.Environ(),
-		"AWS_ACCESS_KEY_ID="+creds.AccessKeyID,
-		"AWS_SECRET_ACCESS_KEY="+creds.SecretAccessKey,
-		"AWS_SESSION_TOKEN="+creds.SessionToken,
-		"AWS_REGION="+workloadRegion,
-	)
+// bastionInstances is the cached result of listSSMManagedInstances
+type bastionInstances struct {
+	Names []string          `json:"names"`
+	IDs   map[string]string `json:"ids"`
+	VPCs  map[string]string `json:"vpcs"`
+}
 
-	// Connect stdin/stdout/stderr
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// Labels of bastions in the VPC of the resources being connected to, and of the one picked last time
+const (
+	sameVPCMarker  = "✅ in-vpc"
+	lastUsedMarker = "(last used)"
+)
 
-	// Set up signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+// targetVPC looks up the VPC the targets run in, empty if it's unknown or they span several VPCs.
+// It only guides the bastion pick, so lookup failures are logged rather than reported.
+func targetVPC(cfg aws.Config, specs []config.TargetSpec, timeout time.Duration) string {
+	ctx, cancel := awsContext(timeout)
 	defer cancel()
 
-	// Handle interrupt signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	vpc := ""
+	for _, spec := range specs {
+		if spec.ResourceName == "" {
+			continue
+		}
+		var id string
+		err := ui.WithSpinner("Looking up the resource's VPC…", func() (err error) {
+			id, err = connect.ResourceVPC(ctx, cfg, spec.ServiceType, spec.ResourceName)
+			return err
+		})
+		if err != nil {
+			slog.Debug("could not look up the resource VPC", "service", spec.ServiceType, "resource", spec.ResourceName, "error", err)
+			return ""
+		}
+		switch {
+		case id == "":
+		case vpc == "":
+			vpc = id
+		case vpc != id:
+			return ""
+		}
+	}
+	return vpc
+}
 
-	// Start the SSM session in a goroutine
-	errChan := make(chan error, 1)
-	go func() {
-		errChan <- cmd.Run()
-	}()
+// lastUsedBastion returns the bastion picked last time in the VPC or region if it is still listed.
+// One that is no longer SSM-managed is forgotten.
+func lastUsedBastion(bastions bastionInstances, accountID, region, vpc string) string {
+	id, err := state.LastBastion(accountID, region, vpc)
+	if err != nil || id == "" {
+		return ""
+	}
+	for _, listed := range bastions.IDs {
+		if listed == id {
+			return id
+		}
+	}
 
-	// Start keep alive functionality if enabled (wait for SSM tunnel to be ready)
-	if keepAlive {
-		go startKeepAliveWhenReady(ctx, localPort, keepAliveInterval)
+	slog.Debug("forgetting last used bastion, it is no longer SSM-managed", "instance", id)
+	if err := state.ForgetBastion(accountID, region, id); err != nil {
+		output.Printf("⚠️ Warning: failed to forget bastion %s: %v\n", id, err)
 	}
+	return ""
+}
 
-	// Wait for either the command to finish, an error, or a signal
-	select {
-	case err := <-errChan:
-		return err
-	case <-sigChan:
-		fmt.Println("\n🛑 Shutting down connection...")
-		cancel()
+// bastionOptions lists the bastions for the picker, the last used one first, then those in vpc, all
+// marked. It preselects the last used bastion or else the first one in vpc.
+func bastionOptions(bastions bastionInstances, vpc, lastUsed string) ([]string, map[string]string, string) {
+	var last, same, other []string
+	labels := make(map[string]string, len(bastions.Names))
+	for _, name := range bastions.Names {
+		id := bastions.IDs[name]
+		label := name
+		inVPC := vpc != "" && bastions.VPCs[id] == vpc
+		if inVPC {
+			label = fmt.Sprintf("%s %s", label, sameVPCMarker)
+		}
+		if id == lastUsed {
+			label = fmt.Sprintf("%s %s", label, lastUsedMarker)
+		}
+		labels[label] = id
 
-		// Terminate the SSM process
-		if cmd.Process != nil {
-			if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
-				fmt.Printf("Warning: failed to send termination signal: %v\n", err)
-			}
+		switch {
+		case id == lastUsed:
+			last = append(last, label)
+		case inVPC:
+			same = append(same, label)
+		default:
+			other = append(other, label)
 		}
+	}
 
-		// Wait a bit for graceful shutdown
-		time.Sleep(1 * time.Second)
-		return nil
+	inVPC := len(same)
+	if len(last) > 0 && bastions.VPCs[lastUsed] == vpc {
+		inVPC++
+	}
+	if vpc != "" && inVPC > 0 {
+		output.Printf("💡 %d of %d bastions are in the resource's VPC (%s), listed first\n", inVPC, len(bastions.Names), vpc)
+	}
+
+	options := append(append(last, same...), other...)
+	preselected := ""
+	if len(last) > 0 || len(same) > 0 {
+		preselected = options[0]
 	}
+	return options, labels, preselected
 }
 
-// Start keep alive when SSM tunnel becomes ready (no arbitrary delay)
-func startKeepAliveWhenReady(ctx context.Context, localPort string, interval time.Duration) {
-	// Poll until the SSM tunnel is ready (check every 500ms for up to 30 seconds)
-	maxAttempts := 60 // 30 seconds with 500ms intervals
-	for range maxAttempts {
-		select {
-		case <-ctx.Done():
-			return
-		default:
+// confirmTargetsAvailable warns about RDS instances that aren't available (stopped, rebooting, ...),
+// where the tunnel would open but never reach the database, and asks whether to connect anyway.
+// Without a terminal to ask on it fails instead.
+func confirmTargetsAvailable(prompt *ui.Prompt, targets []connect.Target) error {
+	for _, target := range targets {
+		if target.Status == "" || target.Status == connect.RDSStatusAvailable {
+			continue
 		}
-
-		if err := performKeepAlive(localPort); err == nil {
-			// Connection successful, start regular keep alive
-			startKeepAlive(ctx, localPort, interval)
-			return
+		output.Printf("⚠️ Warning: %s '%s' is %s, connections through the tunnel will fail until it is available\n", serviceResourceLabels[target.ServiceType], target.ResourceName, target.Status)
+		if !prompt.Interactive() {
+			return fmt.Errorf("%s '%s' is %s, not available", serviceResourceLabels[target.ServiceType], target.ResourceName, target.Status)
 		}
-
-		// Wait 500ms before retrying
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(500 * time.Millisecond):
+		confirmed, err := prompt.Confirm("Connect anyway?")
+		if err != nil || !confirmed {
+			return fmt.Errorf("connection cancelled")
 		}
 	}
+	return nil
+}
 
-	// If we get here, the tunnel never became ready
-	fmt.Printf("⚠️ Keep alive disabled - SSM tunnel did not become ready within 30 seconds\n")
+// promptLocalPort asks for the local port to forward, offering defaultPort
+func promptLocalPort(prompt *ui.Prompt, defaultPort string) string {
+	result, err := prompt.Input("Enter local port to use for forwarding", connect.ValidatePort, defaultPort)
+	if err != nil {
+		output.Printf("Prompt failed %v\n", err)
+		os.Exit(1)
+	}
+	output.Printf("🌐 Port: %s\n", result)
+	return result
 }
 
-// Keep alive functionality
-func startKeepAlive(ctx context.Context, localPort string, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// promptChooser adapts the prompt to the chooser the connect package asks its questions through
+func promptChooser(prompt *ui.Prompt) connect.Chooser {
+	return func(label string, options []string) (string, error) {
+		return prompt.Select(label, options)
+	}
+}
 
-	for {
-		select {
-		case <-ctx.Done():
+// printClientHints explains how to reach services that need TLS or a token through the local port
+func printClientHints(target connect.Target) {
+	switch target.ServiceType {
+	case "opensearch":
+		output.Printf("🔒 OpenSearch only serves HTTPS: connect over TLS with %s as the hostname (SNI), e.g.\n", target.Endpoint)
+		output.Printf("   curl --connect-to %s:443:127.0.0.1:%s https://%s/\n", target.Endpoint, target.LocalPort, target.Endpoint)
+	case "neptune":
+		output.Println("🔒 Neptune only accepts TLS, and SigV4-signed requests when IAM database authentication is on")
+		output.Printf("   Gremlin, SPARQL and openCypher clients connect to https://127.0.0.1:%s, verifying the certificate against %s\n", target.LocalPort, target.Endpoint)
+		output.Printf("   e.g. curl --connect-to %[1]s:%[2]d:127.0.0.1:%[3]s https://%[1]s:%[2]d/status\n", target.Endpoint, target.Port, target.LocalPort)
+	case "keyspaces":
+		output.Println("🔒 Amazon Keyspaces only accepts TLS clients that sign in with SigV4 (or service-specific credentials)")
+		output.Printf("   Point your driver at 127.0.0.1:%s with TLS and the SigV4 auth plugin, verifying the certificate against %s\n", target.LocalPort, target.Endpoint)
+	case "redis":
+		args := []string{"redis-cli"}
+		var needs []string
+		if target.TLSRequired {
+			needs = append(needs, "TLS")
+			args = append(args, "--tls", "--sni", target.Endpoint)
+		}
+		args = append(args, "-h", "127.0.0.1", "-p", target.LocalPort)
+		if target.AuthRequired {
+			needs = append(needs, "an AUTH token")
+			args = append(args, "-a", "<auth-token>")
+		}
+		if len(needs) == 0 {
 			return
-		case <-ticker.C:
-			if err := performKeepAlive(localPort); err != nil {
-				// Log error but continue - keep alive failures shouldn't stop the connection
-				fmt.Printf("⚠️ Keep alive check failed: %v\n", err)
-			}
 		}
+		output.Printf("⚠️ This cluster requires %s, plain connections are refused. Connect with e.g.\n", strings.Join(needs, " and "))
+		output.Printf("   %s\n", strings.Join(args, " "))
+		if target.TLSRequired {
+			output.Printf("   Clients that verify the certificate's hostname need it set to %s, not 127.0.0.1\n", target.Endpoint)
+		}
+	}
+}
+
+// printConnectionStrings prints the connection URL of each target that has one, or says that none could be built
+func printConnectionStrings(targets []connect.Target, host, user, dbName string) {
+	printed := false
+	for _, target := range targets {
+		if connStr := connect.ConnectionString(target, host, user, dbName); connStr != "" {
+			output.Printf("🔗 Connection string: %s\n", connStr)
+			printed = true
+		}
+	}
+	if !printed {
+		output.Printf("⚠️ No connection string for %s, it is only known for RDS Postgres and MySQL, and Redis\n", targets[0].ServiceType)
 	}
 }
 
-// Perform a keep alive check by attempting a TCP connection to the local port
-func performKeepAlive(localPort string) error {
-	// Simple TCP connection test to keep the SSM tunnel alive
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%s", localPort), 5*time.Second)
+// fetchDBCredentials reads the --print-credentials secret before the tunnel starts, so a missing
+// permission or malformed secret is reported up front
+func fetchDBCredentials(cfg aws.Config, secretARN string, timeout time.Duration) connect.DBCredentials {
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+	creds, err := connect.FetchDBCredentials(ctx, cfg, secretARN)
 	if err != nil {
-		return fmt.Errorf("failed to connect to local port %s: %w", localPort, err)
+		output.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
-	defer func() {
-		_ = conn.Close() // Ignore error - this is cleanup
-	}()
+	return creds
+}
 
-	// Connection successful - SSM tunnel is alive
-	return nil
+// credentialsPrinter returns the TunnelOptions.OnReady hook printing the database user, and a client
+// command for the first target whose engine is known. The password is only printed with showPassword.
+func credentialsPrinter(creds connect.DBCredentials, targets []connect.Target, host string, showPassword bool) func() {
+	return func() {
+		output.Printf("🔑 Username: %s\n", creds.Username)
+		if showPassword {
+			output.Printf("🔑 Password: %s\n", creds.Password)
+		}
+		for _, target := range targets {
+			if command := creds.ClientCommand(target.Engine, host, target.LocalPort); command != "" {
+				output.Println("💡 Connect with:")
+				output.Printf("   %s\n", command)
+				return
+			}
+		}
+	}
+}
+
+// Start one SSM port forwarding session per target through the same bastion, tearing them all down
+// together. A lone session gets stdin as well, several can't share it.
+func startPortForwarding(cfg aws.Config, instanceID string, targets []connect.Target, opts connect.TunnelOptions) error {
+	if len(targets) == 1 {
+		opts.Stdin = os.Stdin
+	}
+	opts.Stdout = logging.Tee(output.Status())
+	opts.Stderr = logging.Tee(os.Stderr)
+	return runTunnels(cfg, instanceID, targets, opts)
 }
 
-func validatePort(input string) error {
-	inputPort, err := strconv.Atoi(input)
+// Run the tunnels until they exit or an interrupt signal is received
+func runTunnels(cfg aws.Config, instanceID string, targets []connect.Target, opts connect.TunnelOptions) error {
+	// Set up signal handling for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchForShutdown(ctx, cancel)
+
+	started := time.Now()
+	tunnels, err := connect.Start(ctx, cfg, instanceID, targets, opts)
 	if err != nil {
-		return fmt.Errorf("invalid port number: %s", input)
+		return err
+	}
+	err = tunnels.Wait()
+	recordSessionMetrics(started, tunnels, err)
+	return err
+}
+
+// printSessionLimits tells the user when the connection will be closed for them
+func printSessionLimits(opts connect.TunnelOptions) {
+	if opts.MaxDuration > 0 {
+		output.Printf("⏱️ Closes after %v\n", opts.MaxDuration)
 	}
-	if inputPort < 1 || inputPort > 65535 {
-		return fmt.Errorf("port number must be between 1 and 65535")
+	if opts.IdleTimeout > 0 {
+		output.Printf("💤 Closes after %v without client traffic\n", opts.IdleTimeout)
 	}
-	// Check if the port is already in use
-	if isPortInUse(inputPort) {
-		return fmt.Errorf("port %d is already in use", inputPort)
+}
+
+// awsContext bounds an AWS lookup by timeout and cancels it on Ctrl+C
+func awsContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), timeout)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	return ctx, func() {
+		stop()
+		cancelTimeout()
 	}
-	return nil
 }
 
-func isPortInUse(port int) bool {
-	conn, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// Cancel the context when an interrupt signal is received
+func watchForShutdown(ctx context.Context, cancel context.CancelFunc) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(sigChan)
+		select {
+		case <-sigChan:
+			output.Println("\n🛑 Shutting down connection...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// Start SSM port forwarding detached from the terminal and return the PID once the tunnel accepts connections
+func startSSMPortForwardingInBackground(cfg aws.Config, instanceID, endpoint string, port int32, localPort string, workloadRegion string, opts connect.TunnelOptions) (int, string, error) {
+	if err := connect.CheckPrerequisites(); err != nil {
+		return 0, "", err
+	}
+
+	cmd, err := connect.NewSSMCommand(cfg, instanceID, endpoint, port, localPort, workloadRegion, opts)
 	if err != nil {
-		return true
+		return 0, "", err
+	}
+
+	bifrostDir, err := config.GetBifrostDir()
+	if err != nil {
+		return 0, "", err
+	}
+
+	// Send the session output to a log file since there's no terminal to write to
+	logFile := filepath.Join(bifrostDir, fmt.Sprintf("tunnel-%s.log", localPort))
+	logOutput, err := os.Create(logFile)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer func() {
+		_ = logOutput.Close() // The child keeps its own handle
+	}()
+
+	cmd.Stdout = logOutput
+	cmd.Stderr = logOutput
+	detachProcess(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, "", fmt.Errorf("failed to start SSM session: %w", err)
 	}
-	if err := conn.Close(); err != nil {
-		// Log the error but don't affect the port check result
-		fmt.Fprintf(os.Stderr, "Warning: failed to close connection: %v\n", err)
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	// Wait until the tunnel is ready (check every 500ms for up to 30 seconds)
+	for range 60 {
+		select {
+		case err := <-exited:
+			return 0, logFile, fmt.Errorf("SSM session exited early (%v), see %s", err, logFile)
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		if err := connect.PerformKeepAlive(net.JoinHostPort(connect.DefaultBindAddress, localPort)); err == nil {
+			return cmd.Process.Pid, logFile, nil
+		}
 	}
-	return false
+
+	// Nothing will know about the session to stop it later, so it mustn't keep holding the port
+	_ = process.Stop(cmd.Process, exited, connect.SessionShutdownTimeout)
+	return 0, logFile, fmt.Errorf("SSM tunnel did not become ready within 30 seconds, see %s", logFile)
 }
 
 // offerToSaveProfile prompts the user to save the manual connection configuration as a profile
-func offerToSaveProfile(cfgManager *config.Manager, prompt *ui.Prompt, ssoProfile, accountID, roleName, region, serviceType, port, bastionInstanceID, rdsInstanceName, redisClusterName string) {
-	fmt.Println() // Add some spacing
+func offerToSaveProfile(cfgManager *config.Manager, prompt *ui.Prompt, awsProfile, ssoProfile, accountID, roleName, region, serviceType, port, bastionInstanceID, resourceName string) {
+	if !prompt.Interactive() {
+		return
+	}
+
+	output.Println() // Add some spacing
 
 	// Ask if they want to save the configuration
 	confirmed, err := prompt.Confirm("Would you like to save this configuration as a connection profile for future use?")
@@ -798,26 +1862,25 @@ func offerToSaveProfile(cfgManager *config.Manager, prompt *ui.Prompt, ssoProfil
 
 	// Prompt for profile name
 	defaultName := serviceType
-	if rdsInstanceName != "" {
-		defaultName = rdsInstanceName
-	} else if redisClusterName != "" {
-		defaultName = redisClusterName
+	if resourceName != "" {
+		defaultName = resourceName
 	}
 	profileName, err := prompt.Input("Profile name", nil, defaultName)
 	if err != nil {
-		fmt.Printf("Error getting profile name: %v\n", err)
+		output.Printf("Error getting profile name: %v\n", err)
 		return
 	}
 
 	// Ask where to save (local vs global)
 	saveLocation, err := prompt.Select("Where would you like to save this profile?", []string{"📁 Local (.bifrost.config.yaml)", "🌍 Global (~/.bifrost/config.yaml)"})
 	if err != nil {
-		fmt.Printf("Error selecting save location: %v\n", err)
+		output.Printf("Error selecting save location: %v\n", err)
 		return
 	}
 
 	// Create connection profile
 	connectionProfile := config.ConnectionProfile{
+		AWSProfile:        awsProfile,
 		SSOProfile:        ssoProfile,
 		AccountID:         accountID,
 		RoleName:          roleName,
@@ -825,28 +1888,27 @@ func offerToSaveProfile(cfgManager *config.Manager, prompt *ui.Prompt, ssoProfil
 		ServiceType:       serviceType,
 		Port:              port,
 		BastionInstanceID: bastionInstanceID,
-		RDSInstanceName:   rdsInstanceName,
-		RedisClusterName:  redisClusterName,
 	}
+	connectionProfile.SetResourceName(serviceType, resourceName)
 
 	// Save the profile
 	var saveErr error
 	if saveLocation == "🌍 Global (~/.bifrost/config.yaml)" {
 		saveErr = cfgManager.AddConnectionProfile(profileName, connectionProfile)
 		if saveErr == nil {
-			fmt.Printf("✅ Connection profile '%s' saved to global config\n", profileName)
+			output.Printf("✅ Connection profile '%s' saved to global config\n", profileName)
 		}
 	} else {
 		saveErr = cfgManager.AddLocalConnectionProfile(profileName, connectionProfile)
 		if saveErr == nil {
-			fmt.Printf("✅ Connection profile '%s' saved to local config (.bifrost.config.yaml)\n", profileName)
+			output.Printf("✅ Connection profile '%s' saved to local config (%s)\n", profileName, config.LocalConfigPath())
 		}
 	}
 
 	if saveErr != nil {
-		fmt.Printf("❌ Error saving profile: %v\n", saveErr)
+		output.Printf("❌ Error saving profile: %v\n", saveErr)
 		return
 	}
 
-	fmt.Printf("💡 You can now use this profile with: bifrost connect --profile %s\n", profileName)
+	output.Printf("💡 You can now use this profile with: bifrost connect --profile %s\n", profileName)
 }
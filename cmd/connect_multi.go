@@ -0,0 +1,312 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// multiProfileOptions are the connect flags that apply to every profile opened with --select-multi
+type multiProfileOptions struct {
+	Env          string
+	EndpointType string
+	AWSTimeout   time.Duration
+	AuthTimeout  time.Duration
+	Yes          bool
+	NoPreflight  bool
+	DryRun       bool
+	Tunnel       connect.TunnelOptions
+}
+
+// profileCredentials is a sign-in shared by the profiles using the same credentials
+type profileCredentials struct {
+	AWSConfig aws.Config
+	AccountID string
+	RoleName  string
+}
+
+// profileConnection is one selected profile, resolved and ready to forward
+type profileConnection struct {
+	Name       string
+	AWSConfig  aws.Config
+	AccountID  string
+	RoleName   string
+	InstanceID string
+	Targets    []connect.Target
+	Options    connect.TunnelOptions
+}
+
+// connectMultipleProfiles asks for several connection profiles and forwards all of them at once,
+// each through its own bastion and credentials. Ctrl+C or any tunnel going down stops them all.
+func connectMultipleProfiles(cfgManager *config.Manager, prompt *ui.Prompt, opts multiProfileOptions) {
+	cfg, err := cfgManager.Load()
+	if err != nil {
+		output.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	profiles := filterProfilesByEnvironment(cfg.ConnectionProfiles, opts.Env)
+	if len(profiles) == 0 {
+		fmt.Println("No connection profiles configured. Use 'bifrost profile create' to create one.")
+		os.Exit(1)
+	}
+
+	labels := make([]string, 0, len(profiles))
+	names := make(map[string]string, len(profiles))
+	for _, name := range sortProfilesByEnvironment(profiles) {
+		label := name
+		if summary := profileSummary(profiles[name]); summary != "" {
+			label = fmt.Sprintf("%s (%s)", name, summary)
+		}
+		labels = append(labels, label)
+		names[label] = name
+	}
+
+	selected, err := prompt.MultiSelect("Select the connection profiles to open (space to toggle)", labels)
+	if err != nil {
+		output.Printf("Error selecting profiles: %v\n", err)
+		os.Exit(1)
+	}
+	if len(selected) == 0 {
+		fmt.Println("No profiles selected.")
+		os.Exit(1)
+	}
+
+	// Sign-ins are shared between profiles using the same credentials
+	credentials := make(map[string]profileCredentials)
+	usedPorts := make(map[string]string)
+	var connections []profileConnection
+	for _, label := range selected {
+		name := names[label]
+		output.Printf("\n🔗 Preparing connection profile: %s\n", name)
+		conn, err := prepareProfileConnection(cfgManager, prompt, name, profiles[name], credentials, opts)
+		if err != nil {
+			output.Printf("Error: profile '%s': %v\n", name, err)
+			os.Exit(1)
+		}
+		for _, target := range conn.Targets {
+			if other, used := usedPorts[target.LocalPort]; used {
+				output.Printf("Error: profiles '%s' and '%s' both forward to local port %s, give one of them another port\n", other, name, target.LocalPort)
+				os.Exit(1)
+			}
+			usedPorts[target.LocalPort] = name
+		}
+		connections = append(connections, conn)
+	}
+
+	if opts.DryRun {
+		for _, conn := range connections {
+			output.Printf("\n🔗 %s\n", conn.Name)
+			printDryRun(dryRunSummary{
+				AccountID:  conn.AccountID,
+				RoleName:   conn.RoleName,
+				Region:     conn.AWSConfig.Region,
+				InstanceID: conn.InstanceID,
+				Targets:    conn.Targets,
+				Options:    conn.Options,
+			})
+		}
+		return
+	}
+
+	if err := runProfileConnections(connections, opts.Tunnel); err != nil {
+		output.Printf("Error running SSM sessions: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// prepareProfileConnection signs in with a profile and resolves its targets. Nothing is prompted
+// for besides confirmations, so the profile must name its account, role, region, bastion and resources.
+func prepareProfileConnection(cfgManager *config.Manager, prompt *ui.Prompt, name string, profile config.ConnectionProfile, credentials map[string]profileCredentials, opts multiProfileOptions) (profileConnection, error) {
+	conn := profileConnection{Name: name, AccountID: profile.AccountID, RoleName: profile.RoleName, InstanceID: profile.BastionInstanceID}
+
+	specs := profile.Targets
+	if len(specs) == 0 && profile.ServiceType != "" {
+		port := profile.Port
+		if port == "" {
+			port = serviceDefaultPorts[profile.ServiceType]
+		}
+		// Custom endpoints have no usual port, default to the one they're forwarded to
+		if port == "" && profile.ServiceType == "custom" {
+			if _, remotePort, err := connect.ParseCustomEndpoint(profile.CustomEndpoint); err == nil {
+				port = strconv.Itoa(int(remotePort))
+			}
+		}
+		specs = []config.TargetSpec{{ServiceType: profile.ServiceType, Port: port, ResourceName: profile.ResourceName(profile.ServiceType)}}
+	}
+
+	required := []struct{ key, value string }{
+		{"region", profile.Region},
+		{"bastion_instance_id", profile.BastionInstanceID},
+	}
+	if profile.AWSProfile == "" {
+		required = append(required, []struct{ key, value string }{
+			{"sso_profile", profile.SSOProfile},
+			{"account_id", profile.AccountID},
+			{"role_name", profile.RoleName},
+		}...)
+	}
+	var missing []string
+	for _, field := range required {
+		if field.value == "" {
+			missing = append(missing, field.key)
+		}
+	}
+	if len(specs) == 0 {
+		missing = append(missing, "service")
+	}
+	if len(missing) > 0 {
+		return conn, fmt.Errorf("missing %s, complete it or connect to it on its own with 'bifrost connect --profile %s'", strings.Join(missing, ", "), name)
+	}
+
+	if profile.IsProduction() && !opts.Yes {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("⚠️ '%s' is a production (%s) profile. Connect anyway?", name, profile.Environment))
+		if err != nil || !confirmed {
+			return conn, fmt.Errorf("connection cancelled")
+		}
+	}
+
+	chain := connect.RoleChain{RoleARN: profile.AssumeRoleARN, ExternalID: profile.ExternalID}
+	key := strings.Join([]string{profile.AWSProfile, profile.SSOProfile, profile.AccountID, profile.RoleName, profile.Region, chain.RoleARN, chain.ExternalID}, "|")
+	creds, signedIn := credentials[key]
+	if !signedIn {
+		var err error
+		creds.RoleName = profile.RoleName
+		if profile.AWSProfile != "" {
+			creds.AWSConfig, creds.AccountID, err = getSharedProfileConfig(profile.AWSProfile, profile.Region, chain, opts.AWSTimeout)
+		} else {
+			creds.AWSConfig, creds.AccountID, creds.RoleName, err = getAWSConfig(profile.SSOProfile, profile.Region, profile.AccountID, profile.AccountName, profile.RoleName, opts.AuthTimeout, chain)
+		}
+		if err != nil {
+			return conn, err
+		}
+		credentials[key] = creds
+	}
+	awsCfg := creds.AWSConfig
+	conn.AWSConfig, conn.AccountID, conn.RoleName = awsCfg, creds.AccountID, creds.RoleName
+
+	targetAccountID := conn.AccountID
+	if chained := chain.AccountID(); chained != "" {
+		targetAccountID = chained
+	}
+	if !opts.Yes && targetAccountID != "" {
+		if err := confirmProtectedAccount(cfgManager, prompt, targetAccountID); err != nil {
+			return conn, fmt.Errorf("connection cancelled: %w", err)
+		}
+	}
+
+	conn.Options = opts.Tunnel
+	conn.Options.SSMDocument = profile.SSMDocument
+	conn.Options.SSMParameters = profile.SSMParameters
+	ctx, cancel := awsContext(opts.AWSTimeout)
+	err := connect.ValidateSSMDocument(ctx, awsCfg, conn.Options)
+	cancel()
+	if err != nil {
+		return conn, err
+	}
+
+	if !opts.NoPreflight {
+		services := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			services = append(services, spec.ServiceType)
+		}
+		ctx, cancel := awsContext(opts.AWSTimeout)
+		err := checkPermissions(ctx, awsCfg, profile.BastionInstanceID, services)
+		cancel()
+		if err != nil {
+			return conn, err
+		}
+	}
+
+	ctx, cancel = awsContext(opts.AWSTimeout)
+	targets, err := connect.ResolveTargetSpecs(ctx, awsCfg, promptChooser(prompt), specs, opts.EndpointType)
+	cancel()
+	if err != nil {
+		return conn, err
+	}
+	if !opts.DryRun {
+		if err := confirmTargetsAvailable(prompt, targets); err != nil {
+			return conn, err
+		}
+	}
+	conn.Targets = targets
+	return conn, nil
+}
+
+// runProfileConnections starts every profile's tunnels and keeps them up until Ctrl+C or until
+// any of them goes down, then stops them all
+func runProfileConnections(connections []profileConnection, opts connect.TunnelOptions) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchForShutdown(ctx, cancel)
+
+	type runningProfile struct {
+		name    string
+		started time.Time
+		session *connect.Session
+	}
+	var running []runningProfile
+	for _, conn := range connections {
+		conn.Options.Stdout = logging.Tee(os.Stdout)
+		conn.Options.Stderr = logging.Tee(os.Stderr)
+		session, err := connect.Start(ctx, conn.AWSConfig, conn.InstanceID, conn.Targets, conn.Options)
+		if err != nil {
+			cancel()
+			for _, r := range running {
+				_ = r.session.Wait()
+			}
+			return fmt.Errorf("profile '%s': %w", conn.Name, err)
+		}
+		running = append(running, runningProfile{name: conn.Name, started: time.Now(), session: session})
+	}
+
+	fmt.Println()
+	for _, conn := range connections {
+		for _, target := range conn.Targets {
+			output.Printf("🔌 %-16s %-10s %s → %s:%s\n", conn.Name, target.ServiceType, target.ResourceName, opts.ListenAddress(), target.LocalPort)
+			printClientHints(target)
+		}
+	}
+	output.Printf("📝 Press Ctrl+C to stop all connections\n\n")
+	if opts.KeepAlive {
+		output.Printf("💓 Keep alive enabled (interval: %v)\n", opts.KeepAliveInterval)
+	}
+	if opts.Reconnect {
+		output.Printf("🔁 Reconnect enabled (up to %d attempts)\n", opts.MaxReconnects)
+	}
+	printSessionLimits(opts)
+
+	// The first profile to go down takes the others with it
+	for _, r := range running {
+		go func(session *connect.Session) {
+			select {
+			case <-session.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}(r.session)
+	}
+
+	var failed error
+	for _, r := range running {
+		err := r.session.Wait()
+		recordSessionMetrics(r.started, r.session, err)
+		if err != nil && failed == nil {
+			failed = fmt.Errorf("profile '%s': %w", r.name, err)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// credentialProcessCmd represents the credential-process command
+var credentialProcessCmd = &cobra.Command{
+	Use:   "credential-process",
+	Short: "Act as an AWS CLI/SDK credential_process using cached SSO tokens",
+	Long: `Print role credentials in the JSON format expected by the AWS CLI and SDKs from a credential_process.
+It never prompts or opens a browser: it only uses the cached SSO token (refreshing it if possible)
+and fails if you need to run 'bifrost auth login' first.
+
+Add it to ~/.aws/config:
+  [profile dev]
+  credential_process = bifrost credential-process --sso-profile work --account-id 123456789012 --role-name PowerUserAccess`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ssoProfileFlag, _ := cmd.Flags().GetString("sso-profile")
+		accountIdFlag, _ := cmd.Flags().GetString("account-id")
+		roleNameFlag, _ := cmd.Flags().GetString("role-name")
+
+		cfg, err := config.NewManager().Load()
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ssoProfile, err := cfg.SSOProfile(ssoProfileFlag)
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx := context.Background()
+		ssoClient := connect.NewSSOClient(ssoProfile, 0, connect.TokenCacheOptions(cfg)...)
+
+		token, err := ssoClient.CachedToken(ctx)
+		if errors.Is(err, sso.ErrLoginRequired) {
+			output.Fprintf(os.Stderr, "Error: no valid SSO session for profile '%s', run 'bifrost auth login --profile %s'\n", ssoProfileFlag, ssoProfileFlag)
+			os.Exit(1)
+		}
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		roleCreds, err := ssoClient.GetRoleCredentials(ctx, token, accountIdFlag, roleNameFlag)
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error getting role credentials: %v\n", err)
+			os.Exit(1)
+		}
+
+		printJSON(credentialProcessOutput{
+			Version:         1,
+			AccessKeyID:     aws.ToString(roleCreds.RoleCredentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(roleCreds.RoleCredentials.SecretAccessKey),
+			SessionToken:    aws.ToString(roleCreds.RoleCredentials.SessionToken),
+			Expiration:      credentialExpiration(roleCreds.RoleCredentials),
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(credentialProcessCmd)
+
+	credentialProcessCmd.Flags().String("sso-profile", "", "SSO profile whose cached token is used")
+	credentialProcessCmd.Flags().StringP("account-id", "a", "", "AWS account ID")
+	credentialProcessCmd.Flags().StringP("role-name", "r", "", "AWS role name")
+	_ = credentialProcessCmd.MarkFlagRequired("sso-profile")
+	_ = credentialProcessCmd.MarkFlagRequired("account-id")
+	_ = credentialProcessCmd.MarkFlagRequired("role-name")
+
+	_ = credentialProcessCmd.RegisterFlagCompletionFunc("sso-profile", completeSSOProfiles)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// Supported values for the creds --format flag
+const (
+	credsFormatEnv     = "env"
+	credsFormatJSON    = "json"
+	credsFormatProfile = "profile"
+)
+
+var credsFormats = []string{credsFormatEnv, credsFormatJSON, credsFormatProfile}
+
+// credentialProcessOutput is the document the AWS CLI and SDKs expect from a credential_process
+type credentialProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// credsCmd represents the creds command
+var credsCmd = &cobra.Command{
+	Use:   "creds",
+	Short: "Print temporary role credentials instead of opening a tunnel",
+	Long: `Run the SSO flow and print temporary credentials for an account and role.
+Missing values are prompted for, just like 'bifrost connect'. Status messages and prompts go to stderr,
+so only the credentials are written to stdout.
+
+Formats:
+  env      export AWS_ACCESS_KEY_ID=... lines for eval
+  json     a credential_process document
+  profile  a block for ~/.aws/credentials
+
+Examples:
+  eval "$(bifrost creds --sso-profile work --account-id 123456789012 --role-name PowerUserAccess)"
+  bifrost creds --format json
+  bifrost creds --format profile --profile-name dev >> ~/.aws/credentials`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ssoProfileFlag, _ := cmd.Flags().GetString("sso-profile")
+		accountIdFlag, _ := cmd.Flags().GetString("account-id")
+		accountNameFlag, _ := cmd.Flags().GetString("account-name")
+		roleNameFlag, _ := cmd.Flags().GetString("role-name")
+		formatFlag, _ := cmd.Flags().GetString("format")
+		profileNameFlag, _ := cmd.Flags().GetString("profile-name")
+		authTimeout, _ := cmd.Flags().GetDuration("auth-timeout")
+
+		if !slices.Contains(credsFormats, formatFlag) {
+			output.Fprintf(os.Stderr, "Error: invalid format '%s'. Must be one of: %s\n", formatFlag, strings.Join(credsFormats, ", "))
+			os.Exit(1)
+		}
+
+		// Status messages and prompts normally go to stdout; keep stdout for the credentials only
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+
+		if ssoProfileFlag == "" {
+			ssoProfileFlag = selectSSOProfile(config.NewManager(), ui.NewPrompt())
+		}
+
+		roleCreds, _, _, err := getRoleCredentials(ssoProfileFlag, accountIdFlag, accountNameFlag, roleNameFlag, authTimeout)
+		os.Stdout = stdout
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		printCredentials(roleCreds, formatFlag, profileNameFlag)
+	},
+}
+
+// printCredentials writes role credentials to stdout in the requested format
+func printCredentials(roleCreds *ssotypes.RoleCredentials, format, profileName string) {
+	accessKeyID := aws.ToString(roleCreds.AccessKeyId)
+	secretAccessKey := aws.ToString(roleCreds.SecretAccessKey)
+	sessionToken := aws.ToString(roleCreds.SessionToken)
+
+	switch format {
+	case credsFormatEnv:
+		fmt.Printf("export AWS_ACCESS_KEY_ID=%s\n", accessKeyID)
+		fmt.Printf("export AWS_SECRET_ACCESS_KEY=%s\n", secretAccessKey)
+		fmt.Printf("export AWS_SESSION_TOKEN=%s\n", sessionToken)
+	case credsFormatJSON:
+		printJSON(credentialProcessOutput{
+			Version:         1,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+			Expiration:      credentialExpiration(roleCreds),
+		})
+	case credsFormatProfile:
+		fmt.Printf("[%s]\n", profileName)
+		fmt.Printf("aws_access_key_id = %s\n", accessKeyID)
+		fmt.Printf("aws_secret_access_key = %s\n", secretAccessKey)
+		fmt.Printf("aws_session_token = %s\n", sessionToken)
+	}
+}
+
+// credentialExpiration formats the role credential expiry (epoch milliseconds) as RFC 3339
+func credentialExpiration(roleCreds *ssotypes.RoleCredentials) string {
+	if roleCreds.Expiration == 0 {
+		return ""
+	}
+	return time.UnixMilli(roleCreds.Expiration).UTC().Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(credsCmd)
+
+	credsCmd.Flags().String("sso-profile", "", "SSO profile to use for authentication")
+	credsCmd.Flags().StringP("account-id", "a", "", "AWS account ID")
+	credsCmd.Flags().String("account-name", "", "AWS account name, looked up case-insensitively instead of passing --account-id")
+	credsCmd.Flags().StringP("role-name", "r", "", "AWS role name")
+	credsCmd.Flags().StringP("format", "f", credsFormatEnv, "Credential format (env, json or profile)")
+	credsCmd.Flags().String("profile-name", "bifrost", "Profile name used by the profile format")
+	credsCmd.Flags().Duration("auth-timeout", sso.DefaultAuthTimeout, "How long to wait for the SSO login to be approved in the browser")
+
+	_ = credsCmd.RegisterFlagCompletionFunc("sso-profile", completeSSOProfiles)
+	_ = credsCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(credsFormats, cobra.ShellCompDirectiveNoFileComp))
+}
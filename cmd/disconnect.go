@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/b3nk3/bifrost/internal/process"
+	"github.com/b3nk3/bifrost/internal/session"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// disconnectCmd represents the disconnect command
+var disconnectCmd = &cobra.Command{
+	Use:   "disconnect",
+	Short: "Stop tunnels started with 'bifrost connect --background'",
+	Long: `Stop tunnels started with 'bifrost connect --background'. If no port is specified, you'll be prompted to select one.
+
+Examples:
+  bifrost disconnect --port 5432
+  bifrost disconnect --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		prompt := ui.NewPrompt()
+
+		portFlag, _ := cmd.Flags().GetString("port")
+		allFlag, _ := cmd.Flags().GetBool("all")
+
+		sessions, err := session.LoadSessions()
+		if err != nil {
+			output.Printf("Error loading sessions: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No background sessions found.")
+			return
+		}
+
+		var targets []session.Session
+		switch {
+		case allFlag:
+			targets = sessions
+		case portFlag != "":
+			for _, s := range sessions {
+				if s.LocalPort == portFlag {
+					targets = append(targets, s)
+				}
+			}
+			if len(targets) == 0 {
+				fmt.Printf("No background session found on port %s\n", portFlag)
+				os.Exit(1)
+			}
+		case len(sessions) == 1:
+			targets = sessions
+		default:
+			options := make([]string, 0, len(sessions))
+			sessionMap := make(map[string]session.Session)
+			for _, s := range sessions {
+				display := fmt.Sprintf("127.0.0.1:%s → %s (%s)", s.LocalPort, s.Endpoint, s.ServiceType)
+				options = append(options, display)
+				sessionMap[display] = s
+			}
+
+			selected, err := prompt.Select("Select session to disconnect", options)
+			if err != nil {
+				output.Printf("Error selecting session: %v\n", err)
+				os.Exit(1)
+			}
+			targets = []session.Session{sessionMap[selected]}
+		}
+
+		for _, s := range targets {
+			if s.IsRunning() {
+				p, err := os.FindProcess(s.PID)
+				if err == nil {
+					err = process.Terminate(p)
+				}
+				if err != nil {
+					output.Printf("❌ Failed to stop session on port %s (PID %d): %v\n", s.LocalPort, s.PID, err)
+					continue
+				}
+				output.Printf("🛑 Stopped session on port %s (PID %d)\n", s.LocalPort, s.PID)
+			} else {
+				output.Printf("🧹 Session on port %s (PID %d) was no longer running\n", s.LocalPort, s.PID)
+			}
+
+			if err := session.RemoveSession(s.PID); err != nil {
+				output.Printf("⚠️ Warning: failed to update session registry: %v\n", err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disconnectCmd)
+
+	disconnectCmd.Flags().StringP("port", "p", "", "Local port of the session to stop")
+	disconnectCmd.Flags().Bool("all", false, "Stop all background sessions")
+}
@@ -0,0 +1,255 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of a doctor check
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+var doctorIcons = map[string]string{
+	doctorPass: "✅",
+	doctorWarn: "⚠️",
+	doctorFail: "❌",
+}
+
+// doctorReachabilityURL is a global AWS endpoint that answers without credentials
+const doctorReachabilityURL = "https://sts.amazonaws.com"
+
+// doctorMaxClockSkew is how far the local clock may drift from AWS before signed requests and SSO tokens fail
+const doctorMaxClockSkew = 5 * time.Minute
+
+// doctorCheck is one line of the doctor report
+type doctorCheck struct {
+	Status string
+	Name   string
+	Detail string
+	Hint   string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check bifrost's environment for common problems",
+	Long: `Check the tools, configuration, SSO sessions and network bifrost depends on and print a report.
+
+Checks:
+  - AWS CLI and Session Manager plugin are installed
+  - Global and local config files can be read and parsed
+  - Cached SSO tokens exist and haven't expired
+  - AWS is reachable and the local clock agrees with it
+
+Exits with status 1 if any check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var checks []doctorCheck
+		checks = append(checks, checkTools()...)
+		configChecks, cfg := checkConfig()
+		checks = append(checks, configChecks...)
+		if cfg != nil {
+			checks = append(checks, checkSSOTokens(cfg)...)
+		}
+		checks = append(checks, checkNetwork()...)
+
+		output.Println("🩺 Bifrost doctor")
+		fmt.Println()
+		counts := make(map[string]int)
+		for _, check := range checks {
+			counts[check.Status]++
+			fmt.Printf("%s %s: %s\n", output.Icon(doctorIcons[check.Status]), check.Name, check.Detail)
+			if check.Hint != "" {
+				output.Printf("   💡 %s\n", check.Hint)
+			}
+		}
+
+		fmt.Println()
+		fmt.Printf("%d passed, %d warnings, %d failed\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+		if counts[doctorFail] > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// checkTools reports the AWS CLI and Session Manager plugin versions
+func checkTools() []doctorCheck {
+	tools := connect.Prerequisites()
+	checks := make([]doctorCheck, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Err != nil {
+			checks = append(checks, doctorCheck{Status: doctorFail, Name: tool.Name, Detail: tool.Err.Error(), Hint: tool.Hint})
+			continue
+		}
+		checks = append(checks, doctorCheck{Status: doctorPass, Name: tool.Name, Detail: tool.Version})
+	}
+	return checks
+}
+
+// checkConfig reads the global and local config, returning the merged config if both parse
+func checkConfig() ([]doctorCheck, *config.Config) {
+	cfgManager := config.NewManager()
+	var checks []doctorCheck
+
+	globalCfg, err := cfgManager.LoadGlobal()
+	if err != nil {
+		checks = append(checks, doctorCheck{Status: doctorFail, Name: "Global config", Detail: err.Error(), Hint: "Fix or remove ~/.bifrost/config.yaml"})
+		return checks, nil
+	}
+	checks = append(checks, doctorCheck{
+		Status: doctorPass,
+		Name:   "Global config",
+		Detail: fmt.Sprintf("%d SSO profile(s), %d connection profile(s)", len(globalCfg.SSOProfiles), len(globalCfg.ConnectionProfiles)),
+	})
+
+	localPath := config.LocalConfigPath()
+	if abs, err := filepath.Abs(localPath); err == nil {
+		localPath = abs
+	}
+	_, statErr := os.Stat(localPath)
+
+	cfg, err := cfgManager.Load()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{Status: doctorFail, Name: "Local config", Detail: err.Error(), Hint: "Fix or remove " + localPath})
+		return checks, nil
+	case os.IsNotExist(statErr):
+		checks = append(checks, doctorCheck{Status: doctorPass, Name: "Local config", Detail: "none in this directory or its parents"})
+	default:
+		checks = append(checks, doctorCheck{
+			Status: doctorPass,
+			Name:   "Local config",
+			Detail: fmt.Sprintf("%s, %d connection profile(s) in total", localPath, len(cfg.ConnectionProfiles)),
+		})
+	}
+
+	for _, name := range sortedKeys(cfg.ConnectionProfiles) {
+		profile, err := cfg.ConnectionProfiles[name].Expand()
+		if err != nil {
+			checks = append(checks, doctorCheck{
+				Status: doctorWarn,
+				Name:   fmt.Sprintf("Profile '%s'", name),
+				Detail: err.Error(),
+				Hint:   "Set the environment variable before connecting with this profile",
+			})
+			continue
+		}
+		ssoProfile := profile.SSOProfile
+		if _, exists := cfg.SSOProfiles[ssoProfile]; ssoProfile != "" && !exists {
+			checks = append(checks, doctorCheck{
+				Status: doctorWarn,
+				Name:   fmt.Sprintf("Profile '%s'", name),
+				Detail: fmt.Sprintf("uses SSO profile '%s', which doesn't exist", ssoProfile),
+				Hint:   "Create it with 'bifrost auth configure' or point the profile at an existing SSO profile",
+			})
+		}
+	}
+
+	return checks, cfg
+}
+
+// checkSSOTokens reports whether each SSO profile has a cached token and when it expires
+func checkSSOTokens(cfg *config.Config) []doctorCheck {
+	if len(cfg.SSOProfiles) == 0 {
+		return []doctorCheck{{Status: doctorWarn, Name: "SSO profiles", Detail: "none configured", Hint: "Create one with 'bifrost auth configure'"}}
+	}
+
+	names := make([]string, 0, len(cfg.SSOProfiles))
+	for name := range cfg.SSOProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]doctorCheck, 0, len(names))
+	for _, name := range names {
+		check := doctorCheck{Name: fmt.Sprintf("SSO token '%s'", name)}
+		token, err := sso.LoadTokenCache(cfg.SSOProfiles[name].StartURL)
+		switch {
+		case err != nil:
+			check.Status, check.Detail = doctorWarn, fmt.Sprintf("failed to read cached token: %v", err)
+			check.Hint = fmt.Sprintf("Sign in again with 'bifrost auth login --profile %s'", name)
+		case token == nil:
+			check.Status, check.Detail = doctorWarn, "not logged in"
+			check.Hint = fmt.Sprintf("Sign in with 'bifrost auth login --profile %s'", name)
+		case time.Now().Before(token.ExpiresAt):
+			check.Status = doctorPass
+			check.Detail = fmt.Sprintf("valid until %s", token.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+		case token.Refreshable():
+			check.Status = doctorPass
+			check.Detail = fmt.Sprintf("expired %s, will refresh on next use", token.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+		default:
+			check.Status = doctorWarn
+			check.Detail = fmt.Sprintf("expired %s", token.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+			check.Hint = fmt.Sprintf("Sign in again with 'bifrost auth login --profile %s'", name)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkNetwork reaches a global AWS endpoint and compares its clock with the local one
+func checkNetwork() []doctorCheck {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		// Any answer proves AWS is reachable, there's no need to follow it
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	started := time.Now()
+	resp, err := client.Head(doctorReachabilityURL)
+	if err != nil {
+		return []doctorCheck{{
+			Status: doctorFail,
+			Name:   "Network",
+			Detail: fmt.Sprintf("can't reach %s: %v", doctorReachabilityURL, err),
+			Hint:   "Check your internet connection, VPN and HTTPS_PROXY settings",
+		}}
+	}
+	_ = resp.Body.Close()
+	elapsed := time.Since(started)
+
+	checks := []doctorCheck{{
+		Status: doctorPass,
+		Name:   "Network",
+		Detail: fmt.Sprintf("reached %s in %s", doctorReachabilityURL, elapsed.Round(time.Millisecond)),
+	}}
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return append(checks, doctorCheck{Status: doctorWarn, Name: "Clock", Detail: "AWS didn't send its time, skipped the clock check"})
+	}
+
+	// Compare against the middle of the round trip, the Date header only has second precision anyway
+	skew := started.Add(elapsed / 2).Sub(serverTime)
+	clock := doctorCheck{Status: doctorPass, Name: "Clock", Detail: fmt.Sprintf("%s off AWS", skew.Abs().Round(time.Second))}
+	if skew.Abs() > doctorMaxClockSkew {
+		direction := "ahead of"
+		if skew < 0 {
+			direction = "behind"
+		}
+		clock.Status = doctorFail
+		clock.Detail = fmt.Sprintf("%s %s AWS", skew.Abs().Round(time.Second), direction)
+		clock.Hint = "Sync your system clock, AWS rejects signed requests and SSO tokens from clocks more than 5 minutes off"
+	}
+	return append(checks, clock)
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// dryRunSummary is everything connect resolved, printed by --dry-run instead of opening the tunnel
+type dryRunSummary struct {
+	AccountID  string
+	RoleName   string
+	Region     string
+	InstanceID string
+	Targets    []connect.Target
+	Options    connect.TunnelOptions
+	Command    string
+}
+
+// sessionParams is what --json-params prints: the StartSession request of each tunnel, for tools
+// that start the sessions and run session-manager-plugin themselves
+type sessionParams struct {
+	AccountID string          `json:"account_id"`
+	RoleName  string          `json:"role_name,omitempty"`
+	Region    string          `json:"region"`
+	Sessions  []targetSession `json:"sessions"`
+
+	// SensitiveCredentials are the temporary role credentials, only included with --include-creds
+	SensitiveCredentials *credentialProcessOutput `json:"sensitive_credentials,omitempty"`
+}
+
+type targetSession struct {
+	Service      string                      `json:"service"`
+	Resource     string                      `json:"resource,omitempty"`
+	LocalAddress string                      `json:"local_address"`
+	StartSession connect.StartSessionRequest `json:"start_session"`
+}
+
+// printSessionParams writes the StartSession requests of the summary's tunnels to w as JSON,
+// with the credentials of cfg when includeCreds is set
+func printSessionParams(w io.Writer, summary dryRunSummary, cfg aws.Config, includeCreds bool) {
+	params := sessionParams{
+		AccountID: summary.AccountID,
+		RoleName:  summary.RoleName,
+		Region:    summary.Region,
+		Sessions:  make([]targetSession, 0, len(summary.Targets)),
+	}
+	for _, target := range summary.Targets {
+		params.Sessions = append(params.Sessions, targetSession{
+			Service:      target.ServiceType,
+			Resource:     target.ResourceName,
+			LocalAddress: net.JoinHostPort(summary.Options.ListenAddress(), target.LocalPort),
+			StartSession: connect.NewStartSessionRequest(summary.InstanceID, target.Endpoint, target.Port, target.LocalPort, summary.Options),
+		})
+	}
+
+	if includeCreds {
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		if err != nil {
+			output.Fprintf(os.Stderr, "Error: failed to get credentials: %v\n", err)
+			os.Exit(1)
+		}
+		params.SensitiveCredentials = &credentialProcessOutput{
+			Version:         1,
+			AccessKeyID:     creds.AccessKeyID,
+			SecretAccessKey: creds.SecretAccessKey,
+			SessionToken:    creds.SessionToken,
+		}
+		if creds.CanExpire {
+			params.SensitiveCredentials.Expiration = creds.Expires.UTC().Format(time.RFC3339)
+		}
+	}
+
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		output.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
+
+// connectResult is what connect --output json prints: the resolved connection, once the tunnel is ready
+// or instead of opening it with --dry-run. The service, endpoint and ports are the first tunnel's,
+// Targets lists every tunnel when there are several.
+type connectResult struct {
+	AccountID         string         `json:"account_id"`
+	RoleName          string         `json:"role_name,omitempty"`
+	Region            string         `json:"region"`
+	Service           string         `json:"service"`
+	Endpoint          string         `json:"endpoint"`
+	RemotePort        int32          `json:"remote_port"`
+	LocalPort         string         `json:"local_port"`
+	BastionInstanceID string         `json:"bastion_instance_id"`
+	DryRun            bool           `json:"dry_run,omitempty"`
+	Targets           []resultTarget `json:"targets,omitempty"`
+}
+
+type resultTarget struct {
+	Service    string `json:"service"`
+	Resource   string `json:"resource,omitempty"`
+	Endpoint   string `json:"endpoint"`
+	RemotePort int32  `json:"remote_port"`
+	LocalPort  string `json:"local_port"`
+}
+
+// printConnectResult writes the summary's connection to w as JSON, dryRun telling no tunnel was opened
+func printConnectResult(w io.Writer, summary dryRunSummary, dryRun bool) {
+	first := summary.Targets[0]
+	result := connectResult{
+		AccountID:         summary.AccountID,
+		RoleName:          summary.RoleName,
+		Region:            summary.Region,
+		Service:           first.ServiceType,
+		Endpoint:          first.Endpoint,
+		RemotePort:        first.Port,
+		LocalPort:         first.LocalPort,
+		BastionInstanceID: summary.InstanceID,
+		DryRun:            dryRun,
+	}
+	if len(summary.Targets) > 1 {
+		for _, target := range summary.Targets {
+			result.Targets = append(result.Targets, resultTarget{
+				Service:    target.ServiceType,
+				Resource:   target.ResourceName,
+				Endpoint:   target.Endpoint,
+				RemotePort: target.Port,
+				LocalPort:  target.LocalPort,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		output.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
+
+// resultPrinter returns the TunnelOptions.OnReady hook printing the connection result to w, after
+// what the next hook prints
+func resultPrinter(w io.Writer, summary dryRunSummary, next func()) func() {
+	return func() {
+		if next != nil {
+			next()
+		}
+		printConnectResult(w, summary, false)
+	}
+}
+
+// printDryRun prints the resolved connection and the exact AWS CLI command for each tunnel
+func printDryRun(summary dryRunSummary) {
+	fmt.Println()
+	output.Println("🧪 Dry run, no tunnel opened")
+	fmt.Printf("   Account: %s\n", summary.AccountID)
+	fmt.Printf("   Role: %s\n", summary.RoleName)
+	fmt.Printf("   Region: %s\n", summary.Region)
+	fmt.Printf("   Bastion: %s\n", summary.InstanceID)
+	fmt.Printf("   SSM Document: %s\n", summary.Options.Document())
+	if summary.Options.MaxDuration > 0 {
+		fmt.Printf("   Max duration: %v\n", summary.Options.MaxDuration)
+	}
+	if summary.Options.IdleTimeout > 0 {
+		fmt.Printf("   Idle timeout: %v (the plugin listens on a free loopback port, relayed from the local port)\n", summary.Options.IdleTimeout)
+	}
+
+	for _, target := range summary.Targets {
+		fmt.Println()
+		fmt.Printf("   %s %s\n", target.ServiceType, target.ResourceName)
+		fmt.Printf("     Endpoint: %s:%d\n", target.Endpoint, target.Port)
+		fmt.Printf("     Local: %s:%s\n", summary.Options.ListenAddress(), target.LocalPort)
+		fmt.Printf("     Parameters: %s\n", connect.RenderSSMParameters(summary.Options.ParameterTemplate(), target.Endpoint, target.Port, target.LocalPort))
+
+		args := connect.SSMSessionArgs(summary.InstanceID, target.Endpoint, target.Port, target.LocalPort, summary.Region, summary.Options)
+		fmt.Printf("     Command: aws %s\n", shellQuoteArgs(args))
+	}
+
+	if summary.Command != "" {
+		fmt.Println()
+		fmt.Printf("   Client command: %s\n", renderClientCommand(summary.Command, summary.Options.ListenAddress(), summary.Targets[0].LocalPort))
+	}
+}
+
+// shellQuoteArgs joins arguments for display, single quoting any that a shell would split or expand
+func shellQuoteArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`{}[]*?;&|<>()#~!") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
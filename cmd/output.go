@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// Supported values for the --output flag
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// validateOutputFormat checks the --output flag holds a supported value
+func validateOutputFormat(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("output")
+	if format != outputTable && format != outputJSON {
+		return fmt.Errorf("invalid output format '%s' (must be '%s' or '%s')", format, outputTable, outputJSON)
+	}
+	return nil
+}
+
+// isJSONOutput reports whether the command should print machine-readable JSON
+func isJSONOutput(cmd *cobra.Command) bool {
+	format, _ := cmd.Flags().GetString("output")
+	return format == outputJSON
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) {
+	writeJSON(os.Stdout, v)
+}
+
+// writeJSON writes v to w as indented JSON
+func writeJSON(w io.Writer, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		output.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
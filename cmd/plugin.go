@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// pluginInstallTimeout bounds downloading the Session Manager plugin
+const pluginInstallTimeout = 5 * time.Minute
+
+// ensureSessionManagerPlugin explains how to install the Session Manager plugin when it is missing and,
+// in a terminal, offers to download it into ~/.bifrost/bin. It exits when the plugin stays missing.
+func ensureSessionManagerPlugin(prompt *ui.Prompt) {
+	if connect.PluginInstalled() {
+		return
+	}
+
+	output.Println("⚠️ The Session Manager plugin is not installed, the AWS CLI needs it to start port forwarding sessions")
+	output.Printf("💡 To install it for %s/%s:\n", runtime.GOOS, runtime.GOARCH)
+	output.Printf("   %s\n", connect.PluginInstallCommand())
+
+	if !prompt.Interactive() {
+		output.Println("Error: missing prerequisite session-manager-plugin, install it and try again")
+		os.Exit(1)
+	}
+	if !connect.CanInstallPlugin() {
+		output.Printf("Error: bifrost can't install the plugin on %s/%s itself, run the command above and try again\n", runtime.GOOS, runtime.GOARCH)
+		os.Exit(1)
+	}
+
+	dir, err := connect.PluginDir()
+	if err != nil {
+		output.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	confirmed, err := prompt.Confirm(fmt.Sprintf("Download the plugin from AWS and install it into %s instead?", dir))
+	if err != nil || !confirmed {
+		os.Exit(1)
+	}
+
+	var caBundle string
+	if globalCfg, err := config.NewManager().LoadGlobal(); err == nil {
+		caBundle = globalCfg.CABundle
+	}
+	client, err := sso.NewHTTPClient(sso.CABundle(caBundle), pluginInstallTimeout)
+	if err != nil {
+		output.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var path, signer string
+	err = ui.WithSpinner("Downloading the Session Manager plugin…", func() (err error) {
+		path, signer, err = connect.InstallPlugin(ctx, client)
+		return err
+	})
+	if err != nil {
+		output.Printf("Error installing the Session Manager plugin: %v\n", err)
+		os.Exit(1)
+	}
+	output.Printf("🔏 Verified the plugin's signature: %s\n", signer)
+	output.Printf("✅ Installed the Session Manager plugin to %s\n", path)
+}
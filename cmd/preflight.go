@@ -0,0 +1,132 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// PermissionError reports an IAM action the role was denied during the preflight probe
+type PermissionError struct {
+	Action string
+	Err    error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("the selected role is not allowed to call %s: %v", e.Action, e.Err)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// permissionProbe makes the cheapest call that needs an IAM action, so a denial names the action
+type permissionProbe struct {
+	Action string
+	Call   func(ctx context.Context, cfg aws.Config) error
+}
+
+// servicePermissionProbes are the describe calls bifrost makes to find a resource's endpoint
+var servicePermissionProbes = map[string]permissionProbe{
+	"rds": {"rds:DescribeDBInstances", func(ctx context.Context, cfg aws.Config) error {
+		_, err := rds.NewFromConfig(cfg).DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{MaxRecords: aws.Int32(20)})
+		return err
+	}},
+	"redis": {"elasticache:DescribeReplicationGroups", func(ctx context.Context, cfg aws.Config) error {
+		_, err := elasticache.NewFromConfig(cfg).DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{MaxRecords: aws.Int32(20)})
+		return err
+	}},
+	"documentdb": {"rds:DescribeDBClusters", func(ctx context.Context, cfg aws.Config) error {
+		_, err := docdb.NewFromConfig(cfg).DescribeDBClusters(ctx, &docdb.DescribeDBClustersInput{MaxRecords: aws.Int32(20)})
+		return err
+	}},
+	"neptune": {"rds:DescribeDBClusters", func(ctx context.Context, cfg aws.Config) error {
+		_, err := neptune.NewFromConfig(cfg).DescribeDBClusters(ctx, &neptune.DescribeDBClustersInput{MaxRecords: aws.Int32(20)})
+		return err
+	}},
+	"opensearch": {"es:ListDomainNames", func(ctx context.Context, cfg aws.Config) error {
+		_, err := opensearch.NewFromConfig(cfg).ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
+		return err
+	}},
+	"kafka": {"kafka:ListClustersV2", func(ctx context.Context, cfg aws.Config) error {
+		_, err := kafka.NewFromConfig(cfg).ListClustersV2(ctx, &kafka.ListClustersV2Input{MaxResults: aws.Int32(10)})
+		return err
+	}},
+}
+
+// checkPermissions probes that the role can see the bastion in SSM and describe each service's resources.
+// ssm:StartSession has no dry run, so it is only exercised by the session itself.
+func checkPermissions(ctx context.Context, cfg aws.Config, instanceID string, serviceTypes []string) error {
+
+	info, err := describeBastion(ctx, cfg, instanceID)
+	if err != nil {
+		return permissionError("ssm:DescribeInstanceInformation", connect.AWSError(ctx, err))
+	}
+	if info == nil {
+		output.Printf("⚠️ Bastion %s is not registered with SSM in %s, the session will likely fail\n", instanceID, cfg.Region)
+	} else if info.PingStatus != ssmtypes.PingStatusOnline {
+		output.Printf("⚠️ Bastion %s SSM agent status is %s, the session will likely fail\n", instanceID, info.PingStatus)
+	} else if version := aws.ToString(info.AgentVersion); connect.AgentOutdated(version) {
+		output.Printf("⚠️ Bastion %s runs SSM agent %s, port forwarding needs %s or later, the session will likely fail\n", instanceID, version, connect.MinAgentVersion)
+	}
+
+	checked := make(map[string]bool)
+	for _, serviceType := range serviceTypes {
+		probe, ok := servicePermissionProbes[serviceType]
+		if !ok || checked[serviceType] {
+			continue
+		}
+		checked[serviceType] = true
+
+		if err := probe.Call(ctx, cfg); err != nil {
+			return permissionError(probe.Action, connect.AWSError(ctx, err))
+		}
+	}
+
+	output.Println("✅ Preflight passed: bastion visible in SSM and resources can be described")
+	return nil
+}
+
+// describeBastion returns the bastion's SSM registration, or nil if it isn't registered in the region
+func describeBastion(ctx context.Context, cfg aws.Config, instanceID string) (*ssmtypes.InstanceInformation, error) {
+	result, err := ssm.NewFromConfig(cfg).DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
+		Filters: []ssmtypes.InstanceInformationStringFilter{
+			{Key: aws.String("InstanceIds"), Values: []string{instanceID}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.InstanceInformationList) == 0 {
+		return nil, nil
+	}
+	return &result.InstanceInformationList[0], nil
+}
+
+// permissionError wraps access denied failures in a PermissionError naming the action
+func permissionError(action string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+			return &PermissionError{Action: action, Err: err}
+		}
+	}
+	return fmt.Errorf("preflight %s failed: %w", action, err)
+}
@@ -4,12 +4,18 @@ Copyright © 2025 Ben Szabo me@benszabo.co.uk
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
 	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -36,18 +42,21 @@ Examples:
 
 		profileName, _ := cmd.Flags().GetString("name")
 		ssoProfile, _ := cmd.Flags().GetString("sso-profile")
+		awsProfile, _ := cmd.Flags().GetString("aws-profile")
 		accountID, _ := cmd.Flags().GetString("account-id")
+		accountName, _ := cmd.Flags().GetString("account-name")
 		roleName, _ := cmd.Flags().GetString("role-name")
 		region, _ := cmd.Flags().GetString("region")
 		serviceType, _ := cmd.Flags().GetString("service")
 		port, _ := cmd.Flags().GetString("port")
 		bastionInstanceID, _ := cmd.Flags().GetString("bastion-id")
+		environment, _ := cmd.Flags().GetString("env")
 		global, _ := cmd.Flags().GetBool("global")
 
 		// Load config to check available SSO profiles
 		cfg, err := cfgManager.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			output.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -55,14 +64,14 @@ Examples:
 		if profileName == "" {
 			result, err := prompt.Input("Connection profile name", nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			profileName = result
 		}
 
-		// Prompt for SSO profile if not provided
-		if ssoProfile == "" {
+		// Prompt for SSO profile if not provided, an AWS profile brings its own credentials
+		if ssoProfile == "" && awsProfile == "" {
 			if len(cfg.SSOProfiles) == 0 {
 				fmt.Println("No SSO profiles found. Please create one with 'bifrost auth configure'")
 				os.Exit(1)
@@ -71,16 +80,11 @@ Examples:
 			// Try to get default SSO profile (if only one exists)
 			if defaultProfile, err := cfgManager.GetDefaultSSOProfile(); err == nil && defaultProfile != "" {
 				ssoProfile = defaultProfile
-				fmt.Printf("🔐 Using SSO profile: %s\n", ssoProfile)
+				output.Printf("🔐 Using SSO profile: %s\n", ssoProfile)
 			} else {
-				profileNames := make([]string, 0, len(cfg.SSOProfiles))
-				for name := range cfg.SSOProfiles {
-					profileNames = append(profileNames, name)
-				}
-
-				selected, err := prompt.Select("Select SSO profile", profileNames)
+				selected, err := promptSSOProfile(prompt, "Select SSO profile", cfg.SSOProfiles)
 				if err != nil {
-					fmt.Printf("Error selecting profile: %v\n", err)
+					output.Printf("Error selecting profile: %v\n", err)
 					os.Exit(1)
 				}
 				ssoProfile = selected
@@ -88,19 +92,19 @@ Examples:
 		}
 
 		// Validate SSO profile exists
-		if _, exists := cfg.SSOProfiles[ssoProfile]; !exists {
+		if _, exists := cfg.SSOProfiles[ssoProfile]; awsProfile == "" && !exists {
 			fmt.Printf("SSO profile '%s' not found. Available profiles:\n", ssoProfile)
 			for name := range cfg.SSOProfiles {
-				fmt.Printf("  • %s\n", name)
+				output.Printf("  • %s\n", name)
 			}
 			os.Exit(1)
 		}
 
 		// Prompt for region if not provided
 		if region == "" {
-			result, err := prompt.Input("AWS region (where your RDS/Redis instances are)", nil)
+			result, err := selectRegion(context.Background(), prompt, nil, "", 0)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			region = result
@@ -109,43 +113,47 @@ Examples:
 
 		// Prompt for service type if not provided
 		if serviceType == "" {
-			result, err := prompt.Select("Select service type", []string{"rds", "redis"})
+			result, err := prompt.Select("Select service type", connect.ServiceTypes)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			serviceType = result
+		} else if !slices.Contains(connect.ServiceTypes, serviceType) {
+			fmt.Printf("Invalid service type. Please choose one of: %s.\n", strings.Join(connect.ServiceTypes, ", "))
+			os.Exit(1)
 		}
 
 		// Prompt for account ID if not provided
-		if accountID == "" {
+		if accountID == "" && accountName == "" && awsProfile == "" {
 			result, err := prompt.Input("AWS Account ID", nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			accountID = result
 		}
 
 		// Prompt for role name if not provided
-		if roleName == "" {
+		if roleName == "" && awsProfile == "" {
 			result, err := prompt.Input("AWS Role Name (e.g., PowerUserAccess)", nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			roleName = result
 		}
 
-		// Prompt for port if not provided
+		// Prompt for port if not provided, custom services default to the remote port when connecting
 		if port == "" {
-			defaultPort := "3306" // MySQL default
-			if serviceType == "redis" {
-				defaultPort = "6379"
+			defaultPort := serviceDefaultPorts[serviceType]
+			label := fmt.Sprintf("Local port (default: %s)", defaultPort)
+			if defaultPort == "" {
+				label = "Local port (optional - leave empty to use the remote port)"
 			}
-			result, err := prompt.Input(fmt.Sprintf("Local port (default: %s)", defaultPort), nil)
+			result, err := prompt.Input(label, nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			if result == "" {
@@ -156,62 +164,69 @@ Examples:
 
 		// Prompt for bastion instance ID if not provided
 		if bastionInstanceID == "" {
-			result, err := prompt.Input("Bastion Instance ID (optional - leave empty to browse during connection)", nil)
+			result, err := prompt.Input("Bastion Instance ID (optional - leave empty to browse during connection)", validateOptionalInstanceID)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			bastionInstanceID = result
+		} else if err := connect.ValidateInstanceID(bastionInstanceID); err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Prompt for RDS/Redis resource names based on service type
-		var rdsInstanceName, redisClusterName string
-		switch serviceType {
-		case "rds":
-			result, err := prompt.Input("RDS DB Instance Name (optional - leave empty to browse during connection)", nil)
+		// Prompt for the resource name based on service type, Keyspaces always uses the regional endpoint
+		var resourceName string
+		if serviceType == "custom" {
+			result, err := prompt.Input("Host and port to forward to (e.g. api.internal:8080)", func(input string) error {
+				_, _, err := connect.ParseCustomEndpoint(input)
+				return err
+			})
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-			rdsInstanceName = result
-		case "redis":
-			result, err := prompt.Input("Redis Cluster Name (optional - leave empty to browse during connection)", nil)
+			resourceName = result
+		} else if serviceType != "keyspaces" {
+			result, err := prompt.Input(fmt.Sprintf("%s name (optional - leave empty to browse during connection)", serviceResourceLabels[serviceType]), nil)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				output.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-			redisClusterName = result
+			resourceName = result
 		}
 
 		// Create connection profile
 		connectionProfile := config.ConnectionProfile{
+			AWSProfile:        awsProfile,
 			SSOProfile:        ssoProfile,
 			AccountID:         accountID,
+			AccountName:       accountName,
 			RoleName:          roleName,
 			Region:            region,
+			Environment:       environment,
 			ServiceType:       serviceType,
 			Port:              port,
 			BastionInstanceID: bastionInstanceID,
-			RDSInstanceName:   rdsInstanceName,
-			RedisClusterName:  redisClusterName,
 		}
+		connectionProfile.SetResourceName(serviceType, resourceName)
 
 		// Save the profile (local by default, global if specified)
 		var saveErr error
 		if global {
 			saveErr = cfgManager.AddConnectionProfile(profileName, connectionProfile)
 			if saveErr == nil {
-				fmt.Printf("✅ Connection profile '%s' saved to global config\n", profileName)
+				output.Printf("✅ Connection profile '%s' saved to global config\n", profileName)
 			}
 		} else {
 			saveErr = cfgManager.AddLocalConnectionProfile(profileName, connectionProfile)
 			if saveErr == nil {
-				fmt.Printf("✅ Connection profile '%s' saved to local config (.bifrost.config.yaml)\n", profileName)
+				output.Printf("✅ Connection profile '%s' saved to local config (%s)\n", profileName, config.LocalConfigPath())
 			}
 		}
 
 		if saveErr != nil {
-			fmt.Printf("Error saving connection profile: %v\n", saveErr)
+			output.Printf("Error saving connection profile: %v\n", saveErr)
 			os.Exit(1)
 		}
 
@@ -222,29 +237,103 @@ Examples:
 var profileListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all connection profiles",
-	Long:  `List all configured connection profiles (both global and local).`,
+	Long: `List all configured connection profiles (both global and local) and where each one lives.
+A local profile replaces a global one of the same name, which is flagged in the list.
+
+Examples:
+  bifrost profile list
+  bifrost profile list --location local
+  bifrost profile list --env prd --output json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfgManager := config.NewManager()
+		envFlag, _ := cmd.Flags().GetString("env")
+		locationFlag, _ := cmd.Flags().GetString("location")
+
+		if locationFlag != "" && locationFlag != config.LocationLocal && locationFlag != config.LocationGlobal {
+			fmt.Printf("Invalid location '%s'. Must be %s or %s.\n", locationFlag, config.LocationLocal, config.LocationGlobal)
+			os.Exit(1)
+		}
+
+		// Load merges both files; read them separately too to tell where each profile lives
 		cfg, err := cfgManager.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			output.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		globalProfiles, localProfiles, err := cfgManager.ConnectionProfilesByLocation()
+		if err != nil {
+			output.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		if len(cfg.ConnectionProfiles) == 0 {
+		all := cfg.ConnectionProfiles
+		switch locationFlag {
+		case config.LocationLocal:
+			all = localProfiles
+		case config.LocationGlobal:
+			all = globalProfiles
+		}
+		profiles := filterProfilesByEnvironment(all, envFlag)
+
+		// locationOf is where the listed profile called name comes from, shadowed whether a local
+		// and a global profile share the name
+		locationOf := func(name string) (location string, shadowed bool) {
+			_, inLocal := localProfiles[name]
+			_, inGlobal := globalProfiles[name]
+			location = config.LocationGlobal
+			if locationFlag == config.LocationLocal || (locationFlag == "" && inLocal) {
+				location = config.LocationLocal
+			}
+			return location, inLocal && inGlobal
+		}
+
+		if isJSONOutput(cmd) {
+			type listedProfile struct {
+				config.ConnectionProfile
+				Location string `json:"location"`
+				Shadowed bool   `json:"shadowed,omitempty"`
+			}
+			listed := make(map[string]listedProfile, len(profiles))
+			for name, profile := range profiles {
+				location, shadowed := locationOf(name)
+				listed[name] = listedProfile{ConnectionProfile: profile, Location: location, Shadowed: shadowed}
+			}
+			printJSON(listed)
+			return
+		}
+
+		if len(profiles) == 0 {
+			if envFlag != "" {
+				fmt.Printf("No connection profiles configured for environment '%s'.\n", envFlag)
+				return
+			}
 			fmt.Println("No connection profiles configured. Use 'bifrost profile create' to create one.")
 			return
 		}
 
-		fmt.Println("🔗 Connection Profiles:")
-		for name, profile := range cfg.ConnectionProfiles {
-			fmt.Printf("  • %s\n", name)
+		output.Println("🔗 Connection Profiles:")
+		for _, name := range sortProfilesByEnvironment(profiles) {
+			profile := profiles[name]
+			location, shadowed := locationOf(name)
+			output.Printf("  • %s\n", name)
+			fmt.Printf("    Location: %s\n", profileLocationLabel(location))
+			if shadowed && location == config.LocationLocal {
+				output.Printf("    ⚠️ Replaces the global profile '%s', edit %s to change it\n", name, config.LocalConfigPath())
+			} else if shadowed {
+				output.Printf("    ⚠️ Not used, the local profile '%s' in %s replaces it\n", name, config.LocalConfigPath())
+			}
+			if profile.Environment != "" {
+				fmt.Printf("    Environment: %s\n", profile.Environment)
+			}
 			fmt.Printf("    SSO Profile: %s\n", profile.SSOProfile)
 			fmt.Printf("    Service: %s\n", profile.ServiceType)
 			fmt.Printf("    Region: %s\n", profile.Region)
 			if profile.AccountID != "" {
 				fmt.Printf("    Account ID: %s\n", profile.AccountID)
 			}
+			if profile.AccountName != "" {
+				fmt.Printf("    Account Name: %s\n", profile.AccountName)
+			}
 			if profile.RoleName != "" {
 				fmt.Printf("    Role: %s\n", profile.RoleName)
 			}
@@ -255,17 +344,186 @@ var profileListCmd = &cobra.Command{
 				fmt.Printf("    Bastion: %s\n", profile.BastionInstanceID)
 			}
 			// Only show service-specific resource names
-			if profile.ServiceType == "rds" && profile.RDSInstanceName != "" {
-				fmt.Printf("    RDS Instance: %s\n", profile.RDSInstanceName)
+			if resourceName := profile.ResourceName(profile.ServiceType); resourceName != "" {
+				fmt.Printf("    Resource (%s): %s\n", serviceResourceLabels[profile.ServiceType], resourceName)
 			}
-			if profile.ServiceType == "redis" && profile.RedisClusterName != "" {
-				fmt.Printf("    Redis Cluster: %s\n", profile.RedisClusterName)
+			if len(profile.Targets) > 0 {
+				fmt.Printf("    Targets:\n")
+				for _, target := range profile.Targets {
+					fmt.Printf("      - %s %s → port %s\n", target.ServiceType, target.ResourceName, target.Port)
+				}
 			}
 			fmt.Println()
 		}
 	},
 }
 
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a single connection profile",
+	Long: `Show every field of a single connection profile and whether it lives in local or global config.
+
+Examples:
+  bifrost profile show --name dev-rds
+  bifrost profile show --name dev-rds --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		prompt := ui.NewPrompt()
+
+		profileName, _ := cmd.Flags().GetString("name")
+
+		// Prompt for profile name if not provided
+		if profileName == "" {
+			cfg, err := cfgManager.Load()
+			if err != nil {
+				output.Printf("Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+
+			if len(cfg.ConnectionProfiles) == 0 {
+				fmt.Println("No connection profiles found.")
+				return
+			}
+
+			profileNames := make([]string, 0, len(cfg.ConnectionProfiles))
+			for name := range cfg.ConnectionProfiles {
+				profileNames = append(profileNames, name)
+			}
+
+			selected, err := prompt.Select("Select profile to show", profileNames)
+			if err != nil {
+				output.Printf("Error selecting profile: %v\n", err)
+				os.Exit(1)
+			}
+			profileName = selected
+		}
+
+		profile, err := cfgManager.GetConnectionProfile(profileName)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		location, err := cfgManager.GetConnectionProfileLocation(profileName)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if isJSONOutput(cmd) {
+			printJSON(struct {
+				Name     string `json:"name"`
+				Location string `json:"location"`
+				config.ConnectionProfile
+			}{profileName, location, *profile})
+			return
+		}
+
+		output.Printf("🔗 %s\n", profileName)
+		fmt.Printf("    Location: %s\n", profileLocationLabel(location))
+		if profile.AWSProfile != "" {
+			fmt.Printf("    AWS Profile: %s\n", profile.AWSProfile)
+		}
+		fmt.Printf("    SSO Profile: %s\n", valueOrNotSet(profile.SSOProfile))
+		fmt.Printf("    Account ID: %s\n", valueOrNotSet(profile.AccountID))
+		if profile.AccountName != "" {
+			fmt.Printf("    Account Name: %s\n", profile.AccountName)
+		}
+		fmt.Printf("    Role: %s\n", valueOrNotSet(profile.RoleName))
+		if profile.AssumeRoleARN != "" {
+			fmt.Printf("    Assume Role: %s\n", profile.AssumeRoleARN)
+		}
+		if profile.ExternalID != "" {
+			fmt.Printf("    External ID: %s\n", profile.ExternalID)
+		}
+		fmt.Printf("    Region: %s\n", valueOrNotSet(profile.Region))
+		if len(profile.CandidateRegions) > 0 {
+			fmt.Printf("    Candidate Regions: %s\n", strings.Join(profile.CandidateRegions, ", "))
+		}
+		fmt.Printf("    Environment: %s\n", valueOrNotSet(profile.Environment))
+		fmt.Printf("    Service: %s\n", valueOrNotSet(profile.ServiceType))
+		fmt.Printf("    Port: %s\n", valueOrNotSet(profile.Port))
+		fmt.Printf("    Bastion: %s\n", valueOrNotSet(profile.BastionInstanceID))
+		fmt.Printf("    RDS Instance: %s\n", valueOrNotSet(profile.RDSInstanceName))
+		fmt.Printf("    Redis Cluster: %s\n", valueOrNotSet(profile.RedisClusterName))
+		fmt.Printf("    DocumentDB Cluster: %s\n", valueOrNotSet(profile.DocumentDBCluster))
+		fmt.Printf("    Neptune Cluster: %s\n", valueOrNotSet(profile.NeptuneCluster))
+		fmt.Printf("    OpenSearch Domain: %s\n", valueOrNotSet(profile.OpenSearchDomain))
+		fmt.Printf("    MSK Cluster: %s\n", valueOrNotSet(profile.MSKCluster))
+		if profile.CustomEndpoint != "" {
+			fmt.Printf("    Custom Endpoint: %s\n", profile.CustomEndpoint)
+		}
+		if profile.SSMDocument != "" {
+			fmt.Printf("    SSM Document: %s\n", profile.SSMDocument)
+		}
+		if profile.SSMParameters != "" {
+			fmt.Printf("    SSM Parameters: %s\n", profile.SSMParameters)
+		}
+		if profile.CredentialSecretARN != "" {
+			fmt.Printf("    Credential Secret: %s\n", profile.CredentialSecretARN)
+		}
+		if profile.DBUser != "" {
+			fmt.Printf("    Database User: %s\n", profile.DBUser)
+		}
+		if profile.DBName != "" {
+			fmt.Printf("    Database Name: %s\n", profile.DBName)
+		}
+		if len(profile.Targets) > 0 {
+			fmt.Printf("    Targets:\n")
+			for _, target := range profile.Targets {
+				fmt.Printf("      - %s %s → port %s\n", target.ServiceType, target.ResourceName, target.Port)
+			}
+		}
+	},
+}
+
+// profileLocationLabel names the config file a connection profile in location is stored in
+func profileLocationLabel(location string) string {
+	if location == config.LocationGlobal {
+		return "🌍 Global (~/.bifrost/config.yaml)"
+	}
+	return "📁 Local (.bifrost.config.yaml)"
+}
+
+// filterProfilesByEnvironment keeps the profiles tagged with env, or all of them when env is empty
+func filterProfilesByEnvironment(profiles map[string]config.ConnectionProfile, env string) map[string]config.ConnectionProfile {
+	if env == "" {
+		return profiles
+	}
+
+	filtered := make(map[string]config.ConnectionProfile)
+	for name, profile := range profiles {
+		if profile.Environment == env {
+			filtered[name] = profile
+		}
+	}
+	return filtered
+}
+
+// sortProfilesByEnvironment orders profile names by environment, then name, so environments are grouped together
+func sortProfilesByEnvironment(profiles map[string]config.ConnectionProfile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		envI, envJ := profiles[names[i]].Environment, profiles[names[j]].Environment
+		if envI != envJ {
+			return envI < envJ
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// valueOrNotSet returns a placeholder for empty profile fields
+func valueOrNotSet(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return value
+}
+
 var profileDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a connection profile",
@@ -279,7 +537,7 @@ var profileDeleteCmd = &cobra.Command{
 		// Load config
 		cfg, err := cfgManager.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			output.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -297,7 +555,7 @@ var profileDeleteCmd = &cobra.Command{
 
 			selected, err := prompt.Select("Select profile to delete", profileNames)
 			if err != nil {
-				fmt.Printf("Error selecting profile: %v\n", err)
+				output.Printf("Error selecting profile: %v\n", err)
 				os.Exit(1)
 			}
 			profileName = selected
@@ -317,7 +575,7 @@ var profileDeleteCmd = &cobra.Command{
 		}
 
 		// Check if profile exists in local config first
-		localConfigFile := ".bifrost.config.yaml"
+		localConfigFile := config.LocalConfigPath()
 		if _, err := os.Stat(localConfigFile); err == nil {
 			// Load local config to check if profile exists there
 			localConfig := &config.LocalConfig{ConnectionProfiles: make(map[string]config.ConnectionProfile)}
@@ -331,10 +589,10 @@ var profileDeleteCmd = &cobra.Command{
 						// Delete from local config
 						delete(localConfig.ConnectionProfiles, profileName)
 						if err := cfgManager.SaveLocal(localConfig.ConnectionProfiles); err != nil {
-							fmt.Printf("Error saving local config: %v\n", err)
+							output.Printf("Error saving local config: %v\n", err)
 							os.Exit(1)
 						}
-						fmt.Printf("✅ Connection profile '%s' deleted from local config (.bifrost.config.yaml)\n", profileName)
+						output.Printf("✅ Connection profile '%s' deleted from local config (%s)\n", profileName, config.LocalConfigPath())
 						return
 					}
 				}
@@ -351,7 +609,7 @@ var profileDeleteCmd = &cobra.Command{
 		// Load only global config
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			fmt.Printf("Error getting home directory: %v\n", err)
+			output.Printf("Error getting home directory: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -361,12 +619,12 @@ var profileDeleteCmd = &cobra.Command{
 		globalViper.SetConfigFile(globalConfigFile)
 
 		if err := globalViper.ReadInConfig(); err != nil {
-			fmt.Printf("Error reading global config: %v\n", err)
+			output.Printf("Error reading global config: %v\n", err)
 			os.Exit(1)
 		}
 
 		if err := globalViper.Unmarshal(globalConfig); err != nil {
-			fmt.Printf("Error parsing global config: %v\n", err)
+			output.Printf("Error parsing global config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -379,11 +637,11 @@ var profileDeleteCmd = &cobra.Command{
 		// Delete from global config
 		delete(globalConfig.ConnectionProfiles, profileName)
 		if err := cfgManager.Save(globalConfig); err != nil {
-			fmt.Printf("Error saving global config: %v\n", err)
+			output.Printf("Error saving global config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ Connection profile '%s' deleted from global config\n", profileName)
+		output.Printf("✅ Connection profile '%s' deleted from global config\n", profileName)
 	},
 }
 
@@ -391,19 +649,36 @@ func init() {
 	rootCmd.AddCommand(profileCmd)
 	profileCmd.AddCommand(profileCreateCmd)
 	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileShowCmd)
 	profileCmd.AddCommand(profileDeleteCmd)
 
 	// Create command flags
 	profileCreateCmd.Flags().StringP("name", "n", "", "Connection profile name")
 	profileCreateCmd.Flags().String("sso-profile", "", "SSO profile to use")
+	profileCreateCmd.Flags().String("aws-profile", "", "Profile from ~/.aws/config to take credentials from instead of an SSO profile")
 	profileCreateCmd.Flags().StringP("account-id", "a", "", "AWS account ID")
+	profileCreateCmd.Flags().String("account-name", "", "AWS account name, resolved to its ID at connect time instead of storing --account-id")
 	profileCreateCmd.Flags().StringP("role-name", "r", "", "AWS role name")
 	profileCreateCmd.Flags().String("region", "", "AWS region where workloads are deployed")
-	profileCreateCmd.Flags().StringP("service", "s", "", "Service type (rds, redis)")
+	profileCreateCmd.Flags().StringP("service", "s", "", "Service type (rds, redis, documentdb, neptune, opensearch, kafka, keyspaces, custom)")
 	profileCreateCmd.Flags().StringP("port", "p", "", "Default local port")
 	profileCreateCmd.Flags().String("bastion-id", "", "Bastion instance ID (optional)")
+	profileCreateCmd.Flags().String("env", "", "Environment the profile belongs to (e.g. dev, stg, prd)")
 	profileCreateCmd.Flags().Bool("global", false, "Save to global config instead of local (.bifrost.config.yaml)")
 
+	// List command flags
+	profileListCmd.Flags().String("env", "", "Only list profiles for this environment (e.g. dev, stg, prd)")
+	profileListCmd.Flags().String("location", "", "Only list profiles stored in local or global config")
+
+	// Show command flags
+	profileShowCmd.Flags().StringP("name", "n", "", "Connection profile name to show")
+
 	// Delete command flags
 	profileDeleteCmd.Flags().StringP("name", "n", "", "Connection profile name to delete")
+
+	_ = profileCreateCmd.RegisterFlagCompletionFunc("sso-profile", completeSSOProfiles)
+	_ = profileCreateCmd.RegisterFlagCompletionFunc("service", completeServiceTypes)
+	_ = profileShowCmd.RegisterFlagCompletionFunc("name", completeConnectionProfiles)
+	_ = profileListCmd.RegisterFlagCompletionFunc("location", cobra.FixedCompletions([]string{config.LocationLocal, config.LocationGlobal}, cobra.ShellCompDirectiveNoFileComp))
+	_ = profileDeleteCmd.RegisterFlagCompletionFunc("name", completeConnectionProfiles)
 }
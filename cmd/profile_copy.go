@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+var profileCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy a connection profile under a new name, e.g. into another environment",
+	Long: `Copy a connection profile under a new name, changing the fields given as flags. Handy when
+promoting a profile from one environment to the next, where only the account, region or
+resource differ.
+
+The copy is saved locally (.bifrost.config.yaml) by default, use --global for system-wide
+profiles. An existing profile with the new name is only replaced with --force.
+
+Examples:
+  bifrost profile copy --from dev-rds --to stg-rds --env stg
+  bifrost profile copy --from dev-rds --to prd-rds --env prd --account-id 123456789012 --resource prd-db --global`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		global, _ := cmd.Flags().GetBool("global")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if from == "" || to == "" {
+			fmt.Println("Both --from and --to are required.")
+			os.Exit(1)
+		}
+		if from == to {
+			fmt.Println("--from and --to must be different profiles.")
+			os.Exit(1)
+		}
+
+		cfg, err := cfgManager.Load()
+		if err != nil {
+			output.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		profile, exists := cfg.ConnectionProfiles[from]
+		if !exists {
+			fmt.Printf("Connection profile '%s' not found\n", from)
+			os.Exit(1)
+		}
+		if _, exists := cfg.ConnectionProfiles[to]; exists && !force {
+			fmt.Printf("Connection profile '%s' already exists, use --force to replace it\n", to)
+			os.Exit(1)
+		}
+
+		if err := applyProfileOverrides(cmd, &profile); err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if global {
+			err = cfgManager.AddConnectionProfile(to, profile)
+		} else {
+			err = cfgManager.AddLocalConnectionProfile(to, profile)
+		}
+		if err != nil {
+			output.Printf("Error saving connection profile: %v\n", err)
+			os.Exit(1)
+		}
+
+		if global {
+			output.Printf("✅ Copied '%s' to '%s' in global config\n", from, to)
+			if location, err := cfgManager.GetConnectionProfileLocation(to); err == nil && location == config.LocationLocal {
+				output.Printf("⚠️ Warning: the local profile '%s' in %s takes precedence over the copy\n", to, config.LocalConfigPath())
+			}
+		} else {
+			output.Printf("✅ Copied '%s' to '%s' in local config (%s)\n", from, to, config.LocalConfigPath())
+		}
+		fmt.Println("You can now use it with: bifrost connect --profile " + to)
+	},
+}
+
+// applyProfileOverrides sets the profile fields given as copy flags, leaving the rest as copied
+func applyProfileOverrides(cmd *cobra.Command, profile *config.ConnectionProfile) error {
+	fields := []struct {
+		flag  string
+		field *string
+	}{
+		{"env", &profile.Environment},
+		{"region", &profile.Region},
+		{"account-id", &profile.AccountID},
+		{"account-name", &profile.AccountName},
+		{"role-name", &profile.RoleName},
+		{"bastion-id", &profile.BastionInstanceID},
+		{"port", &profile.Port},
+	}
+	// The copy's account is either the given ID or the given name, not one of them plus the original's other
+	if accountID, _ := cmd.Flags().GetString("account-id"); accountID != "" {
+		profile.AccountName = ""
+	}
+	if accountName, _ := cmd.Flags().GetString("account-name"); accountName != "" {
+		profile.AccountID = ""
+	}
+	for _, f := range fields {
+		if value, _ := cmd.Flags().GetString(f.flag); value != "" {
+			*f.field = value
+		}
+	}
+	if bastionID, _ := cmd.Flags().GetString("bastion-id"); bastionID != "" {
+		if err := connect.ValidateInstanceID(bastionID); err != nil {
+			return err
+		}
+	}
+
+	resource, _ := cmd.Flags().GetString("resource")
+	if resource == "" {
+		return nil
+	}
+	if profile.ServiceType == "" || len(profile.Targets) > 0 {
+		return fmt.Errorf("--resource only works with single-service profiles, edit the targets in the config instead")
+	}
+	if profile.ServiceType == "keyspaces" {
+		return fmt.Errorf("keyspaces profiles have no resource name to change")
+	}
+	profile.SetResourceName(profile.ServiceType, resource)
+	return nil
+}
+
+func init() {
+	profileCmd.AddCommand(profileCopyCmd)
+
+	profileCopyCmd.Flags().String("from", "", "Connection profile to copy")
+	profileCopyCmd.Flags().String("to", "", "Name of the new connection profile")
+	profileCopyCmd.Flags().String("env", "", "Environment of the copy (e.g. dev, stg, prd)")
+	profileCopyCmd.Flags().String("region", "", "AWS region of the copy")
+	profileCopyCmd.Flags().StringP("account-id", "a", "", "AWS account ID of the copy")
+	profileCopyCmd.Flags().String("account-name", "", "AWS account name of the copy")
+	profileCopyCmd.Flags().StringP("role-name", "r", "", "AWS role name of the copy")
+	profileCopyCmd.Flags().String("bastion-id", "", "Bastion instance ID of the copy")
+	profileCopyCmd.Flags().StringP("port", "p", "", "Default local port of the copy")
+	profileCopyCmd.Flags().String("resource", "", "Resource name of the copy (RDS instance, Redis cluster, ...)")
+	profileCopyCmd.Flags().Bool("global", false, "Save to global config instead of local (.bifrost.config.yaml)")
+	profileCopyCmd.Flags().Bool("force", false, "Replace an existing profile with the new name")
+
+	_ = profileCopyCmd.RegisterFlagCompletionFunc("from", completeConnectionProfiles)
+}
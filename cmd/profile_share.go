@@ -0,0 +1,247 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// profileBundle is the portable file written by profile export and read by profile import
+type profileBundle struct {
+	Version            int                                 `yaml:"version"`
+	SSOProfiles        map[string]config.SSOProfile        `yaml:"sso_profiles,omitempty"`
+	ConnectionProfiles map[string]config.ConnectionProfile `yaml:"connection_profiles"`
+}
+
+// Choices offered when an imported profile name already exists
+const (
+	importOverwrite = "Overwrite existing profile"
+	importRename    = "Import under a new name"
+	importSkip      = "Skip"
+)
+
+var profileExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export connection profiles to a YAML file",
+	Long: `Export connection profiles, together with the SSO profiles they reference, to a portable YAML file.
+Profiles hold no secrets, so the file can be shared with your team and loaded with 'bifrost profile import'.
+
+Examples:
+  bifrost profile export --name dev-rds --file dev.yaml
+  bifrost profile export --all --file team.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		prompt := ui.NewPrompt()
+
+		names, _ := cmd.Flags().GetStringSlice("name")
+		allFlag, _ := cmd.Flags().GetBool("all")
+		fileFlag, _ := cmd.Flags().GetString("file")
+
+		cfg, err := cfgManager.Load()
+		if err != nil {
+			output.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(cfg.ConnectionProfiles) == 0 {
+			fmt.Println("No connection profiles found.")
+			return
+		}
+
+		allNames := make([]string, 0, len(cfg.ConnectionProfiles))
+		for name := range cfg.ConnectionProfiles {
+			allNames = append(allNames, name)
+		}
+		sort.Strings(allNames)
+
+		switch {
+		case allFlag:
+			names = allNames
+		case len(names) == 0:
+			selected, err := prompt.Select("Select profile to export", allNames)
+			if err != nil {
+				output.Printf("Error selecting profile: %v\n", err)
+				os.Exit(1)
+			}
+			names = []string{selected}
+		}
+
+		bundle := profileBundle{
+			Version:            config.CurrentVersion,
+			SSOProfiles:        make(map[string]config.SSOProfile),
+			ConnectionProfiles: make(map[string]config.ConnectionProfile),
+		}
+		for _, name := range names {
+			profile, exists := cfg.ConnectionProfiles[name]
+			if !exists {
+				fmt.Printf("Connection profile '%s' not found\n", name)
+				os.Exit(1)
+			}
+			bundle.ConnectionProfiles[name] = profile
+
+			// Include the SSO profile definition so the file works on a fresh machine
+			if ssoProfile, exists := cfg.SSOProfiles[profile.SSOProfile]; exists {
+				bundle.SSOProfiles[profile.SSOProfile] = ssoProfile
+			}
+		}
+
+		data, err := yaml.Marshal(bundle)
+		if err != nil {
+			output.Printf("Error encoding profiles: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := os.WriteFile(fileFlag, data, 0644); err != nil {
+			output.Printf("Error writing %s: %v\n", fileFlag, err)
+			os.Exit(1)
+		}
+
+		output.Printf("✅ Exported %d connection profile(s) to %s\n", len(bundle.ConnectionProfiles), fileFlag)
+	},
+}
+
+var profileImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import connection profiles from a YAML file",
+	Long: `Import connection profiles from a file written by 'bifrost profile export'.
+Profiles are saved to the local config (.bifrost.config.yaml) unless --global is given. SSO profiles
+in the file are added to the global config when you don't have one with the same name yet.
+You'll be asked what to do when a connection profile with the same name already exists.
+
+Examples:
+  bifrost profile import --file team.yaml
+  bifrost profile import --file team.yaml --global`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		prompt := ui.NewPrompt()
+
+		fileFlag, _ := cmd.Flags().GetString("file")
+		globalFlag, _ := cmd.Flags().GetBool("global")
+
+		data, err := os.ReadFile(fileFlag)
+		if err != nil {
+			output.Printf("Error reading %s: %v\n", fileFlag, err)
+			os.Exit(1)
+		}
+
+		var bundle profileBundle
+		if err := yaml.Unmarshal(data, &bundle); err != nil {
+			output.Printf("Error parsing %s: %v\n", fileFlag, err)
+			os.Exit(1)
+		}
+		if bundle.Version > config.CurrentVersion {
+			output.Printf("Error: %s was exported by a newer version of bifrost (config version %d), please upgrade\n", fileFlag, bundle.Version)
+			os.Exit(1)
+		}
+
+		cfg, err := cfgManager.Load()
+		if err != nil {
+			output.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Add referenced SSO profiles that don't exist yet, never overwriting existing ones
+		for _, name := range sortedKeys(bundle.SSOProfiles) {
+			ssoProfile := bundle.SSOProfiles[name]
+			existing, exists := cfg.SSOProfiles[name]
+			if exists {
+				if !existing.Equal(ssoProfile) {
+					output.Printf("⚠️ Keeping your SSO profile '%s' (%s), the file uses %s\n", name, existing.StartURL, ssoProfile.StartURL)
+				}
+				continue
+			}
+			if err := cfgManager.AddSSOProfile(name, ssoProfile); err != nil {
+				output.Printf("Error saving SSO profile '%s': %v\n", name, err)
+				os.Exit(1)
+			}
+			output.Printf("🔐 Added SSO profile '%s'\n", name)
+		}
+
+		imported := 0
+		for _, name := range sortedKeys(bundle.ConnectionProfiles) {
+			profile := bundle.ConnectionProfiles[name]
+
+			if _, exists := cfg.ConnectionProfiles[name]; exists {
+				choice, err := prompt.Select(fmt.Sprintf("Connection profile '%s' already exists", name), []string{importOverwrite, importRename, importSkip})
+				if err != nil {
+					output.Printf("Error resolving name collision: %v\n", err)
+					os.Exit(1)
+				}
+
+				switch choice {
+				case importSkip:
+					output.Printf("⏭️ Skipped '%s'\n", name)
+					continue
+				case importRename:
+					name, err = prompt.Input("New profile name", func(s string) error {
+						if s == "" {
+							return fmt.Errorf("profile name cannot be empty")
+						}
+						if _, exists := cfg.ConnectionProfiles[s]; exists {
+							return fmt.Errorf("profile '%s' already exists", s)
+						}
+						return nil
+					})
+					if err != nil {
+						output.Printf("Error getting profile name: %v\n", err)
+						os.Exit(1)
+					}
+				}
+			}
+
+			if globalFlag {
+				err = cfgManager.AddConnectionProfile(name, profile)
+			} else {
+				err = cfgManager.AddLocalConnectionProfile(name, profile)
+			}
+			if err != nil {
+				output.Printf("Error saving connection profile '%s': %v\n", name, err)
+				os.Exit(1)
+			}
+			cfg.ConnectionProfiles[name] = profile
+			imported++
+			output.Printf("📥 Imported '%s'\n", name)
+		}
+
+		location := "local config (.bifrost.config.yaml)"
+		if globalFlag {
+			location = "global config"
+		}
+		output.Printf("✅ Imported %d connection profile(s) to %s\n", imported, location)
+	},
+}
+
+// sortedKeys returns the keys of a profile map in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	profileCmd.AddCommand(profileExportCmd)
+	profileCmd.AddCommand(profileImportCmd)
+
+	profileExportCmd.Flags().StringSliceP("name", "n", nil, "Connection profile(s) to export (repeatable)")
+	profileExportCmd.Flags().Bool("all", false, "Export all connection profiles")
+	profileExportCmd.Flags().StringP("file", "f", "", "File to write the profiles to")
+	_ = profileExportCmd.MarkFlagRequired("file")
+
+	profileImportCmd.Flags().StringP("file", "f", "", "File to read the profiles from")
+	profileImportCmd.Flags().Bool("global", false, "Save to global config instead of local (.bifrost.config.yaml)")
+	_ = profileImportCmd.MarkFlagRequired("file")
+
+	_ = profileExportCmd.RegisterFlagCompletionFunc("name", completeConnectionProfiles)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+var profileValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that a profile's account, role, bastion and resources still exist",
+	Long: `Sign in with a connection profile and check everything it points at before relying on it:
+  - The account and role resolve to credentials (or the AWS profile does)
+  - The bastion is registered with SSM and online
+  - Each resource (RDS instance, Redis cluster, ...) can be described
+
+This catches drift such as deleted instances or renamed clusters. Values the profile leaves
+empty are reported as warnings, connect prompts for them.
+
+Exits with status 1 if any check fails.
+
+Examples:
+  bifrost profile validate --name dev-rds`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfgManager := config.NewManager()
+		prompt := ui.NewPrompt()
+
+		profileName, _ := cmd.Flags().GetString("name")
+		awsTimeout, _ := cmd.Flags().GetDuration("aws-timeout")
+		authTimeout, _ := cmd.Flags().GetDuration("auth-timeout")
+
+		if profileName == "" {
+			cfg, err := cfgManager.Load()
+			if err != nil {
+				output.Printf("Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+			if len(cfg.ConnectionProfiles) == 0 {
+				fmt.Println("No connection profiles found.")
+				return
+			}
+
+			selected, err := prompt.SelectFilterable("Select profile to validate", sortProfilesByEnvironment(cfg.ConnectionProfiles))
+			if err != nil {
+				output.Printf("Error selecting profile: %v\n", err)
+				os.Exit(1)
+			}
+			profileName = selected
+		}
+
+		profile, err := cfgManager.GetConnectionProfile(profileName)
+		if err != nil {
+			output.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		output.Printf("🔍 Validating profile '%s'\n", profileName)
+		checks := validateProfile(*profile, awsTimeout, authTimeout)
+
+		fmt.Println()
+		counts := make(map[string]int)
+		for _, check := range checks {
+			counts[check.Status]++
+			fmt.Printf("%s %s: %s\n", output.Icon(doctorIcons[check.Status]), check.Name, check.Detail)
+			if check.Hint != "" {
+				output.Printf("   💡 %s\n", check.Hint)
+			}
+		}
+
+		fmt.Println()
+		fmt.Printf("%d passed, %d warnings, %d failed\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+		if counts[doctorFail] > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// validateProfile signs in with the profile and checks its bastion and resources, stopping at the
+// first check the rest depend on
+func validateProfile(profile config.ConnectionProfile, awsTimeout, authTimeout time.Duration) []doctorCheck {
+	var checks []doctorCheck
+	chain := connect.RoleChain{RoleARN: profile.AssumeRoleARN, ExternalID: profile.ExternalID}
+
+	var awsCfg aws.Config
+	region := profile.Region
+	if profile.AWSProfile != "" {
+		cfg, accountID, err := getSharedProfileConfig(profile.AWSProfile, region, chain, awsTimeout)
+		if err != nil {
+			return append(checks, doctorCheck{
+				Status: doctorFail,
+				Name:   "Credentials",
+				Detail: err.Error(),
+				Hint:   fmt.Sprintf("Check the '%s' profile in ~/.aws/config", profile.AWSProfile),
+			})
+		}
+		awsCfg = cfg
+		if region == "" {
+			region = cfg.Region
+		}
+		checks = append(checks, doctorCheck{Status: doctorPass, Name: "Credentials", Detail: fmt.Sprintf("AWS profile '%s' (account %s)", profile.AWSProfile, accountID)})
+	} else {
+		if profile.SSOProfile == "" {
+			return append(checks, doctorCheck{Status: doctorFail, Name: "Credentials", Detail: "no SSO or AWS profile set", Hint: "Set sso_profile or aws_profile on the profile"})
+		}
+		if (profile.AccountID == "" && profile.AccountName == "") || profile.RoleName == "" {
+			checks = append(checks, doctorCheck{Status: doctorWarn, Name: "Account and role", Detail: "not both set, you'll be asked to pick them"})
+		}
+
+		// Without a region the credentials are fetched through the SSO region, the workload checks are skipped below
+		credsRegion := region
+		if credsRegion == "" {
+			ssoProfile, err := config.NewManager().GetSSOProfile(profile.SSOProfile)
+			if err != nil {
+				return append(checks, doctorCheck{Status: doctorFail, Name: "Credentials", Detail: err.Error(), Hint: "Create it with 'bifrost auth configure'"})
+			}
+			credsRegion = ssoProfile.SSORegion
+		}
+
+		cfg, accountID, roleName, err := getAWSConfig(profile.SSOProfile, credsRegion, profile.AccountID, profile.AccountName, profile.RoleName, authTimeout, chain)
+		if err != nil {
+			return append(checks, doctorCheck{
+				Status: doctorFail,
+				Name:   "Account and role",
+				Detail: err.Error(),
+				Hint:   "Check the account ID and role name still exist and are assigned to you in IAM Identity Center",
+			})
+		}
+		awsCfg = cfg
+		checks = append(checks, doctorCheck{Status: doctorPass, Name: "Account and role", Detail: fmt.Sprintf("%s in account %s", roleName, accountID)})
+	}
+	if chain.RoleARN != "" {
+		checks = append(checks, doctorCheck{Status: doctorPass, Name: "Assumed role", Detail: chain.RoleARN})
+	}
+
+	if region == "" {
+		return append(checks, doctorCheck{Status: doctorWarn, Name: "Region", Detail: "not set, skipped the bastion and resource checks", Hint: "Set region on the profile to check them"})
+	}
+	awsCfg.Region = region
+
+	checks = append(checks, checkProfileBastion(awsCfg, profile.BastionInstanceID, awsTimeout))
+
+	specs := profile.Targets
+	if len(specs) == 0 && profile.ServiceType != "" {
+		specs = []config.TargetSpec{{ServiceType: profile.ServiceType, Port: profile.Port, ResourceName: profile.ResourceName(profile.ServiceType)}}
+	}
+	if len(specs) == 0 {
+		return append(checks, doctorCheck{Status: doctorWarn, Name: "Resource", Detail: "no service set, you'll be asked to pick one"})
+	}
+	for _, spec := range specs {
+		checks = append(checks, checkProfileResource(awsCfg, spec, awsTimeout))
+	}
+	return checks
+}
+
+// checkProfileBastion reports whether the bastion is registered with SSM and its agent is online
+func checkProfileBastion(cfg aws.Config, instanceID string, timeout time.Duration) doctorCheck {
+	check := doctorCheck{Name: "Bastion"}
+	if instanceID == "" {
+		check.Status, check.Detail = doctorWarn, "not set, you'll be asked to pick one"
+		return check
+	}
+	check.Name = fmt.Sprintf("Bastion %s", instanceID)
+
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+
+	info, err := describeBastion(ctx, cfg, instanceID)
+	switch {
+	case err != nil:
+		check.Status, check.Detail = doctorFail, connect.AWSError(ctx, err).Error()
+	case info == nil:
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("not registered with SSM in %s", cfg.Region)
+		check.Hint = "The instance may have been replaced, pick a new one with 'bifrost connect --bastion-instance-id=-'"
+	case info.PingStatus != ssmtypes.PingStatusOnline:
+		check.Status, check.Detail = doctorFail, fmt.Sprintf("SSM agent status is %s", info.PingStatus)
+		check.Hint = "Start the instance or check its SSM agent"
+	case connect.AgentOutdated(aws.ToString(info.AgentVersion)):
+		check.Status, check.Detail = doctorWarn, fmt.Sprintf("online in SSM, but agent %s is older than %s, which port forwarding needs", aws.ToString(info.AgentVersion), connect.MinAgentVersion)
+		check.Hint = "Update the SSM agent, e.g. with the AWS-UpdateSSMAgent document"
+	default:
+		check.Status, check.Detail = doctorPass, "online in SSM"
+	}
+	return check
+}
+
+// checkProfileResource reports whether a target's resource can be described and where it points
+func checkProfileResource(cfg aws.Config, spec config.TargetSpec, timeout time.Duration) doctorCheck {
+	label := serviceResourceLabels[spec.ServiceType]
+	if label == "" {
+		label = spec.ServiceType
+	}
+	check := doctorCheck{Name: fmt.Sprintf("%s '%s'", label, spec.ResourceName)}
+	if spec.ResourceName == "" && spec.ServiceType != "keyspaces" {
+		check.Name = label
+		check.Status, check.Detail = doctorWarn, "not set, you'll be asked to pick one"
+		return check
+	}
+
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+
+	// Any node group proves a sharded Redis cluster exists, there's no need to ask which
+	first := func(_ string, options []string) (string, error) { return options[0], nil }
+	targets, err := connect.ResolveTargets(ctx, cfg, first, spec.ServiceType, spec.ResourceName, connect.RedisEndpointPrimary, spec.Port)
+	switch {
+	case err != nil:
+		check.Status, check.Detail = doctorFail, err.Error()
+		check.Hint = fmt.Sprintf("It may have been deleted or renamed, update the profile with the current %s name", label)
+	case targets[0].Status != "" && targets[0].Status != connect.RDSStatusAvailable:
+		check.Status, check.Detail = doctorWarn, fmt.Sprintf("%s:%d, but %s", targets[0].Endpoint, targets[0].Port, targets[0].Status)
+		check.Hint = "Connections will fail until it is available again"
+	case len(targets) > 1:
+		check.Status, check.Detail = doctorPass, fmt.Sprintf("%d brokers, first at %s:%d", len(targets), targets[0].Endpoint, targets[0].Port)
+	default:
+		check.Status, check.Detail = doctorPass, fmt.Sprintf("%s:%d", targets[0].Endpoint, targets[0].Port)
+	}
+	return check
+}
+
+func init() {
+	profileCmd.AddCommand(profileValidateCmd)
+
+	profileValidateCmd.Flags().StringP("name", "n", "", "Connection profile name to validate")
+	profileValidateCmd.Flags().Duration("aws-timeout", connect.DefaultAWSTimeout, "How long to wait for each AWS lookup before giving up")
+	profileValidateCmd.Flags().Duration("auth-timeout", sso.DefaultAuthTimeout, "How long to wait for the SSO login to be approved in the browser")
+
+	_ = profileValidateCmd.RegisterFlagCompletionFunc("name", completeConnectionProfiles)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/b3nk3/bifrost/internal/cache"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// regionsCacheTTL is how long an account's enabled regions are reused, they rarely change
+const regionsCacheTTL = 24 * time.Hour
+
+// knownRegions is offered when the account's enabled regions can't be listed (e.g. before signing in)
+var knownRegions = []string{
+	"af-south-1",
+	"ap-east-1",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-northeast-3",
+	"ap-south-1",
+	"ap-south-2",
+	"ap-southeast-1",
+	"ap-southeast-2",
+	"ap-southeast-3",
+	"ap-southeast-4",
+	"ca-central-1",
+	"ca-west-1",
+	"eu-central-1",
+	"eu-central-2",
+	"eu-north-1",
+	"eu-south-1",
+	"eu-south-2",
+	"eu-west-1",
+	"eu-west-2",
+	"eu-west-3",
+	"il-central-1",
+	"me-central-1",
+	"me-south-1",
+	"sa-east-1",
+	"us-east-1",
+	"us-east-2",
+	"us-west-1",
+	"us-west-2",
+}
+
+// selectRegion prompts for the workload region. With credentials (cfg not nil) it offers the
+// account's enabled regions, otherwise or if listing them fails the static list of known regions.
+func selectRegion(ctx context.Context, prompt *ui.Prompt, cfg *aws.Config, accountID string, ttl time.Duration) (string, error) {
+	regions := knownRegions
+	if cfg != nil {
+		enabled, err := enabledRegions(ctx, *cfg, accountID, ttl)
+		if err != nil || len(enabled) == 0 {
+			slog.Warn("Could not list enabled regions, showing known regions instead", "error", err)
+		} else {
+			regions = enabled
+		}
+	}
+
+	return prompt.SelectFilterable("AWS region (where your workloads are)", regions)
+}
+
+// enabledRegions lists the regions enabled for the account, cached for regionsCacheTTL unless ttl is 0
+func enabledRegions(ctx context.Context, cfg aws.Config, accountID string, ttl time.Duration) ([]string, error) {
+	if ttl > 0 {
+		ttl = regionsCacheTTL
+	}
+	return cache.Fetch(cache.Key(accountID, "global", "regions"), ttl, func() (regions []string, err error) {
+		err = ui.WithSpinner("Listing enabled regions…", func() error {
+			regions, err = listEnabledRegions(ctx, cfg)
+			return err
+		})
+		return regions, err
+	})
+}
+
+// locateResourceRegion checks the RDS instance or Redis cluster exists in cfg's region. If it doesn't,
+// it is looked for in the candidate regions (all enabled regions without any) and the user is offered
+// to switch to the region it was found in, without asking when there's no terminal. It returns the
+// region to switch to, empty to stay.
+func locateResourceRegion(prompt *ui.Prompt, cfg aws.Config, accountID, serviceType, name string, candidates []string, timeout, ttl time.Duration) string {
+	ctx, cancel := awsContext(timeout)
+	defer cancel()
+
+	// Any other error is reported when the endpoint is resolved
+	if exists, err := connect.ResourceExists(ctx, cfg, serviceType, name); err != nil || exists {
+		return ""
+	}
+
+	regions := candidates
+	if len(regions) == 0 {
+		enabled, err := enabledRegions(ctx, cfg, accountID, ttl)
+		if err != nil {
+			output.Printf("⚠️ Could not list enabled regions to look for %s '%s': %v\n", serviceResourceLabels[serviceType], name, err)
+			return ""
+		}
+		regions = enabled
+	}
+	regions = slices.DeleteFunc(slices.Clone(regions), func(region string) bool { return region == cfg.Region })
+
+	output.Printf("🔍 %s '%s' is not in %s, looking in %d other regions...\n", serviceResourceLabels[serviceType], name, cfg.Region, len(regions))
+	var region string
+	err := ui.WithSpinner("Searching regions…", func() (err error) {
+		region, err = connect.FindResourceRegion(ctx, cfg, serviceType, name, regions)
+		return err
+	})
+	if err != nil {
+		output.Printf("⚠️ Could not find %s '%s' in another region: %v\n", serviceResourceLabels[serviceType], name, err)
+		return ""
+	}
+	output.Printf("📍 Found %s '%s' in %s\n", serviceResourceLabels[serviceType], name, region)
+
+	if prompt.Interactive() {
+		confirmed, err := prompt.Confirm(fmt.Sprintf("Switch to %s?", region))
+		if err != nil || !confirmed {
+			return ""
+		}
+	}
+	return region
+}
+
+// List the regions enabled for the account
+func listEnabledRegions(ctx context.Context, cfg aws.Config) ([]string, error) {
+	svc := ec2.NewFromConfig(cfg)
+
+	result, err := svc.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+
+	regions := make([]string, 0, len(result.Regions))
+	for _, region := range result.Regions {
+		if region.RegionName != nil {
+			regions = append(regions, *region.RegionName)
+		}
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
@@ -6,6 +6,12 @@ package cmd
 import (
 	"os"
 
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/ui"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
@@ -19,15 +25,64 @@ making it easier to manage and access your resources in the cloud.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// The log file has to be open before the logger is set up so diagnostics reach it too
+		if logFile, _ := cmd.Flags().GetString("log-file"); logFile != "" {
+			if err := logging.OpenLogFile(logFile, os.Args); err != nil {
+				return err
+			}
+			output.CopyTo(logging.LogFile())
+		}
+
+		logLevel, _ := cmd.Flags().GetString("log-level")
+		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+			logLevel = "debug"
+		}
+		if err := logging.Setup(logLevel); err != nil {
+			return err
+		}
+
+		if localConfig, _ := cmd.Flags().GetString("local-config"); localConfig != "" {
+			config.SetLocalConfigPath(localConfig)
+		}
+
+		noEmoji, _ := cmd.Flags().GetBool("no-emoji")
+		output.Configure(noEmoji)
+		connect.SetReporter(output.Printf)
+
+		nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+		// BIFROST_PROMPT=plain reads answers line by line, also from a pipe. Without it a stdin that
+		// isn't a terminal means nobody is there to answer, e.g. in CI where it may never be closed.
+		plainPrompts := os.Getenv(ui.PromptEnv) == ui.PromptPlain
+		ui.SetInteractive(!nonInteractive && (isTerminal(os.Stdin) || plainPrompts))
+		if plainPrompts {
+			ui.SetPrompter(ui.NewLinePrompter(os.Stdin, os.Stderr))
+		}
+		return validateOutputFormat(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	_ = logging.CloseLogFile()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
-func init() {}
+func init() {
+	rootCmd.PersistentFlags().String("output", outputTable, "Output format for list commands and connect's result (table or json)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log debug detail such as AWS request IDs and timings (same as --log-level debug)")
+	rootCmd.PersistentFlags().String("log-level", logging.DefaultLevel, "Log level for diagnostics on stderr (debug, info, warn or error)")
+	rootCmd.PersistentFlags().String("log-file", "", "Also write status output, diagnostics and SSM session output to this file, with credentials redacted (e.g. to attach to a bug report)")
+	rootCmd.PersistentFlags().String("local-config", "", "Local config file to use instead of the nearest .bifrost.config.yaml in this or a parent directory")
+	rootCmd.PersistentFlags().Bool("non-interactive", false, "Never prompt; fail when a required value is missing (implied when stdin is not a terminal)")
+	rootCmd.PersistentFlags().Bool("no-emoji", false, "Print ASCII prefixes such as [ok] and [warn] instead of emoji (automatic with a non-UTF-8 locale)")
+}
+
+// isTerminal reports whether f is attached to a terminal that can render prompts
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+)
+
+// commandReadyTimeout is how long --command waits for the tunnel to accept connections
+const commandReadyTimeout = 30 * time.Second
+
+// renderClientCommand fills {{host}} and {{port}} in a --command template with the local address
+func renderClientCommand(template, host, localPort string) string {
+	return strings.NewReplacer("{{host}}", host, "{{port}}", localPort).Replace(template)
+}
+
+// runClientCommand runs the --command client through the tunnels and exits with its exit code
+func runClientCommand(cfg aws.Config, instanceID string, targets []connect.Target, opts connect.TunnelOptions, command string) {
+	exitCode, err := runTunnelsWithCommand(cfg, instanceID, targets, opts, command)
+	if err != nil {
+		output.Printf("Error running command: %v\n", err)
+	}
+	os.Exit(exitCode)
+}
+
+// runTunnelsWithCommand opens the tunnels, runs the client command once they accept connections and
+// closes them when it exits. It returns the command's exit code.
+func runTunnelsWithCommand(cfg aws.Config, instanceID string, targets []connect.Target, opts connect.TunnelOptions, command string) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Ctrl+C belongs to the client (e.g. to cancel a query in psql), only SIGTERM stops bifrost
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		for {
+			select {
+			case sig := <-sigChan:
+				if sig == syscall.SIGTERM {
+					output.Println("\n🛑 Shutting down connection...")
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The SSM session gets its own process group so the terminal's Ctrl+C only reaches the client,
+	// and its output stays out of the client's way
+	opts.Stderr = logging.Tee(os.Stderr)
+	opts.Prepare = detachProcess
+	// Anything to print once the tunnels are up has to come before the client takes over the terminal
+	onReady := opts.OnReady
+	opts.OnReady = nil
+
+	started := time.Now()
+	tunnels, err := connect.Start(ctx, cfg, instanceID, targets, opts)
+	if err != nil {
+		return 1, err
+	}
+	defer func() {
+		// The command's result is what gets reported, the tunnel's only goes to the metrics
+		recordSessionMetrics(started, tunnels, tunnels.Close())
+	}()
+
+	readyCtx, cancelReady := context.WithTimeout(ctx, commandReadyTimeout)
+	defer cancelReady()
+	if err := tunnels.Ready(readyCtx); err != nil {
+		return 1, err
+	}
+	if onReady != nil {
+		onReady()
+	}
+
+	rendered := renderClientCommand(command, opts.ListenAddress(), targets[0].LocalPort)
+	output.Printf("▶️ Running: %s\n\n", rendered)
+
+	client := shellCommand(ctx, rendered)
+	client.Stdin = os.Stdin
+	client.Stdout = os.Stdout
+	client.Stderr = os.Stderr
+
+	// A tunnel going down takes the client with it
+	go func() {
+		select {
+		case <-tunnels.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = client.Run()
+	if err != nil && ctx.Err() != nil {
+		if tunnelErr := tunnels.Wait(); tunnelErr != nil {
+			return 1, fmt.Errorf("tunnel closed while the command was running: %w", tunnelErr)
+		}
+		return 1, fmt.Errorf("connection stopped while the command was running")
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return exitErr.ExitCode(), nil
+	default:
+		return 1, fmt.Errorf("failed to run command: %w", err)
+	}
+}
+
+// shellCommand runs command through the platform's shell so quoting and pipes work as typed
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/session"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// sessionsCmd represents the sessions command
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage background tunnels",
+	Long:  `Manage tunnels started with 'bifrost connect --background'.`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List background tunnels",
+	Long:  `List tunnels started with 'bifrost connect --background' and check whether they still respond.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sessions, err := session.LoadSessions()
+		if err != nil {
+			output.Printf("Error loading sessions: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Drop sessions whose process has gone away
+		active := make([]session.Session, 0, len(sessions))
+		for _, s := range sessions {
+			if s.IsRunning() {
+				active = append(active, s)
+			} else if !isJSONOutput(cmd) {
+				output.Printf("🧹 Removed stale session on port %s (PID %d)\n", s.LocalPort, s.PID)
+			}
+		}
+		if len(active) != len(sessions) {
+			if err := session.SaveSessions(active); err != nil {
+				output.Printf("⚠️ Warning: failed to update session registry: %v\n", err)
+			}
+		}
+
+		if isJSONOutput(cmd) {
+			printJSON(active)
+			return
+		}
+
+		if len(active) == 0 {
+			fmt.Println("No background sessions running. Use 'bifrost connect --background' to start one.")
+			return
+		}
+
+		output.Println("🌉 Background Sessions:")
+		for _, s := range active {
+			output.Printf("  • 127.0.0.1:%s → %s\n", s.LocalPort, s.Endpoint)
+			fmt.Printf("    Service: %s\n", s.ServiceType)
+			if s.Profile != "" {
+				fmt.Printf("    Profile: %s\n", s.Profile)
+			}
+			fmt.Printf("    Bastion: %s\n", s.Target)
+			fmt.Printf("    PID: %d\n", s.PID)
+			fmt.Printf("    Started: %s\n", s.StartedAt.Format("2006-01-02 15:04:05"))
+			if err := connect.PerformKeepAlive(net.JoinHostPort(connect.DefaultBindAddress, s.LocalPort)); err != nil {
+				fmt.Printf("    Status: %s not responding\n", output.Icon("⚠️"))
+			} else {
+				fmt.Printf("    Status: %s responding\n", output.Icon("✅"))
+			}
+			fmt.Println()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 Ben Szabo me@benszabo.co.uk
+*/
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/connect"
+	"github.com/b3nk3/bifrost/internal/metrics"
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/cobra"
+)
+
+// statsOutput is the JSON shape of bifrost stats
+type statsOutput struct {
+	Since    time.Time        `json:"since"`
+	Summary  metrics.Summary  `json:"summary"`
+	Sessions []metrics.Record `json:"sessions"`
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize recent tunnel sessions",
+	Long: `Summarize the tunnel sessions recorded in ~/.bifrost/metrics.jsonl: how many ran, for how long,
+how often they reconnected and how many ended with an error.
+
+Recording is off by default and never leaves your machine. Turn it on in ~/.bifrost/config.yaml:
+
+  metrics: true
+
+Foreground connects, including --command, are recorded. Background sessions are not.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		if days < 1 {
+			output.Println("Error: --days must be at least 1")
+			os.Exit(1)
+		}
+		since := time.Now().AddDate(0, 0, -days)
+
+		records, err := metrics.Load(since)
+		if err != nil {
+			output.Printf("Error loading metrics: %v\n", err)
+			os.Exit(1)
+		}
+		summary := metrics.Summarize(records)
+
+		if isJSONOutput(cmd) {
+			printJSON(statsOutput{Since: since, Summary: summary, Sessions: records})
+			return
+		}
+
+		if len(records) == 0 {
+			fmt.Printf("No sessions recorded in the last %d days.\n", days)
+			if !metricsEnabled() {
+				output.Println("💡 Recording is off, add 'metrics: true' to ~/.bifrost/config.yaml to turn it on")
+			}
+			return
+		}
+
+		output.Printf("📊 Sessions in the last %d days:\n", days)
+		fmt.Printf("  Sessions: %d\n", summary.Sessions)
+		fmt.Printf("  Total time: %s\n", summary.TotalDuration().Round(time.Second))
+		fmt.Printf("  Average: %s\n", (summary.TotalDuration() / time.Duration(summary.Sessions)).Round(time.Second))
+		fmt.Printf("  Failed: %d (%.0f%%)\n", summary.Failed, summary.FailureRate()*100)
+		fmt.Printf("  Reconnects: %d\n", summary.Reconnects)
+		fmt.Printf("  Keep alive failures: %d\n", summary.KeepAliveFailures)
+		fmt.Println("  By service:")
+		for _, service := range sortedKeys(summary.ByService) {
+			fmt.Printf("    %s: %d\n", service, summary.ByService[service])
+		}
+
+		fmt.Println()
+		output.Println("🕒 Recent sessions:")
+		for i := len(records) - 1; i >= 0 && i >= len(records)-10; i-- {
+			r := records[i]
+			status := "✅"
+			if r.Failed {
+				status = "❌"
+			}
+			fmt.Printf("  %s %s  %-12s %10s  %d reconnects\n", output.Icon(status), r.StartedAt.Local().Format("2006-01-02 15:04"), r.ServiceType, r.Duration().Round(time.Second), r.Reconnects)
+		}
+	},
+}
+
+// metricsEnabled reports whether session metrics are turned on in the global config
+func metricsEnabled() bool {
+	cfg, err := config.NewManager().LoadGlobal()
+	return err == nil && cfg.Metrics
+}
+
+// recordSessionMetrics appends a finished session to the local metrics file when metrics are enabled.
+// err is why the session stopped, nil when it was closed or interrupted.
+func recordSessionMetrics(started time.Time, tunnels *connect.Session, err error) {
+	if !metricsEnabled() {
+		return
+	}
+
+	services := make([]string, 0, len(tunnels.Targets))
+	for _, target := range tunnels.Targets {
+		services = append(services, target.ServiceType)
+	}
+	stats := tunnels.Stats()
+
+	record := metrics.Record{
+		StartedAt:         started,
+		DurationSeconds:   time.Since(started).Seconds(),
+		ServiceType:       strings.Join(services, ","),
+		Targets:           len(tunnels.Targets),
+		Reconnects:        stats.Reconnects,
+		KeepAliveFailures: stats.KeepAliveFailures,
+		Failed:            err != nil,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if err := metrics.Append(record); err != nil {
+		slog.Warn("failed to record session metrics", "error", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Int("days", 30, "Only include sessions started in the last N days")
+}
@@ -0,0 +1,28 @@
+// Package awsretry configures how bifrost's AWS SDK clients retry failed calls
+package awsretry
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// Retry limits, a little more patient than the SDK's defaults of 3 attempts and 20 seconds
+const (
+	MaxAttempts = 5
+	MaxBackoff  = 20 * time.Second
+)
+
+// NewRetryer returns the SDK's standard retryer with bifrost's limits. It retries throttling
+// (e.g. TooManyRequestsException), 5xx and connection errors with jittered exponential backoff,
+// and gives up as soon as the call's context is cancelled.
+func NewRetryer() aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = MaxAttempts
+		o.MaxBackoff = MaxBackoff
+		// The client side retry quota runs out during bursts of throttling, just when retries are needed
+		o.RateLimiter = ratelimit.None
+	})
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+// DefaultTTL is how long listed AWS resources are reused before being fetched again
+const DefaultTTL = 60 * time.Second
+
+// entry is the on-disk form of a cached value
+type entry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Key builds a cache key for a kind of resource listed in an account and region
+func Key(accountID, region, kind string) string {
+	return strings.Join([]string{accountID, region, kind}, "_")
+}
+
+func getCachePath(key string) (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, key+".json"), nil
+}
+
+// Fetch returns the cached value for key if it is younger than ttl, otherwise it calls fetch and caches the result.
+// A ttl of zero or less always calls fetch. Cache read and write failures are ignored, the cache is only a shortcut.
+func Fetch[T any](key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	path, pathErr := getCachePath(key)
+
+	if ttl > 0 && pathErr == nil {
+		if value, ok := load[T](path, ttl); ok {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	if pathErr == nil {
+		_ = save(path, value)
+	}
+	return value, nil
+}
+
+func load[T any](path string, ttl time.Duration) (T, bool) {
+	var value T
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return value, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return value, false
+	}
+	if time.Since(cached.FetchedAt) > ttl {
+		return value, false
+	}
+
+	if err := json.Unmarshal(cached.Value, &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
+
+func save(path string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry{FetchedAt: time.Now(), Value: raw})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
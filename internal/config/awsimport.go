@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AWSSSOSession is an SSO start URL and region found in the AWS CLI config, either in an
+// [sso-session] block or in a profile with the legacy sso_start_url/sso_region keys
+type AWSSSOSession struct {
+	Name     string
+	Section  string
+	StartURL string
+	Region   string
+}
+
+// SSOProfile returns the session as a bifrost SSO profile
+func (s AWSSSOSession) SSOProfile() SSOProfile {
+	return SSOProfile{StartURL: s.StartURL, SSORegion: s.Region}
+}
+
+// AWSConfigPath returns the AWS CLI config file, honouring AWS_CONFIG_FILE like the CLI does
+func AWSConfigPath() (string, error) {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".aws", "config"), nil
+}
+
+// ReadAWSSSOSessions lists the SSO start URLs configured in an AWS CLI config file. Sessions come
+// first, in file order, followed by legacy SSO profiles whose start URL no session already covers.
+// Profiles pointing at a session with sso_session are covered by that session.
+func ReadAWSSSOSessions(path string) ([]AWSSSOSession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS config: %w", err)
+	}
+	defer file.Close()
+
+	var sessions, profiles []AWSSSOSession
+	var current *AWSSSOSession
+	var isSession bool
+	flush := func() {
+		if current == nil || current.StartURL == "" {
+			return
+		}
+		if isSession {
+			sessions = append(sessions, *current)
+		} else {
+			profiles = append(profiles, *current)
+		}
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		// Indented lines continue a nested value such as s3 settings, never an SSO key
+		if line == "" || line[0] == '#' || line[0] == ';' || raw[0] == ' ' || raw[0] == '\t' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			header := strings.Fields(strings.Trim(line, "[]"))
+			current, isSession = nil, false
+			switch {
+			case len(header) == 2 && header[0] == "sso-session":
+				current, isSession = &AWSSSOSession{Name: header[1], Section: line}, true
+			case len(header) == 2 && header[0] == "profile":
+				current = &AWSSSOSession{Name: header[1], Section: line}
+			case len(header) == 1 && header[0] == "default":
+				current = &AWSSSOSession{Name: "default", Section: line}
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || current == nil {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "sso_start_url":
+			current.StartURL = strings.TrimSpace(value)
+		case "sso_region":
+			current.Region = strings.TrimSpace(value)
+		case "sso_session":
+			// The session block holds the URL, a stray legacy key next to it must not import twice
+			if !isSession {
+				current.StartURL = ""
+				current = nil
+			}
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read AWS config: %w", err)
+	}
+
+	seen := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		seen[session.StartURL] = true
+	}
+	for _, profile := range profiles {
+		if !seen[profile.StartURL] {
+			seen[profile.StartURL] = true
+			sessions = append(sessions, profile)
+		}
+	}
+	return sessions, nil
+}
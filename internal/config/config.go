@@ -4,34 +4,148 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/b3nk3/bifrost/internal/ui/output"
 	"github.com/spf13/viper"
 )
 
 // SSOProfile represents SSO authentication configuration
 type SSOProfile struct {
-	StartURL  string `yaml:"sso_url" mapstructure:"sso_url"`
-	SSORegion string `yaml:"sso_region" mapstructure:"sso_region"`
+	StartURL  string `yaml:"sso_url" json:"sso_url" mapstructure:"sso_url"`
+	SSORegion string `yaml:"sso_region" json:"sso_region" mapstructure:"sso_region"`
+	// Description tells SSO profiles apart in prompts, e.g. "Company Production SSO"
+	Description string `yaml:"description,omitempty" json:"description,omitempty" mapstructure:"description"`
+	// DefaultRegion is used by connect when neither a flag nor the connection profile sets a region
+	DefaultRegion string `yaml:"default_region,omitempty" json:"default_region,omitempty" mapstructure:"default_region"`
+	// DefaultService and DefaultPort are used by connect when neither a flag nor a connection profile
+	// sets them, e.g. when an SSO environment mostly hosts one kind of datastore
+	DefaultService string `yaml:"default_service,omitempty" json:"default_service,omitempty" mapstructure:"default_service"`
+	DefaultPort    string `yaml:"default_port,omitempty" json:"default_port,omitempty" mapstructure:"default_port"`
+	// ClientName and Scopes override how the OIDC client is registered for device logins
+	ClientName string   `yaml:"client_name,omitempty" json:"client_name,omitempty" mapstructure:"client_name"`
+	Scopes     []string `yaml:"scopes,omitempty" json:"scopes,omitempty" mapstructure:"scopes"`
+}
+
+// Label is how the SSO profile called name is shown in selection prompts, with its description if it has one
+func (s SSOProfile) Label(name string) string {
+	if s.Description == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, s.Description)
+}
+
+// Equal reports whether both SSO profiles sign in the same way
+func (s SSOProfile) Equal(other SSOProfile) bool {
+	return s.StartURL == other.StartURL &&
+		s.SSORegion == other.SSORegion &&
+		s.ClientName == other.ClientName &&
+		slices.Equal(s.Scopes, other.Scopes)
 }
 
 // ConnectionProfile represents a connection configuration
 type ConnectionProfile struct {
-	SSOProfile       string `yaml:"sso_profile,omitempty" mapstructure:"sso_profile"`
-	AccountID        string `yaml:"account_id,omitempty" mapstructure:"account_id"`
-	RoleName         string `yaml:"role_name,omitempty" mapstructure:"role_name"`
-	Region           string `yaml:"region,omitempty" mapstructure:"region"`
-	ServiceType      string `yaml:"service,omitempty" mapstructure:"service"`
-	Port             string `yaml:"port,omitempty" mapstructure:"port"`
-	BastionInstanceID string `yaml:"bastion_instance_id,omitempty" mapstructure:"bastion_instance_id"`
-	RDSInstanceName  string `yaml:"rds_instance_name,omitempty" mapstructure:"rds_instance_name"`
-	RedisClusterName string `yaml:"redis_cluster_name,omitempty" mapstructure:"redis_cluster_name"`
+	AWSProfile          string       `yaml:"aws_profile,omitempty" json:"aws_profile,omitempty" mapstructure:"aws_profile"`
+	SSOProfile          string       `yaml:"sso_profile,omitempty" json:"sso_profile,omitempty" mapstructure:"sso_profile"`
+	AccountID           string       `yaml:"account_id,omitempty" json:"account_id,omitempty" mapstructure:"account_id"`
+	AccountName         string       `yaml:"account_name,omitempty" json:"account_name,omitempty" mapstructure:"account_name"`
+	RoleName            string       `yaml:"role_name,omitempty" json:"role_name,omitempty" mapstructure:"role_name"`
+	Region              string       `yaml:"region,omitempty" json:"region,omitempty" mapstructure:"region"`
+	CandidateRegions    []string     `yaml:"candidate_regions,omitempty" json:"candidate_regions,omitempty" mapstructure:"candidate_regions"`
+	Environment         string       `yaml:"environment,omitempty" json:"environment,omitempty" mapstructure:"environment"`
+	ServiceType         string       `yaml:"service,omitempty" json:"service,omitempty" mapstructure:"service"`
+	Port                string       `yaml:"port,omitempty" json:"port,omitempty" mapstructure:"port"`
+	AssumeRoleARN       string       `yaml:"assume_role_arn,omitempty" json:"assume_role_arn,omitempty" mapstructure:"assume_role_arn"`
+	ExternalID          string       `yaml:"external_id,omitempty" json:"external_id,omitempty" mapstructure:"external_id"`
+	BastionInstanceID   string       `yaml:"bastion_instance_id,omitempty" json:"bastion_instance_id,omitempty" mapstructure:"bastion_instance_id"`
+	RDSInstanceName     string       `yaml:"rds_instance_name,omitempty" json:"rds_instance_name,omitempty" mapstructure:"rds_instance_name"`
+	RedisClusterName    string       `yaml:"redis_cluster_name,omitempty" json:"redis_cluster_name,omitempty" mapstructure:"redis_cluster_name"`
+	DocumentDBCluster   string       `yaml:"documentdb_cluster,omitempty" json:"documentdb_cluster,omitempty" mapstructure:"documentdb_cluster"`
+	NeptuneCluster      string       `yaml:"neptune_cluster,omitempty" json:"neptune_cluster,omitempty" mapstructure:"neptune_cluster"`
+	OpenSearchDomain    string       `yaml:"opensearch_domain,omitempty" json:"opensearch_domain,omitempty" mapstructure:"opensearch_domain"`
+	MSKCluster          string       `yaml:"msk_cluster,omitempty" json:"msk_cluster,omitempty" mapstructure:"msk_cluster"`
+	CustomEndpoint      string       `yaml:"custom_endpoint,omitempty" json:"custom_endpoint,omitempty" mapstructure:"custom_endpoint"`
+	SSMDocument         string       `yaml:"ssm_document,omitempty" json:"ssm_document,omitempty" mapstructure:"ssm_document"`
+	SSMParameters       string       `yaml:"ssm_parameters,omitempty" json:"ssm_parameters,omitempty" mapstructure:"ssm_parameters"`
+	CredentialSecretARN string       `yaml:"credential_secret_arn,omitempty" json:"credential_secret_arn,omitempty" mapstructure:"credential_secret_arn"`
+	DBUser              string       `yaml:"db_user,omitempty" json:"db_user,omitempty" mapstructure:"db_user"`
+	DBName              string       `yaml:"db_name,omitempty" json:"db_name,omitempty" mapstructure:"db_name"`
+	Targets             []TargetSpec `yaml:"targets,omitempty" json:"targets,omitempty" mapstructure:"targets"`
 }
 
+// EnvironmentProduction is the environment name that requires confirmation before connecting
+const EnvironmentProduction = "prd"
+
+// IsProduction reports whether the profile is tagged with the production environment
+func (p ConnectionProfile) IsProduction() bool {
+	return p.Environment == EnvironmentProduction
+}
+
+// ResourceName returns the stored resource identifier for the given service type
+func (p ConnectionProfile) ResourceName(serviceType string) string {
+	switch serviceType {
+	case "rds":
+		return p.RDSInstanceName
+	case "redis":
+		return p.RedisClusterName
+	case "documentdb":
+		return p.DocumentDBCluster
+	case "neptune":
+		return p.NeptuneCluster
+	case "opensearch":
+		return p.OpenSearchDomain
+	case "kafka":
+		return p.MSKCluster
+	case "custom":
+		return p.CustomEndpoint
+	}
+	return ""
+}
+
+// SetResourceName stores the resource identifier in the field for the given service type
+func (p *ConnectionProfile) SetResourceName(serviceType, name string) {
+	switch serviceType {
+	case "rds":
+		p.RDSInstanceName = name
+	case "redis":
+		p.RedisClusterName = name
+	case "documentdb":
+		p.DocumentDBCluster = name
+	case "neptune":
+		p.NeptuneCluster = name
+	case "opensearch":
+		p.OpenSearchDomain = name
+	case "kafka":
+		p.MSKCluster = name
+	case "custom":
+		p.CustomEndpoint = name
+	}
+}
+
+// TargetSpec represents one tunnel of a multi-target connection profile
+type TargetSpec struct {
+	ServiceType  string `yaml:"service" json:"service" mapstructure:"service"`
+	Port         string `yaml:"port" json:"port" mapstructure:"port"`
+	ResourceName string `yaml:"resource_name" json:"resource_name" mapstructure:"resource_name"`
+}
 
 // Config represents the application configuration
 type Config struct {
+	Version            int                          `yaml:"version" mapstructure:"version"`
 	SSOProfiles        map[string]SSOProfile        `yaml:"sso_profiles" mapstructure:"sso_profiles"`
 	ConnectionProfiles map[string]ConnectionProfile `yaml:"connection_profiles" mapstructure:"connection_profiles"`
+	ProtectedAccounts  []string                     `yaml:"protected_accounts,omitempty" mapstructure:"protected_accounts"`
+	Metrics            bool                         `yaml:"metrics,omitempty" mapstructure:"metrics"`
+	CABundle           string                       `yaml:"ca_bundle,omitempty" mapstructure:"ca_bundle"`
+	// TokenLifetime overrides how long SSO tokens are cached for, ExpirySkew stops using them that much sooner
+	TokenLifetime time.Duration `yaml:"token_lifetime,omitempty" mapstructure:"token_lifetime"`
+	ExpirySkew    time.Duration `yaml:"expiry_skew,omitempty" mapstructure:"expiry_skew"`
+}
+
+// IsProtectedAccount reports whether connecting to the account needs explicit confirmation
+func (c *Config) IsProtectedAccount(accountID string) bool {
+	return slices.Contains(c.ProtectedAccounts, accountID)
 }
 
 // Manager handles configuration operations
@@ -46,18 +160,75 @@ func NewManager() *Manager {
 	return &Manager{viper: v}
 }
 
+// GetBifrostDir returns ~/.bifrost, creating it if needed
+func GetBifrostDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(homeDir, ".bifrost")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// LocalConfigFile is the name of the project config holding local connection profiles
+const LocalConfigFile = ".bifrost.config.yaml"
+
+// localConfigPath is the local config chosen with --local-config, empty to discover it
+var localConfigPath string
+
+// SetLocalConfigPath uses path as the local config instead of discovering it
+func SetLocalConfigPath(path string) {
+	localConfigPath = path
+}
+
+// LocalConfigPath returns the local config in use: the one set with SetLocalConfigPath, else the nearest
+// .bifrost.config.yaml in the current directory or its parents (like git finds .git). Without one,
+// it is .bifrost.config.yaml in the current directory, where a new local config gets created.
+func LocalConfigPath() string {
+	if localConfigPath != "" {
+		return localConfigPath
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return LocalConfigFile
+	}
+	for {
+		candidate := filepath.Join(dir, LocalConfigFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return LocalConfigFile
+		}
+		dir = parent
+	}
+}
+
 // LocalConfig represents local project configuration (connection profiles only)
 type LocalConfig struct {
 	ConnectionProfiles map[string]ConnectionProfile `yaml:"connection_profiles" mapstructure:"connection_profiles"`
 }
 
-// Load loads the configuration from disk, merging global SSO profiles with local connection profiles
+// Load loads the configuration from disk, merging global SSO profiles with local connection profiles.
+// Connection profiles are as written, environment variables included, see GetConnectionProfile.
 func (m *Manager) Load() (*Config, error) {
 	config := &Config{
 		SSOProfiles:        make(map[string]SSOProfile),
 		ConnectionProfiles: make(map[string]ConnectionProfile),
 	}
 
+	// Upgrade older config files before reading them
+	if err := m.Migrate(); err != nil {
+		return nil, err
+	}
+
 	// Load global SSO profiles
 	if err := m.loadGlobalConfig(config); err != nil {
 		return nil, fmt.Errorf("failed to load global config: %w", err)
@@ -71,6 +242,24 @@ func (m *Manager) Load() (*Config, error) {
 	return config, nil
 }
 
+// LoadGlobal loads only ~/.bifrost/config.yaml, without merging local connection profiles.
+// Use it when the result is written back with SaveGlobal.
+func (m *Manager) LoadGlobal() (*Config, error) {
+	if err := m.Migrate(); err != nil {
+		return nil, err
+	}
+
+	config := &Config{
+		SSOProfiles:        make(map[string]SSOProfile),
+		ConnectionProfiles: make(map[string]ConnectionProfile),
+	}
+	if err := m.loadGlobalConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	return config, nil
+}
+
 // loadGlobalConfig loads SSO profiles and global connection profiles from ~/.bifrost/config.yaml
 func (m *Manager) loadGlobalConfig(config *Config) error {
 	homeDir, err := os.UserHomeDir()
@@ -88,9 +277,10 @@ func (m *Manager) loadGlobalConfig(config *Config) error {
 	// Check if file exists
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		// Create empty file with basic structure
-		initialConfig := `sso_profiles: {}
+		initialConfig := fmt.Sprintf(`version: %d
+sso_profiles: {}
 connection_profiles: {}
-`
+`, CurrentVersion)
 		if err := os.WriteFile(configFile, []byte(initialConfig), 0644); err != nil {
 			return fmt.Errorf("failed to create config file: %w", err)
 		}
@@ -111,10 +301,10 @@ connection_profiles: {}
 	return nil
 }
 
-// loadLocalConfig loads connection profiles from .bifrost.config.yaml in current directory
+// loadLocalConfig loads connection profiles from the local config, see LocalConfigPath
 func (m *Manager) loadLocalConfig(config *Config) error {
-	localConfigFile := ".bifrost.config.yaml"
-	
+	localConfigFile := LocalConfigPath()
+
 	// Check if local config exists
 	if _, err := os.Stat(localConfigFile); os.IsNotExist(err) {
 		return nil // No local config is fine
@@ -162,15 +352,31 @@ func (m *Manager) SaveGlobal(config *Config) error {
 	globalViper := viper.New()
 	globalViper.SetConfigType("yaml")
 	globalViper.SetConfigFile(configFile)
+	globalViper.Set("version", CurrentVersion)
 	globalViper.Set("sso_profiles", config.SSOProfiles)
 	globalViper.Set("connection_profiles", config.ConnectionProfiles)
+	if len(config.ProtectedAccounts) > 0 {
+		globalViper.Set("protected_accounts", config.ProtectedAccounts)
+	}
+	if config.Metrics {
+		globalViper.Set("metrics", true)
+	}
+	if config.CABundle != "" {
+		globalViper.Set("ca_bundle", config.CABundle)
+	}
+	if config.TokenLifetime > 0 {
+		globalViper.Set("token_lifetime", config.TokenLifetime.String())
+	}
+	if config.ExpirySkew > 0 {
+		globalViper.Set("expiry_skew", config.ExpirySkew.String())
+	}
 
 	return globalViper.WriteConfig()
 }
 
-// SaveLocal saves connection profiles to .bifrost.config.yaml in current directory
+// SaveLocal saves connection profiles to the local config, see LocalConfigPath
 func (m *Manager) SaveLocal(connectionProfiles map[string]ConnectionProfile) error {
-	localConfigFile := ".bifrost.config.yaml"
+	localConfigFile := LocalConfigPath()
 
 	localConfig := &LocalConfig{
 		ConnectionProfiles: connectionProfiles,
@@ -186,22 +392,22 @@ func (m *Manager) SaveLocal(connectionProfiles map[string]ConnectionProfile) err
 
 // AddSSOProfile adds or updates an SSO profile
 func (m *Manager) AddSSOProfile(name string, profile SSOProfile) error {
-	config, err := m.Load()
+	config, err := m.LoadGlobal()
 	if err != nil {
 		return err
 	}
-	
+
 	config.SSOProfiles[name] = profile
 	return m.Save(config)
 }
 
-// AddConnectionProfile adds or updates a connection profile
+// AddConnectionProfile adds or updates a connection profile in global config
 func (m *Manager) AddConnectionProfile(name string, profile ConnectionProfile) error {
-	config, err := m.Load()
+	config, err := m.LoadGlobal()
 	if err != nil {
 		return err
 	}
-	
+
 	config.ConnectionProfiles[name] = profile
 	return m.Save(config)
 }
@@ -210,28 +416,28 @@ func (m *Manager) AddConnectionProfile(name string, profile ConnectionProfile) e
 func (m *Manager) AddLocalConnectionProfile(name string, profile ConnectionProfile) error {
 	// Load existing local config
 	localProfiles := make(map[string]ConnectionProfile)
-	
+
 	// Try to load existing local config
-	localConfigFile := ".bifrost.config.yaml"
+	localConfigFile := LocalConfigPath()
 	if _, err := os.Stat(localConfigFile); err == nil {
 		localConfig := &LocalConfig{ConnectionProfiles: make(map[string]ConnectionProfile)}
 		localViper := viper.New()
 		localViper.SetConfigType("yaml")
 		localViper.SetConfigFile(localConfigFile)
-		
+
 		if err := localViper.ReadInConfig(); err == nil {
 			if err := localViper.Unmarshal(localConfig); err != nil {
 				// Log error but continue - local config is optional
-				fmt.Printf("Warning: failed to unmarshal local config: %v\n", err)
+				output.Printf("Warning: failed to unmarshal local config: %v\n", err)
 			} else {
 				localProfiles = localConfig.ConnectionProfiles
 			}
 		}
 	}
-	
+
 	// Add/update the profile
 	localProfiles[name] = profile
-	
+
 	// Save to local config
 	return m.SaveLocal(localProfiles)
 }
@@ -242,13 +448,13 @@ func (m *Manager) GetDefaultSSOProfile() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	
+
 	if len(config.SSOProfiles) == 1 {
 		for name := range config.SSOProfiles {
 			return name, nil
 		}
 	}
-	
+
 	return "", nil
 }
 
@@ -258,26 +464,76 @@ func (m *Manager) GetSSOProfile(name string) (*SSOProfile, error) {
 	if err != nil {
 		return nil, err
 	}
-	
-	profile, exists := config.SSOProfiles[name]
+	return config.SSOProfile(name)
+}
+
+// SSOProfile returns the SSO profile of the loaded config with the name
+func (c *Config) SSOProfile(name string) (*SSOProfile, error) {
+	profile, exists := c.SSOProfiles[name]
 	if !exists {
 		return nil, fmt.Errorf("SSO profile '%s' not found", name)
 	}
-	
+
 	return &profile, nil
 }
 
-// GetConnectionProfile retrieves a connection profile by name
+// Locations a connection profile can be stored in
+const (
+	LocationLocal  = "local"
+	LocationGlobal = "global"
+)
+
+// ConnectionProfilesByLocation loads the connection profiles of the global and the local config
+// separately. Unlike Load, a local profile doesn't hide the global one of the same name.
+func (m *Manager) ConnectionProfilesByLocation() (global, local map[string]ConnectionProfile, err error) {
+	localConfig := &Config{ConnectionProfiles: make(map[string]ConnectionProfile)}
+	if err := m.loadLocalConfig(localConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to load local config: %w", err)
+	}
+
+	globalConfig := &Config{
+		SSOProfiles:        make(map[string]SSOProfile),
+		ConnectionProfiles: make(map[string]ConnectionProfile),
+	}
+	if err := m.loadGlobalConfig(globalConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	return globalConfig.ConnectionProfiles, localConfig.ConnectionProfiles, nil
+}
+
+// GetConnectionProfileLocation reports whether a connection profile lives in local or global config.
+// Local wins when both define the same name, matching Load.
+func (m *Manager) GetConnectionProfileLocation(name string) (string, error) {
+	global, local, err := m.ConnectionProfilesByLocation()
+	if err != nil {
+		return "", err
+	}
+	if _, exists := local[name]; exists {
+		return LocationLocal, nil
+	}
+	if _, exists := global[name]; exists {
+		return LocationGlobal, nil
+	}
+
+	return "", fmt.Errorf("connection profile '%s' not found", name)
+}
+
+// GetConnectionProfile retrieves a connection profile by name, with its environment variables expanded
 func (m *Manager) GetConnectionProfile(name string) (*ConnectionProfile, error) {
 	config, err := m.Load()
 	if err != nil {
 		return nil, err
 	}
-	
+
 	profile, exists := config.ConnectionProfiles[name]
 	if !exists {
 		return nil, fmt.Errorf("connection profile '%s' not found", name)
 	}
-	
+	profile, err = profile.Expand()
+	if err != nil {
+		return nil, fmt.Errorf("connection profile '%s' %w", name, err)
+	}
+
 	return &profile, nil
 }
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fullProfile is a connection profile with every field set
+func fullProfile() ConnectionProfile {
+	return ConnectionProfile{
+		AWSProfile:          "legacy",
+		SSOProfile:          "company",
+		AccountID:           "123456789012",
+		AccountName:         "production",
+		RoleName:            "DatabaseAccess",
+		Region:              "eu-west-1",
+		CandidateRegions:    []string{"eu-west-1", "eu-central-1"},
+		Environment:         EnvironmentProduction,
+		ServiceType:         "rds",
+		Port:                "5432",
+		AssumeRoleARN:       "arn:aws:iam::210987654321:role/Target",
+		ExternalID:          "bifrost-test",
+		BastionInstanceID:   "i-0123456789abcdef0",
+		RDSInstanceName:     "orders",
+		RedisClusterName:    "sessions",
+		DocumentDBCluster:   "catalog",
+		NeptuneCluster:      "graph",
+		OpenSearchDomain:    "search",
+		MSKCluster:          "events",
+		CustomEndpoint:      "internal.example.com:8443",
+		SSMDocument:         "AWS-StartPortForwardingSessionToRemoteHost",
+		SSMParameters:       `{"host":["{{host}}"]}`,
+		CredentialSecretARN: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:orders",
+		DBUser:              "app",
+		DBName:              "orders",
+		Targets: []TargetSpec{
+			{ServiceType: "rds", Port: "5432", ResourceName: "orders"},
+			{ServiceType: "redis", Port: "6379", ResourceName: "sessions"},
+		},
+	}
+}
+
+// useTempConfig points the global and local config at a temporary directory for the test
+func useTempConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	SetLocalConfigPath(filepath.Join(dir, LocalConfigFile))
+	t.Cleanup(func() { SetLocalConfigPath("") })
+}
+
+func TestFullProfileSetsEveryField(t *testing.T) {
+	profile := reflect.ValueOf(fullProfile())
+	for i := range profile.NumField() {
+		if profile.Field(i).IsZero() {
+			t.Errorf("fullProfile doesn't set %s, so the round-trip tests can't tell it is saved", profile.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestConnectionProfileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		save func(m *Manager, name string, profile ConnectionProfile) error
+	}{
+		{name: "global", save: (*Manager).AddConnectionProfile},
+		{name: "local", save: (*Manager).AddLocalConnectionProfile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempConfig(t)
+			want := fullProfile()
+
+			if err := tt.save(NewManager(), "prod-rds", want); err != nil {
+				t.Fatalf("saving profile: %v", err)
+			}
+			got, err := NewManager().GetConnectionProfile("prod-rds")
+			if err != nil {
+				t.Fatalf("reloading profile: %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("reloaded profile differs\ngot:  %+v\nwant: %+v", *got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// expandableField is a connection profile setting whose value may reference environment variables
+type expandableField struct {
+	name  string
+	value *string
+}
+
+// expandableFields lists the settings of a profile that may reference environment variables: the
+// accounts, roles, AWS resources and databases it connects to. SSM parameters are left alone, they
+// are a {{host}} template handed to the SSM document as written.
+func (p *ConnectionProfile) expandableFields() []expandableField {
+	fields := []expandableField{
+		{"aws_profile", &p.AWSProfile},
+		{"sso_profile", &p.SSOProfile},
+		{"account_id", &p.AccountID},
+		{"account_name", &p.AccountName},
+		{"role_name", &p.RoleName},
+		{"region", &p.Region},
+		{"port", &p.Port},
+		{"assume_role_arn", &p.AssumeRoleARN},
+		{"external_id", &p.ExternalID},
+		{"bastion_instance_id", &p.BastionInstanceID},
+		{"rds_instance_name", &p.RDSInstanceName},
+		{"redis_cluster_name", &p.RedisClusterName},
+		{"documentdb_cluster", &p.DocumentDBCluster},
+		{"neptune_cluster", &p.NeptuneCluster},
+		{"opensearch_domain", &p.OpenSearchDomain},
+		{"msk_cluster", &p.MSKCluster},
+		{"custom_endpoint", &p.CustomEndpoint},
+		{"credential_secret_arn", &p.CredentialSecretARN},
+		{"db_user", &p.DBUser},
+		{"db_name", &p.DBName},
+	}
+	for i := range p.Targets {
+		fields = append(fields,
+			expandableField{fmt.Sprintf("targets[%d].port", i), &p.Targets[i].Port},
+			expandableField{fmt.Sprintf("targets[%d].resource_name", i), &p.Targets[i].ResourceName},
+		)
+	}
+	return fields
+}
+
+// Expand returns the profile with ${VAR} and $VAR replaced in its expandable fields, so a shared
+// config can say e.g. account_id: ${PROD_ACCOUNT_ID}. A variable that isn't set is an error rather
+// than an empty value, which would only fail later and more confusingly. Profiles are only expanded
+// when used, so one referencing an unset variable doesn't get in the way of the others.
+func (p ConnectionProfile) Expand() (ConnectionProfile, error) {
+	// Targets are shared with the caller's copy of the profile, so expand a copy of them
+	p.Targets = slices.Clone(p.Targets)
+	for _, field := range p.expandableFields() {
+		expanded, err := expandValue(*field.value)
+		if err != nil {
+			return ConnectionProfile{}, fmt.Errorf("%s: %w", field.name, err)
+		}
+		*field.value = expanded
+	}
+	return p, nil
+}
+
+// expandValue expands the environment variables referenced in value, failing if any isn't set
+func expandValue(value string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+	var missing []string
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	switch len(missing) {
+	case 0:
+		return expanded, nil
+	case 1:
+		return "", fmt.Errorf("environment variable %s is not set", missing[0])
+	}
+	return "", fmt.Errorf("environment variables %s are not set", strings.Join(missing, ", "))
+}
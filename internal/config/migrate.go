@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/spf13/viper"
+)
+
+// CurrentVersion is the config schema version written by this build
+const CurrentVersion = 1
+
+// migrations upgrade a config one schema version at a time; index i moves version i to i+1
+var migrations = []func(v *viper.Viper) error{
+	migrateV0ToV1,
+}
+
+// migrateV0ToV1 upgrades unversioned configs. Profiles written before the schema was
+// versioned already use the current keys, so the only change is stamping the version.
+func migrateV0ToV1(v *viper.Viper) error {
+	return nil
+}
+
+// Migrate upgrades ~/.bifrost/config.yaml to the current schema version and saves it.
+// A notice is printed when the file is rewritten, so it only appears once per upgrade.
+func (m *Manager) Migrate() error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	configFile := filepath.Join(homeDir, ".bifrost", "config.yaml")
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return nil // Nothing to migrate, a fresh file is created at the current version
+	}
+
+	globalViper := viper.New()
+	globalViper.SetConfigType("yaml")
+	globalViper.SetConfigFile(configFile)
+
+	if err := globalViper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	version := globalViper.GetInt("version")
+	if version > CurrentVersion {
+		return fmt.Errorf("config version %d is newer than this version of bifrost supports (%d), please upgrade bifrost", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return nil
+	}
+
+	from := version
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](globalViper); err != nil {
+			return fmt.Errorf("failed to migrate config from version %d: %w", version, err)
+		}
+	}
+
+	globalViper.Set("version", CurrentVersion)
+	if err := globalViper.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to save migrated config: %w", err)
+	}
+
+	output.Fprintf(os.Stderr, "ℹ️  Migrated %s from config version %d to %d\n", configFile, from, CurrentVersion)
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Problem is one mistake found in a config file
+type Problem struct {
+	File    string `json:"file"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Key, p.Message)
+}
+
+// keySuggestions maps keys people commonly type, e.g. from ~/.aws/config, to the ones bifrost reads
+var keySuggestions = map[string]string{
+	"start_url":     "sso_url",
+	"sso_start_url": "sso_url",
+	"service_type":  "service",
+	"account":       "account_id",
+	"role":          "role_name",
+	"bastion":       "bastion_instance_id",
+	"local_port":    "port",
+}
+
+var (
+	// Unknown keys are reported by the decoder as "'<path>' has invalid keys: a, b"
+	invalidKeysPattern = regexp.MustCompile(`^'([^']*)' has invalid keys: (.+)$`)
+	// Other decode errors start with the quoted path of the offending key
+	quotedKeyPattern = regexp.MustCompile(`^'([^']*)' (.+)$`)
+	// Map keys appear in decoder paths as [name], list indexes as [0]
+	mapKeyPattern = regexp.MustCompile(`\[([^\]]*[^0-9\]][^\]]*)\]`)
+)
+
+// GlobalConfigPath returns the path of ~/.bifrost/config.yaml
+func GlobalConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".bifrost", "config.yaml"), nil
+}
+
+// ValidateFile reads a config file strictly and reports anything bifrost would ignore or fail on:
+// YAML syntax errors, unknown keys and values of the wrong type. Global configs (with sso_profiles
+// or version at the top level) are checked against the global schema, others as local configs.
+// The parsed config is returned for further checks, nil if the file couldn't be read.
+func ValidateFile(path string) (*Config, []Problem) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, []Problem{{File: path, Message: err.Error()}}
+	}
+
+	cfg := &Config{
+		SSOProfiles:        make(map[string]SSOProfile),
+		ConnectionProfiles: make(map[string]ConnectionProfile),
+	}
+	var err error
+	if v.IsSet("sso_profiles") || v.IsSet("version") {
+		err = v.UnmarshalExact(cfg)
+	} else {
+		local := &LocalConfig{ConnectionProfiles: cfg.ConnectionProfiles}
+		err = v.UnmarshalExact(local)
+		cfg.ConnectionProfiles = local.ConnectionProfiles
+	}
+	if err == nil {
+		return cfg, nil
+	}
+
+	var problems []Problem
+	for _, leaf := range leafErrors(err) {
+		problems = append(problems, decodeProblems(path, leaf)...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Key < problems[j].Key
+	})
+
+	// The strict decode may stop part way, read leniently so the remaining checks see every profile
+	if v.Unmarshal(cfg) != nil {
+		return nil, problems
+	}
+	return cfg, problems
+}
+
+// ValidateReferences reports connection profiles in cfg whose sso_profile isn't one of ssoProfiles
+func ValidateReferences(path string, cfg *Config, ssoProfiles map[string]SSOProfile) []Problem {
+	names := make([]string, 0, len(cfg.ConnectionProfiles))
+	for name := range cfg.ConnectionProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []Problem
+	for _, name := range names {
+		// Variables may only be set where the profile is used, e.g. not in CI, so they aren't a problem
+		profile, err := cfg.ConnectionProfiles[name].Expand()
+		if err != nil {
+			continue
+		}
+		ssoProfile := profile.SSOProfile
+		if _, exists := ssoProfiles[ssoProfile]; ssoProfile != "" && !exists {
+			problems = append(problems, Problem{
+				File:    path,
+				Key:     fmt.Sprintf("connection_profiles.%s.sso_profile", name),
+				Message: fmt.Sprintf("SSO profile '%s' doesn't exist", ssoProfile),
+			})
+		}
+	}
+	return problems
+}
+
+// leafErrors flattens the joined errors the decoder returns
+func leafErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		var leaves []error
+		for _, inner := range joined.Unwrap() {
+			leaves = append(leaves, leafErrors(inner)...)
+		}
+		return leaves
+	}
+	return []error{err}
+}
+
+// decodeProblems turns one decoder error into a problem per key it names
+func decodeProblems(path string, err error) []Problem {
+	message := err.Error()
+	if matches := invalidKeysPattern.FindStringSubmatch(message); matches != nil {
+		var problems []Problem
+		for _, key := range strings.Split(matches[2], ", ") {
+			problem := Problem{File: path, Key: joinKey(matches[1], key), Message: "unknown key"}
+			if suggestion, ok := keySuggestions[key]; ok {
+				problem.Message = fmt.Sprintf("unknown key, did you mean '%s'?", suggestion)
+			}
+			problems = append(problems, problem)
+		}
+		return problems
+	}
+	if matches := quotedKeyPattern.FindStringSubmatch(message); matches != nil {
+		return []Problem{{File: path, Key: displayKey(matches[1]), Message: matches[2]}}
+	}
+	return []Problem{{File: path, Message: message}}
+}
+
+// joinKey appends key to a decoder path such as connection_profiles[dev].targets[0]
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return displayKey(parent) + "." + key
+}
+
+// displayKey writes decoder paths the way they look in YAML, e.g. connection_profiles.dev.targets[0]
+func displayKey(path string) string {
+	return mapKeyPattern.ReplaceAllString(path, ".$1")
+}
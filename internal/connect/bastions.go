@@ -0,0 +1,199 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// instanceIDPattern matches EC2 instance IDs (i-) and hybrid managed node IDs (mi-)
+var instanceIDPattern = regexp.MustCompile(`^(i|mi)-[0-9a-f]{8,17}$`)
+
+// ValidateInstanceID checks a bastion ID looks like an EC2 instance or SSM managed node ID
+func ValidateInstanceID(id string) error {
+	if !instanceIDPattern.MatchString(id) {
+		return fmt.Errorf("'%s' is not an instance ID, expected i- or mi- followed by 8 to 17 hex characters (e.g. i-1234567890abcdef0)", id)
+	}
+	return nil
+}
+
+// List all SSM managed instances that can be used as bastion hosts, with the VPC of each EC2 instance by ID
+func ListSSMManagedInstances(ctx context.Context, cfg aws.Config) (instances []string, ids map[string]string, vpcs map[string]string, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	ssmSvc := ssm.NewFromConfig(cfg)
+	ec2Svc := ec2.NewFromConfig(cfg)
+
+	// Fetch SSM managed instances and EC2 Name tags concurrently
+	var managed []types.InstanceInformation
+	names := make(map[string]string)
+	vpcs = make(map[string]string)
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		paginator := ssm.NewDescribeInstanceInformationPaginator(ssmSvc, &ssm.DescribeInstanceInformationInput{})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(groupCtx)
+			if err != nil {
+				return fmt.Errorf("failed to list SSM managed instances: %w", err)
+			}
+			managed = append(managed, page.InstanceInformationList...)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		// If the EC2 call fails, instances are shown by ID without names or VPCs
+		paginator := ec2.NewDescribeInstancesPaginator(ec2Svc, &ec2.DescribeInstancesInput{
+			Filters: []ec2types.Filter{
+				{Name: aws.String("instance-state-name"), Values: []string{"running"}},
+			},
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(groupCtx)
+			if err != nil {
+				return nil
+			}
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					if instance.InstanceId == nil {
+						continue
+					}
+					if instance.VpcId != nil {
+						vpcs[*instance.InstanceId] = *instance.VpcId
+					}
+					for _, tag := range instance.Tags {
+						if tag.Key != nil && *tag.Key == "Name" && tag.Value != nil {
+							names[*instance.InstanceId] = *tag.Value
+							break
+						}
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(managed) == 0 {
+		return nil, nil, nil, ErrNoManagedInstances
+	}
+
+	// Build display names for instances that are online or connection lost (still manageable)
+	displayNames := make([]string, 0, len(managed))
+	instanceMap := make(map[string]string)
+	offline := &NoOnlineInstancesError{}
+	for _, instance := range managed {
+		if instance.InstanceId == nil {
+			continue
+		}
+
+		instanceId := *instance.InstanceId
+		displayName := instanceId
+		details := instanceDetails(instance)
+		if name := names[instanceId]; name != "" {
+			displayName = fmt.Sprintf("%s (%s)", name, strings.Join(append([]string{instanceId}, details...), " · "))
+		} else if len(details) > 0 {
+			displayName = fmt.Sprintf("%s (%s)", instanceId, strings.Join(details, " · "))
+		}
+
+		if instance.PingStatus != types.PingStatusOnline && instance.PingStatus != types.PingStatusConnectionLost {
+			offline.Instances = append(offline.Instances, OfflineInstance{
+				DisplayName: displayName,
+				InstanceID:  instanceId,
+				PingStatus:  string(instance.PingStatus),
+				LastPing:    aws.ToTime(instance.LastPingDateTime),
+			})
+			continue
+		}
+
+		displayNames = append(displayNames, displayName)
+		instanceMap[displayName] = instanceId
+	}
+
+	if len(displayNames) == 0 {
+		return nil, nil, nil, offline
+	}
+	return displayNames, instanceMap, vpcs, nil
+}
+
+// MinAgentVersion is the oldest SSM agent that supports port forwarding to remote hosts, which every
+// bifrost tunnel uses
+const MinAgentVersion = "3.1.1374.0"
+
+// AgentOutdated reports whether an SSM agent version is older than MinAgentVersion. Versions that
+// can't be parsed are not reported.
+func AgentOutdated(version string) bool {
+	current, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	minimum, _ := parseVersion(MinAgentVersion)
+	return slices.Compare(current, minimum) < 0
+}
+
+func parseVersion(version string) ([]int, bool) {
+	if version == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// instanceDetails describes the platform and SSM agent of an instance for the bastion picker,
+// e.g. "Amazon Linux" and "agent 3.3.40.0", flagging agents too old for port forwarding
+func instanceDetails(instance types.InstanceInformation) []string {
+	var details []string
+	if platform := aws.ToString(instance.PlatformName); platform != "" {
+		details = append(details, platform)
+	} else if instance.PlatformType != "" {
+		details = append(details, string(instance.PlatformType))
+	}
+	if version := aws.ToString(instance.AgentVersion); version != "" {
+		agent := "agent " + version
+		if AgentOutdated(version) {
+			agent += " (too old)"
+		}
+		details = append(details, agent)
+	}
+	return details
+}
+
+// ErrNoManagedInstances means no instances are registered with SSM in the region
+var ErrNoManagedInstances = errors.New("no SSM managed instances found in this region")
+
+// OfflineInstance is an SSM managed instance whose agent is not reachable
+type OfflineInstance struct {
+	DisplayName string
+	InstanceID  string
+	PingStatus  string
+	LastPing    time.Time
+}
+
+// NoOnlineInstancesError means instances are registered with SSM but none of their agents are online
+type NoOnlineInstancesError struct {
+	Instances []OfflineInstance
+}
+
+func (e *NoOnlineInstancesError) Error() string {
+	return fmt.Sprintf("%d SSM managed instance(s) found, but none are online", len(e.Instances))
+}
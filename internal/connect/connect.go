@@ -0,0 +1,251 @@
+// Package connect opens SSM port forwarding tunnels through a bastion to AWS resources.
+// The bifrost connect command adds prompts on top of it, other tools can use Connect directly.
+package connect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/sso"
+)
+
+// Chooser picks one of several options, e.g. a node group of a sharded Redis cluster.
+// The bifrost CLI passes its interactive select prompt.
+type Chooser func(label string, options []string) (string, error)
+
+// Options describes a fully specified connection. Nothing is prompted for, so every field
+// except the role chain, endpoint type, auth timeout, auth handler and chooser must be set. With an AWS
+// profile the SSO profile, account and role are not needed, Keyspaces needs no resource name.
+// The account can be given by name instead of ID, it is looked up when signing in.
+// AuthHandler, if set, shows the SSO device login instead of printing it and opening the browser.
+// Targets, if set, forwards several resources at once in place of the service type, resource name
+// and local port.
+type Options struct {
+	AWSProfile        string
+	SSOProfile        string
+	AccountID         string
+	AccountName       string
+	RoleName          string
+	Region            string
+	RoleChain         RoleChain
+	AuthTimeout       time.Duration
+	AuthHandler       sso.AuthHandler
+	ServiceType       string
+	ResourceName      string
+	EndpointType      string
+	LocalPort         string
+	BastionInstanceID string
+	Targets           []config.TargetSpec
+	Tunnel            TunnelOptions
+	Choose            Chooser
+}
+
+// targetSpecs returns the targets to resolve, the single service's when Targets isn't set
+func (o Options) targetSpecs() []config.TargetSpec {
+	if len(o.Targets) > 0 {
+		return o.Targets
+	}
+	return []config.TargetSpec{{ServiceType: o.ServiceType, Port: o.LocalPort, ResourceName: o.ResourceName}}
+}
+
+func (o Options) validate() error {
+	required := []struct{ name, value string }{
+		{"region", o.Region},
+		{"bastion instance ID", o.BastionInstanceID},
+	}
+	if len(o.Targets) == 0 {
+		required = append(required, []struct{ name, value string }{
+			{"service type", o.ServiceType},
+			{"local port", o.LocalPort},
+		}...)
+		if o.ServiceType != "keyspaces" {
+			required = append(required, struct{ name, value string }{"resource name", o.ResourceName})
+		}
+	}
+	if o.AWSProfile == "" {
+		required = append(required, []struct{ name, value string }{
+			{"SSO profile", o.SSOProfile},
+			{"account ID or name", o.AccountID + o.AccountName},
+			{"role name", o.RoleName},
+		}...)
+	}
+	for _, field := range required {
+		if field.value == "" {
+			return fmt.Errorf("%s is required", field.name)
+		}
+	}
+	if len(o.Targets) == 0 && !slices.Contains(ServiceTypes, o.ServiceType) {
+		return fmt.Errorf("unsupported service type '%s'", o.ServiceType)
+	}
+	return nil
+}
+
+// Connect signs in with the SSO profile, resolves the resource's endpoints and starts forwarding them.
+// The tunnels run until ctx is cancelled, one of them fails or the returned session is closed.
+func Connect(ctx context.Context, opts Options) (*Session, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	cfg, err := AWSConfig(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointType := opts.EndpointType
+	if endpointType == "" {
+		endpointType = RedisEndpointPrimary
+	}
+	targets, err := ResolveTargetSpecs(ctx, cfg, opts.Choose, opts.targetSpecs(), endpointType)
+	if err != nil {
+		return nil, err
+	}
+
+	return Start(ctx, cfg, opts.BastionInstanceID, targets, opts.Tunnel)
+}
+
+// Session is a running set of tunnels through one bastion
+type Session struct {
+	Targets []Target
+
+	listenAddress string
+	counters      *sessionCounters
+	cancel        context.CancelFunc
+	done          chan struct{}
+	err           error
+}
+
+// Start forwards each target through the bastion in the config's region, tearing them all down together
+func Start(ctx context.Context, cfg aws.Config, instanceID string, targets []Target, opts TunnelOptions) (*Session, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets to forward")
+	}
+	if err := CheckPrerequisites(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Session{
+		Targets:       targets,
+		listenAddress: opts.ListenAddress(),
+		counters:      &sessionCounters{},
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+	opts.counters = s.counters
+	s.counters.touch()
+	go enforceSessionLimits(ctx, cancel, opts)
+	if opts.KeepAlive && opts.SSOClient != nil && opts.TokenWarning > 0 {
+		go WatchSSOToken(ctx, opts.SSOClient, opts.TokenWarning)
+	}
+	go func() {
+		defer close(s.done)
+		defer cancel()
+		s.err = runTargets(ctx, cancel, cfg, instanceID, targets, opts)
+	}()
+	if opts.OnReady != nil {
+		go func() {
+			if s.Ready(ctx) == nil {
+				opts.OnReady()
+			}
+		}()
+	}
+	return s, nil
+}
+
+// Done is closed once every tunnel of the session has stopped
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the session stops and returns why it stopped, nil after Close or cancellation
+func (s *Session) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Stats reports the reconnects and keep alive failures of the session so far
+func (s *Session) Stats() Stats {
+	return s.counters.stats()
+}
+
+// Ready waits until every target's local port accepts connections.
+// It fails if the session stops or ctx is done first.
+func (s *Session) Ready(ctx context.Context) error {
+	for _, target := range s.Targets {
+		address := net.JoinHostPort(s.listenAddress, target.LocalPort)
+		for PerformKeepAlive(address) != nil {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("tunnel on %s did not become ready: %w", address, ctx.Err())
+			case <-s.done:
+				return fmt.Errorf("tunnel on %s stopped before it was ready: %s", address, sessionExitReason(s.err))
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops every tunnel of the session and waits for the SSM sessions to exit
+func (s *Session) Close() error {
+	s.cancel()
+	return s.Wait()
+}
+
+// Run one SSM session per target, cancelling the others when one goes down
+func runTargets(ctx context.Context, cancel context.CancelFunc, cfg aws.Config, instanceID string, targets []Target, opts TunnelOptions) error {
+	newCmd := func(target Target) func(pluginPort string) (*exec.Cmd, error) {
+		return func(pluginPort string) (*exec.Cmd, error) {
+			cmd, err := NewSSMCommand(cfg, instanceID, target.Endpoint, target.Port, pluginPort, cfg.Region, opts)
+			if err != nil {
+				return nil, err
+			}
+			cmd.Stdin = opts.Stdin
+			cmd.Stdout = opts.Stdout
+			cmd.Stderr = opts.Stderr
+			if opts.Prepare != nil {
+				opts.Prepare(cmd)
+			}
+			return cmd, nil
+		}
+	}
+
+	if len(targets) == 1 {
+		targetOpts := opts
+		targetOpts.ServiceType = targets[0].ServiceType
+		targetOpts.tlsRequired = targets[0].TLSRequired
+		return runSSMPortForwardingWithReconnect(ctx, newCmd(targets[0]), targets[0].LocalPort, targetOpts)
+	}
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(targets))
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target Target) {
+			defer wg.Done()
+			targetOpts := opts
+			targetOpts.ServiceType = target.ServiceType
+			targetOpts.tlsRequired = target.TLSRequired
+			err := runSSMPortForwardingWithReconnect(ctx, newCmd(target), target.LocalPort, targetOpts)
+			if ctx.Err() == nil {
+				// One tunnel going down takes the others with it
+				errChan <- fmt.Errorf("%s tunnel to %s: %s", target.ServiceType, target.ResourceName, sessionExitReason(err))
+				cancel()
+			}
+		}(target)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	return <-errChan
+}
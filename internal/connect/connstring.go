@@ -0,0 +1,43 @@
+package connect
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ConnectionString returns a URL clients can connect to target with through host, e.g.
+// postgresql://app@127.0.0.1:5432/orders, using user and dbName when they are set. It is empty for
+// services and engines without a well-known URL scheme.
+func ConnectionString(target Target, host, user, dbName string) string {
+	var scheme string
+	switch target.ServiceType {
+	case "rds":
+		switch {
+		case strings.Contains(target.Engine, "postgres"):
+			scheme = "postgresql"
+		case strings.Contains(target.Engine, "mysql"), strings.Contains(target.Engine, "mariadb"):
+			scheme = "mysql"
+		default:
+			return ""
+		}
+	case "redis":
+		// Redis has no user or database names, only numbered databases that clients pick themselves
+		scheme = "redis"
+		if target.TLSRequired {
+			scheme = "rediss"
+		}
+		user, dbName = "", ""
+	default:
+		return ""
+	}
+
+	u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, target.LocalPort)}
+	if user != "" {
+		u.User = url.User(user)
+	}
+	if dbName != "" {
+		u.Path = "/" + dbName
+	}
+	return u.String()
+}
@@ -0,0 +1,198 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/b3nk3/bifrost/internal/awsretry"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/sso"
+)
+
+// RoleChain is an optional role assumed with the SSO role credentials
+type RoleChain struct {
+	RoleARN    string
+	ExternalID string
+}
+
+// AccountID returns the account of the chained role, empty when there is none or the ARN is invalid
+func (c RoleChain) AccountID() string {
+	parsed, err := arn.Parse(c.RoleARN)
+	if err != nil {
+		return ""
+	}
+	return parsed.AccountID
+}
+
+// AWSConfig signs in with the SSO profile and returns an SDK config for the account, role and region.
+// A device login is started if there is no usable cached token. With an AWS profile, its credentials
+// are used instead, see SharedProfileConfig.
+func AWSConfig(ctx context.Context, opts Options) (aws.Config, error) {
+	if opts.AWSProfile != "" {
+		return SharedProfileConfig(ctx, opts.AWSProfile, opts.Region, opts.RoleChain)
+	}
+
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return aws.Config{}, err
+	}
+	ssoProfile, err := cfg.SSOProfile(opts.SSOProfile)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to get SSO profile '%s': %v", opts.SSOProfile, err)
+	}
+
+	ssoClient := NewSSOClient(ssoProfile, opts.AuthTimeout, append(TokenCacheOptions(cfg), sso.WithAuthHandler(opts.AuthHandler))...)
+	token, err := ssoClient.Authenticate(ctx)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("authentication failed: %v", err)
+	}
+
+	accountID := opts.AccountID
+	if accountID == "" {
+		accounts, err := ssoClient.ListAccounts(ctx, token)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("failed to list accounts: %v", err)
+		}
+		accountID, err = sso.AccountIDByName(accounts, opts.AccountName)
+		if err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	roleCreds, err := ssoClient.GetRoleCredentials(ctx, token, accountID, opts.RoleName)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to get role credentials: %v", err)
+	}
+
+	return NewAWSConfig(roleCreds.RoleCredentials, opts.Region, opts.RoleChain)
+}
+
+// NewSSOClient returns the client signing in with the SSO profile. extra options are applied last,
+// pass TokenCacheOptions for the config the profile was loaded from to honour its token settings.
+func NewSSOClient(ssoProfile *config.SSOProfile, authTimeout time.Duration, extra ...sso.Option) *sso.Client {
+	opts := []sso.Option{
+		sso.WithAuthTimeout(authTimeout),
+		sso.WithClientName(ssoProfile.ClientName),
+		sso.WithScopes(ssoProfile.Scopes...),
+	}
+	opts = append(opts, extra...)
+	return sso.NewClient(ssoProfile.SSORegion, ssoProfile.StartURL, opts...)
+}
+
+// TokenCacheOptions returns the SSO client options caching tokens for the token_lifetime and
+// expiry_skew of cfg, which leave the defaults when unset
+func TokenCacheOptions(cfg *config.Config) []sso.Option {
+	return []sso.Option{sso.WithTokenLifetime(cfg.TokenLifetime), sso.WithExpirySkew(cfg.ExpirySkew)}
+}
+
+// NewAWSConfig builds an SDK config from SSO role credentials, assuming the chained role if one is given
+func NewAWSConfig(roleCreds *ssotypes.RoleCredentials, region string, chain RoleChain) (aws.Config, error) {
+	// Create AWS config with the role credentials and region
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion(region),
+		awsconfig.WithRetryer(awsretry.NewRetryer),
+		awsconfig.WithAPIOptions([]func(*middleware.Stack) error{logging.LogAWSCalls}),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			*roleCreds.AccessKeyId,
+			*roleCreds.SecretAccessKey,
+			*roleCreds.SessionToken,
+		)),
+	)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to create AWS config: %v", err)
+	}
+
+	if chain.RoleARN != "" {
+		if err := assumeRoleChain(&awsCfg, chain); err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	return awsCfg, nil
+}
+
+// SharedProfileConfig builds an SDK config from a profile in the shared AWS config (~/.aws/config and
+// ~/.aws/credentials) rather than a bifrost SSO profile, assuming the chained role if one is given.
+// The profile's own region is used when region is empty.
+func SharedProfileConfig(ctx context.Context, profile, region string, chain RoleChain) (aws.Config, error) {
+	optFns := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithSharedConfigProfile(profile),
+		awsconfig.WithRetryer(awsretry.NewRetryer),
+		awsconfig.WithAPIOptions([]func(*middleware.Stack) error{logging.LogAWSCalls}),
+	}
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS profile '%s': %w", profile, err)
+	}
+
+	// Resolve the credentials now so an expired SSO session or missing keys show up before any lookups
+	if _, err := awsCfg.Credentials.Retrieve(ctx); err != nil {
+		return aws.Config{}, fmt.Errorf("failed to get credentials for AWS profile '%s': %w", profile, err)
+	}
+
+	if chain.RoleARN != "" {
+		if err := assumeRoleChain(&awsCfg, chain); err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	return awsCfg, nil
+}
+
+// CallerAccountID returns the account the config's credentials belong to
+func CallerAccountID(ctx context.Context, cfg aws.Config) (string, error) {
+	// STS answers for every account globally, so any region will do when the profile has none
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", AWSError(ctx, err))
+	}
+	return aws.ToString(identity.Account), nil
+}
+
+// Replace the SSO credentials in cfg with credentials for the chained role.
+// The provider is cached so credentials are renewed when a reconnect needs them.
+func assumeRoleChain(cfg *aws.Config, chain RoleChain) error {
+	if _, err := arn.Parse(chain.RoleARN); err != nil {
+		return fmt.Errorf("invalid role ARN '%s': %w", chain.RoleARN, err)
+	}
+
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*cfg), chain.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "bifrost"
+		if chain.ExternalID != "" {
+			o.ExternalID = aws.String(chain.ExternalID)
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+
+	// Assume the role now so permission problems show up before any resource lookups
+	if _, err := cfg.Credentials.Retrieve(context.Background()); err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDenied" {
+			return fmt.Errorf("access denied assuming role %s: check the SSO role may call sts:AssumeRole on it and the role trusts it (and the external ID matches, if one is required): %s", chain.RoleARN, apiErr.ErrorMessage())
+		}
+		return fmt.Errorf("failed to assume role %s: %w", chain.RoleARN, err)
+	}
+
+	report("🔗 Assumed role: %s\n", chain.RoleARN)
+	return nil
+}
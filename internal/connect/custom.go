@@ -0,0 +1,54 @@
+package connect
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// CustomEndpoint joins the host and port of a custom target into its resource name, e.g. api.internal:8080
+func CustomEndpoint(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// ParseCustomEndpoint splits a custom target's host:port, checking both are usable
+func ParseCustomEndpoint(endpoint string) (string, int32, error) {
+	host, portText, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, fmt.Errorf("custom endpoint '%s' must be host:port (e.g. api.internal:8080)", endpoint)
+	}
+	if err := ValidateCustomHost(host); err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("custom endpoint '%s' needs a port between 1 and 65535", endpoint)
+	}
+	return host, int32(port), nil
+}
+
+// ValidateCustomHost checks a custom target's host is an IP address or a well-formed DNS name.
+// Whether it resolves is up to the bastion, which is the one connecting to it.
+func ValidateCustomHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if len(host) > 253 {
+		return fmt.Errorf("host '%s' is too long to be a DNS name", host)
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("host '%s' is not a valid DNS name or IP address", host)
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Errorf("host '%s' is not a valid DNS name or IP address", host)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,656 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbtypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	elasticachetypes "github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	neptunetypes "github.com/aws/aws-sdk-go-v2/service/neptune/types"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/events"
+)
+
+// Endpoint types selectable with --endpoint-type, for Redis clusters and Neptune clusters (writer or reader)
+const (
+	RedisEndpointPrimary = "primary"
+	RedisEndpointReader  = "reader"
+
+	redisConfigurationEndpointOption = "Configuration endpoint (all shards)"
+)
+
+var RedisEndpointTypes = []string{RedisEndpointPrimary, RedisEndpointReader}
+
+// ServiceTypes lists the services bifrost can forward to
+var ServiceTypes = []string{"rds", "redis", "documentdb", "neptune", "opensearch", "kafka", "keyspaces", "custom"}
+
+// NeptunePort is the port Neptune clusters serve Gremlin, SPARQL and openCypher on by default
+const NeptunePort = 8182
+
+// KeyspacesPort is the TLS port of the Amazon Keyspaces endpoints
+const KeyspacesPort = 9142
+
+// KeyspacesEndpoint returns the regional Amazon Keyspaces endpoint. Keyspaces has no
+// per-resource endpoints, every keyspace in the region is reached through this one.
+func KeyspacesEndpoint(region string) string {
+	return fmt.Sprintf("cassandra.%s.amazonaws.com", region)
+}
+
+// List all RDS instances in the region
+func listRDSInstances(ctx context.Context, svc rds.DescribeDBInstancesAPIClient) ([]string, error) {
+	instances := []string{}
+	paginator := rds.NewDescribeDBInstancesPaginator(svc, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list RDS instances: %w", err)
+		}
+
+		for _, db := range page.DBInstances {
+			if db.DBInstanceIdentifier != nil {
+				instances = append(instances, *db.DBInstanceIdentifier)
+			}
+		}
+	}
+
+	return instances, nil
+}
+
+// List the resources available for a service type in the region
+func ListResources(ctx context.Context, cfg aws.Config, serviceType string) (resources []string, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	switch serviceType {
+	case "rds":
+		return listRDSInstances(ctx, rds.NewFromConfig(cfg))
+	case "redis":
+		return listRedisClusters(ctx, elasticache.NewFromConfig(cfg))
+	case "documentdb":
+		return listDocumentDBClusters(ctx, cfg)
+	case "neptune":
+		return listNeptuneClusters(ctx, cfg)
+	case "opensearch":
+		return listOpenSearchDomains(ctx, cfg)
+	case "kafka":
+		return listMSKClusters(ctx, cfg)
+	case "keyspaces":
+		return []string{KeyspacesEndpoint(cfg.Region)}, nil
+	case "custom":
+		return nil, fmt.Errorf("custom endpoints can't be listed, give the host and port to forward to")
+	default:
+		return nil, fmt.Errorf("unsupported service type '%s'", serviceType)
+	}
+}
+
+// Resolve the endpoint host and port of a named resource for a service type
+func ResolveEndpoint(ctx context.Context, cfg aws.Config, choose Chooser, serviceType, resourceName, endpointType string) (endpoint string, port int32, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	resolver, err := NewEndpointResolver(cfg, serviceType, choose, endpointType)
+	if err != nil {
+		return "", 0, err
+	}
+	return resolver.Resolve(ctx, resourceName)
+}
+
+// ResolveTargets resolves a named resource to the tunnels needed to reach it from localPort.
+// Most services have a single endpoint, MSK clusters get one target per broker on consecutive local ports.
+func ResolveTargets(ctx context.Context, cfg aws.Config, choose Chooser, serviceType, resourceName, endpointType, localPort string) (targets []Target, err error) {
+	defer func() {
+		if err != nil {
+			return
+		}
+		for _, target := range targets {
+			events.Emit(events.Event{
+				Type:     events.EndpointResolved,
+				Service:  target.ServiceType,
+				Resource: target.ResourceName,
+				Endpoint: net.JoinHostPort(target.Endpoint, strconv.Itoa(int(target.Port))),
+			})
+		}
+	}()
+	defer func() { err = AWSError(ctx, err) }()
+
+	if serviceType == "keyspaces" && resourceName == "" {
+		resourceName = KeyspacesEndpoint(cfg.Region)
+	}
+
+	switch serviceType {
+	case "kafka":
+		return kafkaTargets(ctx, cfg, resourceName, localPort)
+	case "rds":
+		target, err := getRDSTarget(ctx, rds.NewFromConfig(cfg), resourceName)
+		if err != nil {
+			return nil, err
+		}
+		target.ServiceType = serviceType
+		target.ResourceName = resourceName
+		target.LocalPort = localPort
+		return []Target{target}, nil
+	case "redis":
+		target, err := getRedisTarget(ctx, elasticache.NewFromConfig(cfg), choose, resourceName, endpointType)
+		if err != nil {
+			return nil, err
+		}
+		target.ServiceType = serviceType
+		target.ResourceName = resourceName
+		target.LocalPort = localPort
+		return []Target{target}, nil
+	}
+
+	endpoint, port, err := ResolveEndpoint(ctx, cfg, choose, serviceType, resourceName, endpointType)
+	if err != nil {
+		return nil, err
+	}
+	return []Target{{
+		ServiceType:  serviceType,
+		ResourceName: resourceName,
+		Endpoint:     endpoint,
+		Port:         port,
+		LocalPort:    localPort,
+	}}, nil
+}
+
+// ResolveTargetSpecs resolves each spec to its tunnels with ResolveTargets, making sure no two of them
+// share a local port. A lone spec may leave its port empty for the caller to pick once the endpoint
+// is known, e.g. defaulting to the endpoint's own port; with several, every one needs a port.
+func ResolveTargetSpecs(ctx context.Context, cfg aws.Config, choose Chooser, specs []config.TargetSpec, endpointType string) ([]Target, error) {
+	targets := make([]Target, 0, len(specs))
+	usedPorts := make(map[string]bool)
+
+	for i, spec := range specs {
+		label := fmt.Sprintf("target %d (%s)", i+1, spec.ResourceName)
+		if spec.ResourceName == "" && spec.ServiceType != "keyspaces" {
+			return nil, fmt.Errorf("target %d has no resource name", i+1)
+		}
+		if !slices.Contains(ServiceTypes, spec.ServiceType) {
+			return nil, fmt.Errorf("%s: invalid service type '%s'", label, spec.ServiceType)
+		}
+		if spec.Port != "" || len(specs) > 1 {
+			if err := ValidatePort(spec.Port); err != nil {
+				return nil, fmt.Errorf("%s: %w", label, err)
+			}
+		}
+
+		// Kafka targets fan out to one local port per broker, starting at the target's port
+		resolved, err := ResolveTargets(ctx, cfg, choose, spec.ServiceType, spec.ResourceName, endpointType, spec.Port)
+		if err != nil {
+			if len(specs) == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", label, err)
+		}
+		for _, target := range resolved {
+			if target.LocalPort == "" {
+				continue
+			}
+			if usedPorts[target.LocalPort] {
+				return nil, fmt.Errorf("%s: port %s is used by another target", label, target.LocalPort)
+			}
+			usedPorts[target.LocalPort] = true
+		}
+		targets = append(targets, resolved...)
+	}
+
+	return targets, nil
+}
+
+// RDSStatusAvailable is the status of an RDS instance that accepts connections
+const RDSStatusAvailable = "available"
+
+// Get the RDS database endpoint, engine and status by DB instance name
+func getRDSTarget(ctx context.Context, svc rds.DescribeDBInstancesAPIClient, dbInstanceName string) (Target, error) {
+	if dbInstanceName == "" {
+		return Target{}, fmt.Errorf("RDS instance name cannot be empty")
+	}
+
+	// Get specific DB instance by name
+	result, err := svc.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: &dbInstanceName,
+	})
+	if err != nil {
+		return Target{}, fmt.Errorf("failed to describe DB instance '%s': %w", dbInstanceName, err)
+	}
+
+	if len(result.DBInstances) == 0 {
+		return Target{}, fmt.Errorf("DB instance '%s' not found", dbInstanceName)
+	}
+
+	db := result.DBInstances[0]
+	if db.Endpoint == nil {
+		return Target{}, fmt.Errorf("DB instance '%s' does not have an endpoint (status: %s)", dbInstanceName, aws.ToString(db.DBInstanceStatus))
+	}
+
+	report("🎯 Connecting to RDS instance: %s\n", *db.DBInstanceIdentifier)
+	return Target{
+		Endpoint: *db.Endpoint.Address,
+		Port:     int32(*db.Endpoint.Port),
+		Engine:   aws.ToString(db.Engine),
+		Status:   aws.ToString(db.DBInstanceStatus),
+	}, nil
+}
+
+// List all Redis clusters in the region
+func listRedisClusters(ctx context.Context, svc elasticache.DescribeReplicationGroupsAPIClient) ([]string, error) {
+	clusters := []string{}
+	paginator := elasticache.NewDescribeReplicationGroupsPaginator(svc, &elasticache.DescribeReplicationGroupsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Redis clusters: %w", err)
+		}
+
+		for _, cluster := range page.ReplicationGroups {
+			if cluster.ReplicationGroupId != nil {
+				clusters = append(clusters, *cluster.ReplicationGroupId)
+			}
+		}
+	}
+
+	return clusters, nil
+}
+
+// Get the Redis cluster endpoint and whether clients need TLS and an AUTH token, by replication group name
+func getRedisTarget(ctx context.Context, svc elasticache.DescribeReplicationGroupsAPIClient, choose Chooser, clusterName, endpointType string) (Target, error) {
+	if clusterName == "" {
+		return Target{}, fmt.Errorf("redis cluster name cannot be empty")
+	}
+
+	result, err := svc.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+		ReplicationGroupId: &clusterName,
+	})
+	if err != nil {
+		return Target{}, fmt.Errorf("failed to describe Redis cluster '%s': %w", clusterName, err)
+	}
+
+	if len(result.ReplicationGroups) == 0 {
+		return Target{}, fmt.Errorf("redis cluster '%s' not found", clusterName)
+	}
+
+	cluster := result.ReplicationGroups[0]
+	endpoint, port, err := getRedisEndpoint(cluster, choose, clusterName, endpointType)
+	if err != nil {
+		return Target{}, err
+	}
+
+	return Target{
+		Endpoint:     endpoint,
+		Port:         port,
+		TLSRequired:  aws.ToBool(cluster.TransitEncryptionEnabled),
+		AuthRequired: aws.ToBool(cluster.AuthTokenEnabled),
+	}, nil
+}
+
+// Pick the endpoint of a Redis replication group.
+// Sharded groups expose a configuration endpoint and one endpoint set per node group,
+// so the user picks which one to forward to.
+func getRedisEndpoint(cluster elasticachetypes.ReplicationGroup, choose Chooser, clusterName, endpointType string) (string, int32, error) {
+	if len(cluster.NodeGroups) == 0 && cluster.ConfigurationEndpoint == nil {
+		return "", 0, fmt.Errorf("redis cluster '%s' has no node groups", clusterName)
+	}
+
+	report("🎯 Connecting to Redis cluster: %s\n", *cluster.ReplicationGroupId)
+
+	// Single node group: use its primary or reader endpoint, falling back to the configuration endpoint
+	if len(cluster.NodeGroups) <= 1 {
+		if len(cluster.NodeGroups) == 1 {
+			if endpoint := redisNodeGroupEndpoint(cluster.NodeGroups[0], endpointType); endpoint != nil {
+				return aws.ToString(endpoint.Address), aws.ToInt32(endpoint.Port), nil
+			}
+		}
+		if endpointType == RedisEndpointPrimary && cluster.ConfigurationEndpoint != nil {
+			return aws.ToString(cluster.ConfigurationEndpoint.Address), aws.ToInt32(cluster.ConfigurationEndpoint.Port), nil
+		}
+		return "", 0, fmt.Errorf("redis cluster '%s' does not have a %s endpoint (may not be available)", clusterName, endpointType)
+	}
+
+	// Multiple node groups: let the user choose the configuration endpoint or a specific node group
+	options := make([]string, 0, len(cluster.NodeGroups)+1)
+	if endpointType == RedisEndpointPrimary && cluster.ConfigurationEndpoint != nil {
+		options = append(options, redisConfigurationEndpointOption)
+	}
+	nodeGroups := make(map[string]elasticachetypes.NodeGroup, len(cluster.NodeGroups))
+	for _, nodeGroup := range cluster.NodeGroups {
+		id := aws.ToString(nodeGroup.NodeGroupId)
+		options = append(options, id)
+		nodeGroups[id] = nodeGroup
+	}
+
+	if choose == nil {
+		return "", 0, fmt.Errorf("redis cluster '%s' has %d node groups, a Chooser is needed to pick one", clusterName, len(cluster.NodeGroups))
+	}
+	selected, err := choose("Select node group", options)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to select node group: %w", err)
+	}
+
+	if selected == redisConfigurationEndpointOption {
+		return aws.ToString(cluster.ConfigurationEndpoint.Address), aws.ToInt32(cluster.ConfigurationEndpoint.Port), nil
+	}
+
+	endpoint := redisNodeGroupEndpoint(nodeGroups[selected], endpointType)
+	if endpoint == nil {
+		return "", 0, fmt.Errorf("node group '%s' of redis cluster '%s' does not have a %s endpoint", selected, clusterName, endpointType)
+	}
+	return aws.ToString(endpoint.Address), aws.ToInt32(endpoint.Port), nil
+}
+
+// Pick the primary or reader endpoint of a node group, nil if it has none
+func redisNodeGroupEndpoint(nodeGroup elasticachetypes.NodeGroup, endpointType string) *elasticachetypes.Endpoint {
+	if endpointType == RedisEndpointReader {
+		return nodeGroup.ReaderEndpoint
+	}
+	return nodeGroup.PrimaryEndpoint
+}
+
+// List all DocumentDB clusters in the region
+func listDocumentDBClusters(ctx context.Context, cfg aws.Config) ([]string, error) {
+	svc := docdb.NewFromConfig(cfg)
+
+	// DescribeDBClusters also returns RDS and Neptune clusters unless filtered by engine
+	clusters := []string{}
+	paginator := docdb.NewDescribeDBClustersPaginator(svc, &docdb.DescribeDBClustersInput{
+		Filters: []docdbtypes.Filter{
+			{Name: aws.String("engine"), Values: []string{"docdb"}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list DocumentDB clusters: %w", err)
+		}
+
+		for _, cluster := range page.DBClusters {
+			if cluster.DBClusterIdentifier != nil {
+				clusters = append(clusters, *cluster.DBClusterIdentifier)
+			}
+		}
+	}
+
+	return clusters, nil
+}
+
+// Get the DocumentDB cluster endpoint by cluster identifier
+func getDocumentDBEndpoint(ctx context.Context, svc docdb.DescribeDBClustersAPIClient, clusterID string) (string, int32, error) {
+	if clusterID == "" {
+		return "", 0, fmt.Errorf("DocumentDB cluster identifier cannot be empty")
+	}
+
+	result, err := svc.DescribeDBClusters(ctx, &docdb.DescribeDBClustersInput{
+		DBClusterIdentifier: &clusterID,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to describe DocumentDB cluster '%s': %w", clusterID, err)
+	}
+
+	if len(result.DBClusters) == 0 {
+		return "", 0, fmt.Errorf("DocumentDB cluster '%s' not found", clusterID)
+	}
+
+	cluster := result.DBClusters[0]
+	if cluster.Endpoint == nil {
+		return "", 0, fmt.Errorf("DocumentDB cluster '%s' does not have an endpoint (may not be available)", clusterID)
+	}
+
+	port := int32(27017)
+	if cluster.Port != nil {
+		port = *cluster.Port
+	}
+
+	report("🎯 Connecting to DocumentDB cluster: %s\n", *cluster.DBClusterIdentifier)
+	return *cluster.Endpoint, port, nil
+}
+
+// List all Neptune clusters in the region
+func listNeptuneClusters(ctx context.Context, cfg aws.Config) ([]string, error) {
+	svc := neptune.NewFromConfig(cfg)
+
+	// Like DocumentDB, DescribeDBClusters returns the clusters of every RDS-family engine unless filtered
+	clusters := []string{}
+	paginator := neptune.NewDescribeDBClustersPaginator(svc, &neptune.DescribeDBClustersInput{
+		Filters: []neptunetypes.Filter{
+			{Name: aws.String("engine"), Values: []string{"neptune"}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Neptune clusters: %w", err)
+		}
+
+		for _, cluster := range page.DBClusters {
+			if cluster.DBClusterIdentifier != nil {
+				clusters = append(clusters, *cluster.DBClusterIdentifier)
+			}
+		}
+	}
+
+	return clusters, nil
+}
+
+// Get the Neptune cluster (writer) or reader endpoint by cluster identifier
+func getNeptuneEndpoint(ctx context.Context, svc neptune.DescribeDBClustersAPIClient, clusterID, endpointType string) (string, int32, error) {
+	if clusterID == "" {
+		return "", 0, fmt.Errorf("Neptune cluster identifier cannot be empty")
+	}
+
+	result, err := svc.DescribeDBClusters(ctx, &neptune.DescribeDBClustersInput{
+		DBClusterIdentifier: &clusterID,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to describe Neptune cluster '%s': %w", clusterID, err)
+	}
+
+	if len(result.DBClusters) == 0 {
+		return "", 0, fmt.Errorf("Neptune cluster '%s' not found", clusterID)
+	}
+
+	cluster := result.DBClusters[0]
+	endpoint := cluster.Endpoint
+	if endpointType == RedisEndpointReader {
+		endpoint = cluster.ReaderEndpoint
+	}
+	if endpoint == nil {
+		return "", 0, fmt.Errorf("Neptune cluster '%s' does not have a %s endpoint (may not be available)", clusterID, endpointType)
+	}
+
+	port := int32(NeptunePort)
+	if cluster.Port != nil {
+		port = *cluster.Port
+	}
+
+	report("🎯 Connecting to Neptune cluster: %s (%s endpoint)\n", *cluster.DBClusterIdentifier, endpointType)
+	return *endpoint, port, nil
+}
+
+// List all OpenSearch domains in the region
+func listOpenSearchDomains(ctx context.Context, cfg aws.Config) ([]string, error) {
+	svc := opensearch.NewFromConfig(cfg)
+
+	result, err := svc.ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OpenSearch domains: %w", err)
+	}
+
+	domains := make([]string, 0, len(result.DomainNames))
+	for _, domain := range result.DomainNames {
+		if domain.DomainName != nil {
+			domains = append(domains, *domain.DomainName)
+		}
+	}
+
+	return domains, nil
+}
+
+// Get the VPC endpoint of an OpenSearch domain by domain name
+func getOpenSearchEndpoint(ctx context.Context, svc OpenSearchDescribeDomainAPIClient, domainName string) (string, int32, error) {
+	if domainName == "" {
+		return "", 0, fmt.Errorf("OpenSearch domain name cannot be empty")
+	}
+
+	result, err := svc.DescribeDomain(ctx, &opensearch.DescribeDomainInput{
+		DomainName: &domainName,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to describe OpenSearch domain '%s': %w", domainName, err)
+	}
+
+	domain := result.DomainStatus
+	if domain == nil {
+		return "", 0, fmt.Errorf("OpenSearch domain '%s' not found", domainName)
+	}
+
+	// VPC domains expose their endpoint under the "vpc" key, public domains use Endpoint
+	endpoint := domain.Endpoints["vpc"]
+	if endpoint == "" {
+		endpoint = aws.ToString(domain.Endpoint)
+	}
+	if endpoint == "" {
+		return "", 0, fmt.Errorf("OpenSearch domain '%s' does not have an endpoint (may not be available)", domainName)
+	}
+
+	report("🎯 Connecting to OpenSearch domain: %s\n", aws.ToString(domain.DomainName))
+	return endpoint, 443, nil
+}
+
+// Endpoint is a host and port reachable from the bastion
+type Endpoint struct {
+	Host string
+	Port int32
+}
+
+// List all MSK clusters in the region
+func listMSKClusters(ctx context.Context, cfg aws.Config) ([]string, error) {
+	svc := kafka.NewFromConfig(cfg)
+
+	clusters := []string{}
+	paginator := kafka.NewListClustersV2Paginator(svc, &kafka.ListClustersV2Input{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list MSK clusters: %w", err)
+		}
+		for _, cluster := range page.ClusterInfoList {
+			if cluster.ClusterName != nil {
+				clusters = append(clusters, *cluster.ClusterName)
+			}
+		}
+	}
+
+	return clusters, nil
+}
+
+// Look up the ARN of an MSK cluster by name
+func getMSKClusterARN(ctx context.Context, cfg aws.Config, clusterName string) (string, error) {
+	if clusterName == "" {
+		return "", fmt.Errorf("MSK cluster name cannot be empty")
+	}
+	svc := kafka.NewFromConfig(cfg)
+
+	paginator := kafka.NewListClustersV2Paginator(svc, &kafka.ListClustersV2Input{
+		ClusterNameFilter: &clusterName,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up MSK cluster '%s': %w", clusterName, err)
+		}
+		// The filter matches name prefixes, so check for the exact name
+		for _, cluster := range page.ClusterInfoList {
+			if aws.ToString(cluster.ClusterName) == clusterName && cluster.ClusterArn != nil {
+				return *cluster.ClusterArn, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("MSK cluster '%s' not found", clusterName)
+}
+
+// Get the bootstrap brokers of an MSK cluster, preferring plaintext, then TLS, IAM and SCRAM listeners
+func getMSKBrokers(ctx context.Context, cfg aws.Config, clusterARN string) ([]Endpoint, error) {
+	svc := kafka.NewFromConfig(cfg)
+
+	result, err := svc.GetBootstrapBrokers(ctx, &kafka.GetBootstrapBrokersInput{
+		ClusterArn: &clusterARN,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap brokers for %s: %w", clusterARN, err)
+	}
+
+	brokerString := ""
+	for _, candidate := range []*string{
+		result.BootstrapBrokerString,
+		result.BootstrapBrokerStringTls,
+		result.BootstrapBrokerStringSaslIam,
+		result.BootstrapBrokerStringSaslScram,
+	} {
+		if aws.ToString(candidate) != "" {
+			brokerString = *candidate
+			break
+		}
+	}
+	if brokerString == "" {
+		return nil, fmt.Errorf("MSK cluster %s has no bootstrap brokers (may not be active)", clusterARN)
+	}
+
+	brokers := []Endpoint{}
+	for _, broker := range strings.Split(brokerString, ",") {
+		host, portStr, err := net.SplitHostPort(strings.TrimSpace(broker))
+		if err != nil {
+			return nil, fmt.Errorf("invalid broker address '%s': %w", broker, err)
+		}
+		port, err := strconv.ParseInt(portStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid broker port in '%s': %w", broker, err)
+		}
+		brokers = append(brokers, Endpoint{Host: host, Port: int32(port)})
+	}
+
+	return brokers, nil
+}
+
+// kafkaTargets resolves an MSK cluster's brokers to tunnel targets on consecutive local ports from firstLocalPort
+func kafkaTargets(ctx context.Context, cfg aws.Config, clusterName, firstLocalPort string) ([]Target, error) {
+	clusterARN, err := getMSKClusterARN(ctx, cfg, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	brokers, err := getMSKBrokers(ctx, cfg, clusterARN)
+	if err != nil {
+		return nil, err
+	}
+
+	basePort, err := strconv.Atoi(firstLocalPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s': %w", firstLocalPort, err)
+	}
+
+	report("🎯 Connecting to MSK cluster: %s (%d brokers)\n", clusterName, len(brokers))
+	targets := make([]Target, 0, len(brokers))
+	for i, broker := range brokers {
+		localPort := strconv.Itoa(basePort + i)
+		if err := ValidatePort(localPort); err != nil {
+			return nil, fmt.Errorf("broker %s: %w", broker.Host, err)
+		}
+		targets = append(targets, Target{
+			ServiceType:  "kafka",
+			ResourceName: broker.Host,
+			Endpoint:     broker.Host,
+			Port:         broker.Port,
+			LocalPort:    localPort,
+		})
+	}
+
+	return targets, nil
+}
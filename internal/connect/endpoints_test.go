@@ -0,0 +1,124 @@
+package connect
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	elasticachetypes "github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// fakeRDS serves DescribeDBInstances one page at a time, the marker being the index of the next page.
+// Looking up an identifier returns the instances with it from any page, or err if set.
+type fakeRDS struct {
+	pages [][]rdstypes.DBInstance
+	err   error
+	calls int
+}
+
+func (f *fakeRDS) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if params.DBInstanceIdentifier != nil {
+		return &rds.DescribeDBInstancesOutput{DBInstances: fakeLookup(f.pages, func(db rdstypes.DBInstance) bool {
+			return aws.ToString(db.DBInstanceIdentifier) == *params.DBInstanceIdentifier
+		})}, nil
+	}
+	page, next := fakePage(f.pages, params.Marker)
+	return &rds.DescribeDBInstancesOutput{DBInstances: page, Marker: next}, nil
+}
+
+// fakeElastiCache serves DescribeReplicationGroups the same way as fakeRDS
+type fakeElastiCache struct {
+	pages [][]elasticachetypes.ReplicationGroup
+	err   error
+	calls int
+}
+
+func (f *fakeElastiCache) DescribeReplicationGroups(ctx context.Context, params *elasticache.DescribeReplicationGroupsInput, optFns ...func(*elasticache.Options)) (*elasticache.DescribeReplicationGroupsOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if params.ReplicationGroupId != nil {
+		return &elasticache.DescribeReplicationGroupsOutput{ReplicationGroups: fakeLookup(f.pages, func(group elasticachetypes.ReplicationGroup) bool {
+			return aws.ToString(group.ReplicationGroupId) == *params.ReplicationGroupId
+		})}, nil
+	}
+	page, next := fakePage(f.pages, params.Marker)
+	return &elasticache.DescribeReplicationGroupsOutput{ReplicationGroups: page, Marker: next}, nil
+}
+
+// fakePage returns the page marker points at and the marker of the one after it, nil on the last page
+func fakePage[T any](pages [][]T, marker *string) ([]T, *string) {
+	i := 0
+	if marker != nil {
+		i, _ = strconv.Atoi(*marker)
+	}
+	if i >= len(pages) {
+		return nil, nil
+	}
+	if i+1 < len(pages) {
+		return pages[i], aws.String(strconv.Itoa(i + 1))
+	}
+	return pages[i], nil
+}
+
+// fakeLookup returns the items of all pages that match
+func fakeLookup[T any](pages [][]T, match func(T) bool) []T {
+	var found []T
+	for _, page := range pages {
+		for _, item := range page {
+			if match(item) {
+				found = append(found, item)
+			}
+		}
+	}
+	return found
+}
+
+func TestListRDSInstancesGathersAllPages(t *testing.T) {
+	client := &fakeRDS{pages: [][]rdstypes.DBInstance{
+		{{DBInstanceIdentifier: aws.String("orders")}, {DBInstanceIdentifier: aws.String("users")}},
+		{{DBInstanceIdentifier: aws.String("billing")}},
+		{{DBInstanceIdentifier: aws.String("audit")}},
+	}}
+
+	instances, err := listRDSInstances(context.Background(), client)
+	if err != nil {
+		t.Fatalf("listRDSInstances: %v", err)
+	}
+	want := []string{"orders", "users", "billing", "audit"}
+	if !slices.Equal(instances, want) {
+		t.Errorf("got %v, want %v", instances, want)
+	}
+	if client.calls != 3 {
+		t.Errorf("got %d DescribeDBInstances calls, want 3", client.calls)
+	}
+}
+
+func TestListRedisClustersGathersAllPages(t *testing.T) {
+	client := &fakeElastiCache{pages: [][]elasticachetypes.ReplicationGroup{
+		{{ReplicationGroupId: aws.String("sessions")}},
+		{{ReplicationGroupId: aws.String("cache")}, {ReplicationGroupId: aws.String("queues")}},
+	}}
+
+	clusters, err := listRedisClusters(context.Background(), client)
+	if err != nil {
+		t.Fatalf("listRedisClusters: %v", err)
+	}
+	want := []string{"sessions", "cache", "queues"}
+	if !slices.Equal(clusters, want) {
+		t.Errorf("got %v, want %v", clusters, want)
+	}
+	if client.calls != 2 {
+		t.Errorf("got %d DescribeReplicationGroups calls, want 2", client.calls)
+	}
+}
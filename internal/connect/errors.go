@@ -0,0 +1,43 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultAWSTimeout bounds each AWS lookup made while connecting
+const DefaultAWSTimeout = 15 * time.Second
+
+// TimeoutError reports an AWS call that didn't finish before its context's deadline,
+// usually a network, VPN or proxy problem rather than a missing resource
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return "timed out talking to AWS"
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// AWSError explains a failed AWS call made with ctx: a TimeoutError if ctx's deadline passed,
+// a cancellation error if ctx was cancelled (e.g. Ctrl+C), and err unchanged otherwise
+func AWSError(ctx context.Context, err error) error {
+	var timeoutErr *TimeoutError
+	if err == nil || errors.As(err, &timeoutErr) {
+		return err
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return &TimeoutError{Err: err}
+	case context.Canceled:
+		return fmt.Errorf("cancelled before AWS replied: %w", context.Canceled)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,141 @@
+package connect
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	// healthWindow is how many of the latest keep alive checks the success rate and average cover
+	healthWindow = 20
+	// A check is a latency spike when it takes spikeFactor times the average and spikeMinimum longer
+	spikeFactor  = 3
+	spikeMinimum = 50 * time.Millisecond
+	// spikeSamples successful checks are needed before the average is trusted
+	spikeSamples = 3
+)
+
+// healthMu keeps the tunnels of a session from drawing over each other's status line
+var healthMu sync.Mutex
+
+type healthSample struct {
+	latency time.Duration
+	ok      bool
+}
+
+// healthMonitor shows how the keep alive checks of a tunnel went for --watch: the latency of the
+// last check, the average and success rate of recent ones, and latency spikes. On a terminal it
+// redraws a single status line, otherwise it prints a line per check. A nil monitor shows nothing.
+type healthMonitor struct {
+	label   string
+	inPlace bool
+	drawn   bool
+	samples []healthSample // The latest healthWindow checks, oldest first
+}
+
+func newHealthMonitor(label string) *healthMonitor {
+	return &healthMonitor{
+		label:   label,
+		inPlace: isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()),
+	}
+}
+
+// record adds the result of a check that took latency and shows the updated health
+func (m *healthMonitor) record(latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+
+	average, successes := m.average()
+	m.samples = append(m.samples, healthSample{latency: latency, ok: err == nil})
+	if len(m.samples) > healthWindow {
+		m.samples = m.samples[1:]
+	}
+
+	if err == nil && successes >= spikeSamples && latency > spikeFactor*average && latency-average >= spikeMinimum {
+		m.clearLine()
+		report("⚠️ Latency spike on %s: %s (average %s)\n", m.label, formatLatency(latency), formatLatency(average))
+	}
+
+	status := m.status(latency, err)
+	if !m.inPlace {
+		report("📶 %s\n", status)
+		return
+	}
+	// Drawn directly, a status line redrawn every check doesn't belong in the log file
+	m.clearLine()
+	fmt.Printf("%s %s", output.Icon("📶"), status)
+	m.drawn = true
+}
+
+// interrupt removes the status line so a message can be printed in its place
+func (m *healthMonitor) interrupt() {
+	if m == nil {
+		return
+	}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	m.clearLine()
+}
+
+// finish ends the status line so later output starts on a line of its own
+func (m *healthMonitor) finish() {
+	if m == nil {
+		return
+	}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	if m.drawn {
+		fmt.Println()
+		m.drawn = false
+	}
+}
+
+func (m *healthMonitor) clearLine() {
+	if m.inPlace {
+		fmt.Print("\r\033[K")
+		m.drawn = false
+	}
+}
+
+// average is the mean latency of the successful checks in the window and how many there were
+func (m *healthMonitor) average() (time.Duration, int) {
+	var total time.Duration
+	successes := 0
+	for _, sample := range m.samples {
+		if sample.ok {
+			total += sample.latency
+			successes++
+		}
+	}
+	if successes == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(successes), successes
+}
+
+// status describes the health after a check, e.g. "127.0.0.1:5432 12.3ms (avg 10.1ms) 19/20 ok (95%)"
+func (m *healthMonitor) status(latency time.Duration, err error) string {
+	last := formatLatency(latency)
+	if err != nil {
+		last = "failed"
+	}
+	average, successes := m.average()
+	avg := "-"
+	if successes > 0 {
+		avg = formatLatency(average)
+	}
+	return fmt.Sprintf("%s %s (avg %s) %d/%d ok (%d%%)", m.label, last, avg, successes, len(m.samples), successes*100/len(m.samples))
+}
+
+// formatLatency shows a latency in milliseconds, which is the scale tunnel checks take
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,99 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/events"
+)
+
+// Start keep alive when SSM tunnel becomes ready (no arbitrary delay).
+// It returns an error once maxFailures checks in a row have failed, see startKeepAlive.
+func startKeepAliveWhenReady(ctx context.Context, address string, interval time.Duration, maxFailures int, probe keepAliveProbe, counters *sessionCounters, monitor *healthMonitor) error {
+	if !waitForTunnel(ctx, address) {
+		if ctx.Err() == nil {
+			report("⚠️ Keep alive disabled - SSM tunnel did not become ready within 30 seconds\n")
+		}
+		return nil
+	}
+	return startKeepAlive(ctx, address, interval, maxFailures, probe, counters, monitor)
+}
+
+// waitForTunnel polls address every 500ms until it accepts connections, for up to 30 seconds.
+// It reports false if the tunnel never became ready or ctx was cancelled first.
+func waitForTunnel(ctx context.Context, address string) bool {
+	maxAttempts := 60 // 30 seconds with 500ms intervals
+	for range maxAttempts {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+		}
+
+		if err := PerformKeepAlive(address); err == nil {
+			return true
+		}
+
+		// Wait 500ms before retrying
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	return false
+}
+
+// Keep alive functionality. A failed check is only logged, a momentary blip shouldn't stop the
+// connection, but after maxFailures in a row the tunnel is declared dead and an error returned.
+// With maxFailures 0 it runs until ctx is cancelled. Each check is shown on monitor, if set.
+func startKeepAlive(ctx context.Context, address string, interval time.Duration, maxFailures int, probe keepAliveProbe, counters *sessionCounters, monitor *healthMonitor) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer monitor.finish()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			checkStarted := time.Now()
+			err := probe(address)
+			latency := time.Since(checkStarted)
+			if err != nil {
+				failures++
+				monitor.interrupt()
+				report("⚠️ Keep alive check failed: %v\n", err)
+				counters.addKeepAliveFailure()
+				monitor.record(latency, err)
+				if maxFailures > 0 && failures >= maxFailures {
+					return fmt.Errorf("tunnel stopped responding, %d keep alive checks failed in a row (last: %w)", failures, err)
+				}
+			} else {
+				failures = 0
+				slog.Debug("keep alive check succeeded", "address", address, "duration", latency)
+				events.Emit(events.Event{Type: events.KeepAliveOK, LocalAddress: address})
+				monitor.record(latency, nil)
+			}
+		}
+	}
+}
+
+// Perform a keep alive check by attempting a TCP connection to the local port
+func PerformKeepAlive(address string) error {
+	// Simple TCP connection test to keep the SSM tunnel alive
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close() // Ignore error - this is cleanup
+	}()
+
+	// Connection successful - SSM tunnel is alive
+	return nil
+}
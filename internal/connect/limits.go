@@ -0,0 +1,51 @@
+package connect
+
+import (
+	"context"
+	"time"
+)
+
+// enforceSessionLimits closes the session once it reaches opts.MaxDuration or has had no client
+// traffic for opts.IdleTimeout, printing which limit closed it
+func enforceSessionLimits(ctx context.Context, cancel context.CancelFunc, opts TunnelOptions) {
+	if opts.MaxDuration == 0 && opts.IdleTimeout == 0 {
+		return
+	}
+
+	var deadline <-chan time.Time
+	if opts.MaxDuration > 0 {
+		timer := time.NewTimer(opts.MaxDuration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var idleCheck <-chan time.Time
+	if opts.IdleTimeout > 0 {
+		ticker := time.NewTicker(idleCheckInterval(opts.IdleTimeout))
+		defer ticker.Stop()
+		idleCheck = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			report("⏱️ Closing connection: it reached the maximum duration of %v\n", opts.MaxDuration)
+			cancel()
+			return
+		case <-idleCheck:
+			if opts.counters.idleFor() >= opts.IdleTimeout {
+				report("💤 Closing connection: no client traffic for %v\n", opts.IdleTimeout)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// idleCheckInterval checks often enough to close an idle session close to its timeout,
+// but no more than once a second or less than once a minute
+func idleCheckInterval(timeout time.Duration) time.Duration {
+	return min(max(timeout/10, time.Second), time.Minute)
+}
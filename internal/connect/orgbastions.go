@@ -0,0 +1,154 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/b3nk3/bifrost/internal/sso"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultBastionTag is the tag FindBastionsInAccounts looks for on bastions, as key=value
+const DefaultBastionTag = "Role=bastion"
+
+// maxAccountLookups bounds how many accounts FindBastionsInAccounts searches at once
+const maxAccountLookups = 8
+
+// ErrNoTaggedBastions is returned by FindBastionsInAccounts when no account has an online bastion with the tag
+var ErrNoTaggedBastions = errors.New("no online SSM managed instances with the bastion tag found in any account")
+
+// AccountBastion is an online bastion found in one of the accounts searched by FindBastionsInAccounts
+type AccountBastion struct {
+	AccountID   string
+	AccountName string
+	InstanceID  string
+	Name        string // Name tag of EC2 bastions, empty without one
+}
+
+// Label describes the bastion with the account it is in, e.g. "jump (i-0abc…) in prod (123456789012)"
+func (b AccountBastion) Label() string {
+	bastion := b.InstanceID
+	if b.Name != "" {
+		bastion = fmt.Sprintf("%s (%s)", b.Name, b.InstanceID)
+	}
+	return fmt.Sprintf("%s in %s (%s)", bastion, b.AccountName, b.AccountID)
+}
+
+// ParseBastionTag splits a key=value tag, e.g. DefaultBastionTag
+func ParseBastionTag(tag string) (key, value string, err error) {
+	key, value, ok := strings.Cut(tag, "=")
+	if !ok || key == "" || value == "" {
+		return "", "", fmt.Errorf("invalid bastion tag '%s', expected key=value (e.g. %s)", tag, DefaultBastionTag)
+	}
+	return key, value, nil
+}
+
+// FindBastionsInAccounts signs into each of the accounts with roleName and lists the online SSM managed
+// instances tagged tagKey=tagValue in region, sorted by account name. Each account's lookup gets
+// timeout. Accounts without the role, or where the lookup is denied or times out, are skipped: only
+// the accounts the role reaches are relevant.
+func FindBastionsInAccounts(ctx context.Context, client *sso.Client, token *ssooidc.CreateTokenOutput, accounts []ssotypes.AccountInfo, roleName, region, tagKey, tagValue string, timeout time.Duration) (bastions []AccountBastion, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	found := make([][]AccountBastion, len(accounts))
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxAccountLookups)
+	for i, account := range accounts {
+		g.Go(func() error {
+			accountID := aws.ToString(account.AccountId)
+			accountCtx, cancel := context.WithTimeout(groupCtx, timeout)
+			defer cancel()
+			ids, names, err := accountBastions(accountCtx, client, token, accountID, roleName, region, tagKey, tagValue)
+			if err != nil {
+				slog.Debug("skipping account in bastion search", "account_id", accountID, "error", err)
+				return nil
+			}
+			for _, id := range ids {
+				found[i] = append(found[i], AccountBastion{
+					AccountID:   accountID,
+					AccountName: aws.ToString(account.AccountName),
+					InstanceID:  id,
+					Name:        names[id],
+				})
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for _, accountBastions := range found {
+		bastions = append(bastions, accountBastions...)
+	}
+	if len(bastions) == 0 {
+		return nil, ErrNoTaggedBastions
+	}
+	slices.SortStableFunc(bastions, func(a, b AccountBastion) int {
+		return strings.Compare(strings.ToLower(a.AccountName), strings.ToLower(b.AccountName))
+	})
+	return bastions, nil
+}
+
+// accountBastions lists the online tagged bastions of one account, with the Name tag of EC2 instances by ID
+func accountBastions(ctx context.Context, client *sso.Client, token *ssooidc.CreateTokenOutput, accountID, roleName, region, tagKey, tagValue string) ([]string, map[string]string, error) {
+	roleCreds, err := client.GetRoleCredentials(ctx, token, accountID, roleName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get credentials for role %s: %w", roleName, err)
+	}
+	cfg, err := NewAWSConfig(roleCreds.RoleCredentials, region, RoleChain{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids, ec2IDs []string
+	paginator := ssm.NewDescribeInstanceInformationPaginator(ssm.NewFromConfig(cfg), &ssm.DescribeInstanceInformationInput{
+		Filters: []types.InstanceInformationStringFilter{
+			{Key: aws.String("tag:" + tagKey), Values: []string{tagValue}},
+			{Key: aws.String("PingStatus"), Values: []string{string(types.PingStatusOnline)}},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list SSM managed instances: %w", err)
+		}
+		for _, instance := range page.InstanceInformationList {
+			id := aws.ToString(instance.InstanceId)
+			ids = append(ids, id)
+			if strings.HasPrefix(id, "i-") {
+				ec2IDs = append(ec2IDs, id)
+			}
+		}
+	}
+
+	// Names only make the list easier to read, so bastions are still offered by ID if EC2 can't be asked
+	names := make(map[string]string)
+	if len(ec2IDs) > 0 {
+		result, err := ec2.NewFromConfig(cfg).DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: ec2IDs})
+		if err == nil {
+			for _, reservation := range result.Reservations {
+				for _, instance := range reservation.Instances {
+					for _, tag := range instance.Tags {
+						if aws.ToString(tag.Key) == "Name" {
+							names[aws.ToString(instance.InstanceId)] = aws.ToString(tag.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+	return ids, names, nil
+}
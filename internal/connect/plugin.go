@@ -0,0 +1,317 @@
+package connect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+// pluginDownloadURL is where AWS publishes the latest Session Manager plugin builds
+const pluginDownloadURL = "https://s3.amazonaws.com/session-manager-downloads/plugin/latest/"
+
+// ErrPluginInstallUnsupported is returned by InstallPlugin on platforms where only the manual install works
+var ErrPluginInstallUnsupported = errors.New("installing the Session Manager plugin is not supported on this platform")
+
+// pluginPackage is the Session Manager plugin build for a platform
+type pluginPackage struct {
+	url     string
+	command string // Installs the plugin system-wide, as the AWS docs describe
+}
+
+// pluginPackageFor picks the plugin build for the OS and architecture, using the rpm on Linux
+// systems without dpkg
+func pluginPackageFor(goos, goarch string, hasDpkg bool) (pluginPackage, bool) {
+	switch goos {
+	case "darwin":
+		dir := "mac"
+		if goarch == "arm64" {
+			dir = "mac_arm64"
+		}
+		url := pluginDownloadURL + dir + "/sessionmanager-bundle.zip"
+		return pluginPackage{
+			url: url,
+			command: fmt.Sprintf(`curl "%s" -o "sessionmanager-bundle.zip" && unzip sessionmanager-bundle.zip && `+
+				`sudo ./sessionmanager-bundle/install -i /usr/local/sessionmanagerplugin -b /usr/local/bin/session-manager-plugin`, url),
+		}, true
+	case "linux":
+		if goarch != "amd64" && goarch != "arm64" {
+			return pluginPackage{}, false
+		}
+		if hasDpkg {
+			dir := "ubuntu_64bit"
+			if goarch == "arm64" {
+				dir = "ubuntu_arm64"
+			}
+			url := pluginDownloadURL + dir + "/session-manager-plugin.deb"
+			return pluginPackage{
+				url:     url,
+				command: fmt.Sprintf(`curl "%s" -o "session-manager-plugin.deb" && sudo dpkg -i session-manager-plugin.deb`, url),
+			}, true
+		}
+		dir := "linux_64bit"
+		if goarch == "arm64" {
+			dir = "linux_arm64"
+		}
+		url := pluginDownloadURL + dir + "/session-manager-plugin.rpm"
+		return pluginPackage{
+			url:     url,
+			command: fmt.Sprintf(`sudo yum install -y %s`, url),
+		}, true
+	case "windows":
+		url := pluginDownloadURL + "windows/SessionManagerPluginSetup.exe"
+		return pluginPackage{
+			url:     url,
+			command: fmt.Sprintf(`Invoke-WebRequest "%s" -OutFile SessionManagerPluginSetup.exe; .\SessionManagerPluginSetup.exe`, url),
+		}, true
+	}
+	return pluginPackage{}, false
+}
+
+func currentPluginPackage() (pluginPackage, bool) {
+	_, err := exec.LookPath("dpkg")
+	return pluginPackageFor(runtime.GOOS, runtime.GOARCH, err == nil)
+}
+
+// PluginInstallCommand returns the command that installs the Session Manager plugin on this
+// OS and architecture, or the link to the install instructions if AWS publishes no build for it
+func PluginInstallCommand() string {
+	pkg, ok := currentPluginPackage()
+	if !ok {
+		return ssmPluginInstallHint
+	}
+	return pkg.command
+}
+
+// PluginInstalled reports whether the Session Manager plugin is on PATH or in PluginDir
+func PluginInstalled() bool {
+	usePluginDir()
+	_, err := exec.LookPath(sessionManagerPluginName)
+	return err == nil
+}
+
+// CanInstallPlugin reports whether InstallPlugin can install the plugin on this platform:
+// from the macOS bundle or the Debian package, without root
+func CanInstallPlugin() bool {
+	pkg, ok := currentPluginPackage()
+	return ok && (strings.HasSuffix(pkg.url, ".zip") || strings.HasSuffix(pkg.url, ".deb"))
+}
+
+// PluginDir is the user-writable directory InstallPlugin puts the plugin in
+func PluginDir() (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bin"), nil
+}
+
+// usePluginDir puts PluginDir on PATH when InstallPlugin installed the plugin there, so that the
+// AWS CLI finds it too
+func usePluginDir() {
+	dir, err := PluginDir()
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, pluginBinaryName())); err != nil {
+		return
+	}
+	path := os.Getenv("PATH")
+	for _, entry := range filepath.SplitList(path) {
+		if entry == dir {
+			return
+		}
+	}
+	_ = os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+}
+
+func pluginBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return sessionManagerPluginName + ".exe"
+	}
+	return sessionManagerPluginName
+}
+
+// InstallPlugin downloads the Session Manager plugin for this platform with client and installs it
+// into PluginDir, returning its path and who signed it. Nothing is installed unless the signature
+// checks out: the Debian package's with gpg, the macOS binary's with codesign. Later prerequisite
+// checks put the directory on PATH.
+func InstallPlugin(ctx context.Context, client *http.Client) (path, signer string, err error) {
+	pkg, ok := currentPluginPackage()
+	if !ok || !CanInstallPlugin() {
+		return "", "", ErrPluginInstallUnsupported
+	}
+
+	data, err := download(ctx, client, pkg.url)
+	if err != nil {
+		return "", "", err
+	}
+
+	isZip := strings.HasSuffix(pkg.url, ".zip")
+	var binary []byte
+	if isZip {
+		binary, err = pluginFromZip(data)
+	} else {
+		if signer, err = verifyDebSignature(ctx, client, pkg.url, data); err != nil {
+			return "", "", err
+		}
+		binary, err = pluginFromDeb(data)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to unpack %s: %w", pkg.url, err)
+	}
+
+	dir, err := PluginDir()
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	// Write next to the destination and rename, so a failed install leaves no half-written plugin
+	path = filepath.Join(dir, pluginBinaryName())
+	tmp, err := os.CreateTemp(dir, sessionManagerPluginName+"-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", "", err
+	}
+	// The macOS bundle itself isn't signed, the binary in it is
+	if isZip {
+		if signer, err = verifyCodeSignature(ctx, tmp.Name()); err != nil {
+			return "", "", err
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", "", err
+	}
+
+	usePluginDir()
+	return path, signer, nil
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isPluginBinary matches the plugin executable inside the AWS packages, e.g.
+// sessionmanager-bundle/bin/session-manager-plugin or ./usr/local/sessionmanagerplugin/bin/session-manager-plugin
+func isPluginBinary(name string) bool {
+	return strings.HasSuffix(name, "bin/"+sessionManagerPluginName)
+}
+
+// pluginFromZip extracts the plugin from the macOS bundle
+func pluginFromZip(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range archive.File {
+		if !isPluginBinary(file.Name) {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = rc.Close()
+		}()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in the bundle", sessionManagerPluginName)
+}
+
+// pluginFromDeb extracts the plugin from the Debian package: an ar archive whose data.tar.gz
+// member holds the installed files
+func pluginFromDeb(data []byte) ([]byte, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != "!<arch>\n" {
+		return nil, fmt.Errorf("not a Debian package")
+	}
+
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, fmt.Errorf("no data.tar.gz in the package")
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt package member header")
+		}
+
+		if strings.HasPrefix(name, "data.tar") {
+			if name != "data.tar.gz" {
+				return nil, fmt.Errorf("unsupported package compression %s", name)
+			}
+			return pluginFromTarGz(io.LimitReader(r, size))
+		}
+
+		// Members are padded to an even size
+		if _, err := r.Discard(int(size + size%2)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func pluginFromTarGz(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in the package", sessionManagerPluginName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && isPluginBinary(header.Name) {
+			return io.ReadAll(tr)
+		}
+	}
+}
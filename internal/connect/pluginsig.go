@@ -0,0 +1,119 @@
+package connect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPluginUnverified is returned by InstallPlugin when the downloaded plugin's signature can't be checked
+var ErrPluginUnverified = errors.New("the downloaded Session Manager plugin could not be verified")
+
+// pluginSigningKey is the fingerprint of the key AWS signs the Session Manager plugin's Linux packages
+// with, as given in the AWS docs (pluginInstallDocs). Only a signature by this key is accepted,
+// whatever else the user's keyring holds; it needs updating when AWS rotates the key.
+const pluginSigningKey = "8108A07A9EBE248E3F1C63F254F4F56E693ECA21"
+
+// pluginTeamID is the Apple Developer ID team AWS signs the macOS plugin binary with
+const pluginTeamID = "94KV3E626L"
+
+// verifyDebSignature checks the Debian package against the detached signature AWS publishes next to
+// it, with gpg and the Session Manager plugin key the AWS docs have users import. It returns who
+// signed it.
+func verifyDebSignature(ctx context.Context, client *http.Client, url string, data []byte) (string, error) {
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		return "", fmt.Errorf("%w: gpg is needed to check the package's signature", ErrPluginUnverified)
+	}
+	signature, err := download(ctx, client, url+".sig")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPluginUnverified, err)
+	}
+
+	dir, err := os.MkdirTemp("", "bifrost-plugin-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	pkgPath := filepath.Join(dir, filepath.Base(url))
+	sigPath := pkgPath + ".sig"
+	if err := os.WriteFile(pkgPath, data, 0600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(sigPath, signature, 0600); err != nil {
+		return "", err
+	}
+
+	signer, err := gpgVerify(ctx, gpg, dir, pkgPath, sigPath, pluginSigningKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrPluginUnverified, url, err)
+	}
+	return signer, nil
+}
+
+// gpgVerify checks sigPath is a signature of pkgPath by the key with fingerprint, returning its user
+// ID. The key is taken from the user's keyring into a keyring of its own in dir, so that no other key
+// the user trusts can vouch for the package.
+func gpgVerify(ctx context.Context, gpg, dir, pkgPath, sigPath, fingerprint string) (string, error) {
+	key, err := exec.CommandContext(ctx, gpg, "--batch", "--export", fingerprint).Output()
+	if err != nil || len(key) == 0 {
+		return "", fmt.Errorf("the Session Manager plugin key %s isn't in your keyring, import it as the AWS docs show (%s)", fingerprint, pluginInstallDocs)
+	}
+	keyring := filepath.Join(dir, "session-manager-plugin.gpg")
+	if err := os.WriteFile(keyring, key, 0600); err != nil {
+		return "", err
+	}
+
+	// gpg reports the result on the status file descriptor in a stable format, unlike its messages
+	out, err := exec.CommandContext(ctx, gpg, "--batch", "--no-default-keyring", "--keyring", keyring,
+		"--trust-model", "always", "--status-fd", "1", "--verify", sigPath, pkgPath).Output()
+	signer, valid := "", false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "GOODSIG":
+			signer = strings.Join(fields[3:], " ")
+		case "VALIDSIG":
+			// The signing subkey's fingerprint comes first and the primary key's last
+			valid = strings.EqualFold(fields[2], fingerprint) || strings.EqualFold(fields[len(fields)-1], fingerprint)
+		}
+	}
+	if err != nil || !valid || signer == "" {
+		return "", fmt.Errorf("the signature is not a valid one by the Session Manager plugin key %s", fingerprint)
+	}
+	return signer, nil
+}
+
+// verifyCodeSignature checks that the macOS binary at path carries an intact Developer ID signature
+// from AWS's team, returning who signed it
+func verifyCodeSignature(ctx context.Context, path string) (string, error) {
+	requirement := fmt.Sprintf(`-R=anchor apple generic and certificate leaf[subject.OU] = "%s"`, pluginTeamID)
+	if out, err := exec.CommandContext(ctx, "codesign", "--verify", "--strict", requirement, path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w: not signed by the AWS developer team %s: %s", ErrPluginUnverified, pluginTeamID, strings.TrimSpace(string(out)))
+	}
+
+	// codesign describes the signature on stderr, the first Authority being the signing certificate
+	out, err := exec.CommandContext(ctx, "codesign", "--display", "--verbose=2", path).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrPluginUnverified, strings.TrimSpace(string(out)))
+	}
+	for line := range strings.Lines(string(out)) {
+		if authority, ok := strings.CutPrefix(strings.TrimSpace(line), "Authority="); ok {
+			return authority, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no signing authority found", ErrPluginUnverified)
+}
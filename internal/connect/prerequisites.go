@@ -0,0 +1,87 @@
+package connect
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// Install hints for the tools SSM port forwarding shells out to
+const (
+	awsCLIInstallHint        = "Install the AWS CLI v2: https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html"
+	pluginInstallDocs        = "https://docs.aws.amazon.com/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html"
+	ssmPluginInstallHint     = "Install the Session Manager plugin: " + pluginInstallDocs
+	sessionManagerPluginName = "session-manager-plugin"
+)
+
+// PrerequisiteError reports tools that must be installed before an SSM session can start
+type PrerequisiteError struct {
+	Missing []string
+	Hints   []string
+}
+
+func (e *PrerequisiteError) Error() string {
+	return fmt.Sprintf("missing prerequisites: %s\n  %s", strings.Join(e.Missing, ", "), strings.Join(e.Hints, "\n  "))
+}
+
+// Prerequisite is a tool SSM port forwarding shells out to, with its version if it was found
+type Prerequisite struct {
+	Name    string
+	Version string
+	Hint    string
+	Err     error
+}
+
+// Prerequisites looks up the AWS CLI and Session Manager plugin and their versions
+func Prerequisites() []Prerequisite {
+	usePluginDir()
+	tools := []Prerequisite{
+		{Name: "aws", Hint: awsCLIInstallHint},
+		{Name: sessionManagerPluginName, Hint: ssmPluginInstallHint},
+	}
+	for i := range tools {
+		tools[i].Version, tools[i].Err = toolVersion(tools[i].Name, "--version")
+		if tools[i].Err != nil {
+			slog.Debug("prerequisite check failed", "tool", tools[i].Name, "error", tools[i].Err)
+		}
+	}
+	return tools
+}
+
+// CheckPrerequisites verifies the AWS CLI and Session Manager plugin are on PATH and prints their versions
+func CheckPrerequisites() error {
+	missing := &PrerequisiteError{}
+	versions := make([]string, 0, 2)
+	for _, tool := range Prerequisites() {
+		if tool.Err != nil {
+			missing.Missing = append(missing.Missing, tool.Name)
+			missing.Hints = append(missing.Hints, tool.Hint)
+			continue
+		}
+		versions = append(versions, tool.Version)
+	}
+
+	if len(missing.Missing) > 0 {
+		return missing
+	}
+
+	report("🧰 %s, session-manager-plugin %s\n", versions[0], versions[1])
+	return nil
+}
+
+// toolVersion looks up a binary on PATH and returns the first line of its version output
+func toolVersion(name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := exec.Command(path, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s %s: %w", name, strings.Join(args, " "), err)
+	}
+
+	version, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return version, nil
+}
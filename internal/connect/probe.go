@@ -0,0 +1,127 @@
+package connect
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Supported values for --keep-alive-probe
+const (
+	KeepAliveProbeTCP      = "tcp"
+	KeepAliveProbeProtocol = "protocol"
+)
+
+var KeepAliveProbeModes = []string{KeepAliveProbeTCP, KeepAliveProbeProtocol}
+
+// keepAliveProbe checks that a tunnel is healthy through its local address
+type keepAliveProbe func(address string) error
+
+// protocolProbes talk to the remote service itself, proving the tunnel reaches it.
+// Service types without an entry fall back to the TCP check.
+var protocolProbes = map[string]keepAliveProbe{
+	"rds":   probeSQL,
+	"redis": probeRedis,
+}
+
+// keepAliveProbeFor picks the keep alive check for a service type and probe mode. tlsRequired tells
+// the service only accepts TLS clients, which only Redis clusters with in-transit encryption do.
+func keepAliveProbeFor(serviceType, mode string, tlsRequired bool) keepAliveProbe {
+	if mode == KeepAliveProbeProtocol {
+		if serviceType == "redis" && tlsRequired {
+			return probeRedisTLS
+		}
+		if probe, ok := protocolProbes[serviceType]; ok {
+			return probe
+		}
+	}
+	return PerformKeepAlive
+}
+
+// probeRedis sends PING and expects +PONG. An authentication error also proves the server answered.
+func probeRedis(address string) error {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close() // Ignore error - this is cleanup
+	}()
+	return pingRedis(conn)
+}
+
+// probeRedisTLS is probeRedis for clusters with in-transit encryption, which drop plaintext clients.
+// The certificate is issued for the cluster's hostname rather than the local address, and the probe
+// only needs the handshake to reach the server, so it isn't verified.
+func probeRedisTLS(address string) error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s with TLS: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close() // Ignore error - this is cleanup
+	}()
+	return pingRedis(conn)
+}
+
+// pingRedis sends PING over conn and checks the reply
+func pingRedis(conn net.Conn) error {
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return fmt.Errorf("failed to send PING: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("no reply to PING through the tunnel: %w", err)
+	}
+
+	reply = strings.TrimSpace(reply)
+	if reply == "+PONG" || strings.HasPrefix(reply, "-NOAUTH") {
+		return nil
+	}
+	return fmt.Errorf("unexpected reply to PING: %q", reply)
+}
+
+// postgresSSLRequest is the 8 byte SSLRequest message, which Postgres answers with a single byte
+var postgresSSLRequest = []byte{0x00, 0x00, 0x00, 0x08, 0x04, 0xd2, 0x16, 0x2f}
+
+// probeSQL waits for the MySQL server greeting, or asks a Postgres server whether it supports SSL
+func probeSQL(address string) error {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer func() {
+		_ = conn.Close() // Ignore error - this is cleanup
+	}()
+
+	buf := make([]byte, 1)
+
+	// MySQL and MariaDB speak first
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if n, err := conn.Read(buf); n > 0 {
+		return nil
+	} else if !errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("connection closed before the database answered: %w", err)
+	}
+
+	// Postgres waits for the client
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(postgresSSLRequest); err != nil {
+		return fmt.Errorf("failed to send SSL request: %w", err)
+	}
+	if _, err := conn.Read(buf); err != nil {
+		return fmt.Errorf("no reply from the database through the tunnel: %w", err)
+	}
+	if buf[0] != 'S' && buf[0] != 'N' {
+		return fmt.Errorf("unexpected reply from the database: %q", buf[0])
+	}
+	return nil
+}
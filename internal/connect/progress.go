@@ -0,0 +1,38 @@
+package connect
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Reporter receives the status lines the package reports while connecting, like the endpoint a
+// resource resolved to, a role it assumed or a reconnect. format and a are as for fmt.Printf.
+type Reporter func(format string, a ...any)
+
+var (
+	reporterMu sync.Mutex
+	reporter   Reporter
+)
+
+// SetReporter sends the package's status lines to r. Until it's called they are logged with slog at
+// info level, so tools embedding the package decide what reaches their users; the bifrost CLI prints them.
+func SetReporter(r Reporter) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reporter = r
+}
+
+// report hands a status line to the reporter
+func report(format string, a ...any) {
+	reporterMu.Lock()
+	r := reporter
+	reporterMu.Unlock()
+
+	if r == nil {
+		slog.Info(strings.TrimSpace(fmt.Sprintf(format, a...)))
+		return
+	}
+	r(format, a...)
+}
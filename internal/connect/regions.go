@@ -0,0 +1,79 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	elasticachetypes "github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// RegionSearchServices are the service types whose resources FindResourceRegion can look for
+var RegionSearchServices = []string{"rds", "redis"}
+
+// ErrResourceNotFound is returned by FindResourceRegion when none of the regions has the resource
+var ErrResourceNotFound = errors.New("resource not found in any of the regions")
+
+// maxRegionLookups bounds how many regions FindResourceRegion asks at once
+const maxRegionLookups = 8
+
+// ResourceExists reports whether the RDS instance or Redis cluster exists in cfg's region.
+// Other errors, e.g. missing permissions, are returned as they are.
+func ResourceExists(ctx context.Context, cfg aws.Config, serviceType, name string) (exists bool, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	switch serviceType {
+	case "rds":
+		_, err = rds.NewFromConfig(cfg).DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: aws.String(name),
+		})
+	case "redis":
+		_, err = elasticache.NewFromConfig(cfg).DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+			ReplicationGroupId: aws.String(name),
+		})
+	default:
+		return false, fmt.Errorf("looking up %s resources by region is not supported", serviceType)
+	}
+
+	var rdsNotFound *rdstypes.DBInstanceNotFoundFault
+	var redisNotFound *elasticachetypes.ReplicationGroupNotFoundFault
+	if errors.As(err, &rdsNotFound) || errors.As(err, &redisNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindResourceRegion looks for the RDS instance or Redis cluster in each of the regions at once and
+// returns the first of them, in the order given, that has it. Regions that can't be asked (e.g. not
+// enabled, or denied by an SCP) are skipped.
+func FindResourceRegion(ctx context.Context, cfg aws.Config, serviceType, name string, regions []string) (region string, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	found := make([]bool, len(regions))
+	g, groupCtx := errgroup.WithContext(ctx)
+	g.SetLimit(maxRegionLookups)
+	for i, region := range regions {
+		g.Go(func() error {
+			regionCfg := cfg.Copy()
+			regionCfg.Region = region
+			found[i], _ = ResourceExists(groupCtx, regionCfg, serviceType, name)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for i, region := range regions {
+		if found[i] {
+			return region, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return "", ErrResourceNotFound
+}
@@ -0,0 +1,147 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultBindAddress is where the Session Manager plugin listens, it can't be told to use another address
+const DefaultBindAddress = "127.0.0.1"
+
+// ValidateBindAddress checks the address is loopback or assigned to one of this host's interfaces
+func ValidateBindAddress(address string) error {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return fmt.Errorf("invalid bind address '%s': must be an IP address", address)
+	}
+	if ip.IsUnspecified() {
+		return fmt.Errorf("invalid bind address '%s': use the IP of a specific interface (e.g. the Docker bridge)", address)
+	}
+	if ip.IsLoopback() {
+		return nil
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("bind address '%s' is not assigned to any local interface", address)
+}
+
+// sessionPluginPort picks the port the Session Manager plugin listens on for localPort. With an idle
+// timeout or protocol tracing clients go through the relay on localPort, so the plugin gets a free
+// loopback port instead.
+func sessionPluginPort(localPort string, opts TunnelOptions) (string, error) {
+	if opts.IdleTimeout == 0 && !opts.TraceProtocol {
+		return localPort, nil
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(DefaultBindAddress, "0"))
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free port for the tunnel: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if err := listener.Close(); err != nil {
+		slog.Warn("failed to close port check listener", "port", port, "error", err)
+	}
+	return strconv.Itoa(port), nil
+}
+
+// startLocalRelayWhenReady starts the relay once the plugin accepts connections, so clients are
+// never accepted before the tunnel can take them
+func startLocalRelayWhenReady(ctx context.Context, opts TunnelOptions, localPort, tunnelAddress string) {
+	if !waitForTunnel(ctx, tunnelAddress) {
+		return
+	}
+	if err := startLocalRelay(ctx, opts.ListenAddress(), localPort, tunnelAddress, opts.counters, opts.TraceProtocol); err != nil {
+		report("⚠️ Warning: %v\n", err)
+		return
+	}
+	emitTunnelReady(opts, localPort)
+}
+
+// startLocalRelay listens on bindAddress:localPort and relays each connection to the plugin's
+// listener at tunnelAddress, until ctx is cancelled. With trace each connection is reported, see connTrace.
+func startLocalRelay(ctx context.Context, bindAddress, localPort, tunnelAddress string, counters *sessionCounters, trace bool) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, localPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s:%s: %w", bindAddress, localPort, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close() // Ignore error - this is cleanup
+	}()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					slog.Warn("relay stopped accepting connections", "address", listener.Addr(), "error", err)
+				}
+				return
+			}
+			var traced *connTrace
+			if trace {
+				traced = newConnTrace(localPort, conn.RemoteAddr().String())
+			}
+			go relayConnection(conn, tunnelAddress, counters, traced)
+		}
+	}()
+
+	slog.Debug("Relay started", "from", listener.Addr(), "to", tunnelAddress)
+	return nil
+}
+
+// relayConnection copies data both ways between conn and the tunnel until either side closes,
+// marking the session active whenever the client sends something
+func relayConnection(conn net.Conn, tunnelAddress string, counters *sessionCounters, trace *connTrace) {
+	defer func() {
+		_ = conn.Close() // Ignore error - this is cleanup
+	}()
+
+	upstream, err := net.DialTimeout("tcp", tunnelAddress, 5*time.Second)
+	if err != nil {
+		slog.Warn("relay could not reach the tunnel", "address", tunnelAddress, "error", err)
+		trace.failed(err)
+		return
+	}
+	defer func() {
+		_ = upstream.Close() // Ignore error - this is cleanup
+	}()
+
+	// Each copy ends when its source closes, telling which side hung up first
+	done := make(chan string, 2)
+	go func() {
+		_, _ = io.Copy(trace.wrap(activityWriter{upstream, counters}, true), conn)
+		done <- "client"
+	}()
+	go func() {
+		_, _ = io.Copy(trace.wrap(conn, false), upstream)
+		done <- "tunnel"
+	}()
+	trace.closed(<-done)
+}
+
+// activityWriter records client traffic for the idle timeout as it is written to the tunnel
+type activityWriter struct {
+	w        io.Writer
+	counters *sessionCounters
+}
+
+func (a activityWriter) Write(p []byte) (int, error) {
+	a.counters.touch()
+	return a.w.Write(p)
+}
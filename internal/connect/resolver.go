@@ -0,0 +1,130 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// EndpointResolver finds the host and port of a named resource of one service type.
+// Each implementation takes the SDK client it calls, so a fake can stand in for AWS.
+type EndpointResolver interface {
+	Resolve(ctx context.Context, name string) (host string, port int32, err error)
+}
+
+// OpenSearchDescribeDomainAPIClient is the OpenSearch call OpenSearchResolver makes.
+// The SDK only defines such interfaces for paginated operations.
+type OpenSearchDescribeDomainAPIClient interface {
+	DescribeDomain(ctx context.Context, params *opensearch.DescribeDomainInput, optFns ...func(*opensearch.Options)) (*opensearch.DescribeDomainOutput, error)
+}
+
+// NewEndpointResolver returns the resolver for a service type with SDK clients built from cfg.
+// MSK clusters have one endpoint per broker rather than one, use ResolveTargets for them.
+func NewEndpointResolver(cfg aws.Config, serviceType string, choose Chooser, endpointType string) (EndpointResolver, error) {
+	switch serviceType {
+	case "rds":
+		return RDSResolver{Client: rds.NewFromConfig(cfg)}, nil
+	case "redis":
+		return RedisResolver{Client: elasticache.NewFromConfig(cfg), Choose: choose, EndpointType: endpointType}, nil
+	case "documentdb":
+		return DocumentDBResolver{Client: docdb.NewFromConfig(cfg)}, nil
+	case "neptune":
+		return NeptuneResolver{Client: neptune.NewFromConfig(cfg), EndpointType: endpointType}, nil
+	case "opensearch":
+		return OpenSearchResolver{Client: opensearch.NewFromConfig(cfg)}, nil
+	case "keyspaces":
+		return KeyspacesResolver{Region: cfg.Region}, nil
+	case "custom":
+		return CustomResolver{}, nil
+	case "kafka":
+		return nil, fmt.Errorf("MSK clusters have one endpoint per broker, use ResolveTargets")
+	default:
+		return nil, fmt.Errorf("unsupported service type '%s'", serviceType)
+	}
+}
+
+// RDSResolver resolves RDS DB instances by identifier
+type RDSResolver struct {
+	Client rds.DescribeDBInstancesAPIClient
+}
+
+func (r RDSResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	target, err := getRDSTarget(ctx, r.Client, name)
+	return target.Endpoint, target.Port, err
+}
+
+// RedisResolver resolves ElastiCache replication groups, asking Choose which node group of a sharded one
+type RedisResolver struct {
+	Client       elasticache.DescribeReplicationGroupsAPIClient
+	Choose       Chooser
+	EndpointType string
+}
+
+func (r RedisResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	target, err := getRedisTarget(ctx, r.Client, r.Choose, name, r.EndpointType)
+	return target.Endpoint, target.Port, err
+}
+
+// DocumentDBResolver resolves DocumentDB clusters by identifier
+type DocumentDBResolver struct {
+	Client docdb.DescribeDBClustersAPIClient
+}
+
+func (r DocumentDBResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	return getDocumentDBEndpoint(ctx, r.Client, name)
+}
+
+// NeptuneResolver resolves Neptune clusters by identifier to their writer or reader endpoint
+type NeptuneResolver struct {
+	Client       neptune.DescribeDBClustersAPIClient
+	EndpointType string
+}
+
+func (r NeptuneResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	endpointType := r.EndpointType
+	if endpointType == "" {
+		endpointType = RedisEndpointPrimary
+	}
+	return getNeptuneEndpoint(ctx, r.Client, name, endpointType)
+}
+
+// OpenSearchResolver resolves OpenSearch domains by name
+type OpenSearchResolver struct {
+	Client OpenSearchDescribeDomainAPIClient
+}
+
+func (r OpenSearchResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	return getOpenSearchEndpoint(ctx, r.Client, name)
+}
+
+// KeyspacesResolver needs no AWS call: the name is the endpoint itself (e.g. a FIPS endpoint),
+// an empty name the regional default
+type KeyspacesResolver struct {
+	Region string
+}
+
+func (r KeyspacesResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	if name == "" {
+		name = KeyspacesEndpoint(r.Region)
+	}
+	report("🎯 Connecting to Amazon Keyspaces: %s\n", name)
+	return name, KeyspacesPort, nil
+}
+
+// CustomResolver needs no AWS call either: the name is the host:port to forward to
+type CustomResolver struct{}
+
+func (r CustomResolver) Resolve(ctx context.Context, name string) (string, int32, error) {
+	host, port, err := ParseCustomEndpoint(name)
+	if err != nil {
+		return "", 0, err
+	}
+	report("🎯 Connecting to %s\n", name)
+	return host, port, nil
+}
@@ -0,0 +1,178 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	elasticachetypes "github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+func TestRDSResolver(t *testing.T) {
+	instances := [][]rdstypes.DBInstance{{
+		{
+			DBInstanceIdentifier: aws.String("orders"),
+			DBInstanceStatus:     aws.String("available"),
+			Endpoint:             &rdstypes.Endpoint{Address: aws.String("orders.abc.eu-west-1.rds.amazonaws.com"), Port: aws.Int32(5432)},
+		},
+		{
+			DBInstanceIdentifier: aws.String("creating"),
+			DBInstanceStatus:     aws.String("creating"),
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		client   *fakeRDS
+		instance string
+		wantHost string
+		wantPort int32
+		wantErr  string
+	}{
+		{name: "found", client: &fakeRDS{pages: instances}, instance: "orders", wantHost: "orders.abc.eu-west-1.rds.amazonaws.com", wantPort: 5432},
+		{name: "not found", client: &fakeRDS{pages: instances}, instance: "missing", wantErr: "DB instance 'missing' not found"},
+		{name: "no endpoint", client: &fakeRDS{pages: instances}, instance: "creating", wantErr: "does not have an endpoint (status: creating)"},
+		{name: "empty name", client: &fakeRDS{pages: instances}, instance: "", wantErr: "cannot be empty"},
+		{name: "API error", client: &fakeRDS{err: errors.New("AccessDenied")}, instance: "orders", wantErr: "failed to describe DB instance 'orders': AccessDenied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, err := RDSResolver{Client: tt.client}.Resolve(context.Background(), tt.instance)
+			checkResolved(t, host, port, err, tt.wantHost, tt.wantPort, tt.wantErr)
+		})
+	}
+}
+
+func TestRedisResolver(t *testing.T) {
+	groups := [][]elasticachetypes.ReplicationGroup{{
+		{
+			ReplicationGroupId: aws.String("sessions"),
+			NodeGroups: []elasticachetypes.NodeGroup{{
+				NodeGroupId:     aws.String("0001"),
+				PrimaryEndpoint: &elasticachetypes.Endpoint{Address: aws.String("master.sessions.cache.amazonaws.com"), Port: aws.Int32(6379)},
+				ReaderEndpoint:  &elasticachetypes.Endpoint{Address: aws.String("replica.sessions.cache.amazonaws.com"), Port: aws.Int32(6379)},
+			}},
+		},
+		{
+			ReplicationGroupId: aws.String("primary-only"),
+			NodeGroups: []elasticachetypes.NodeGroup{{
+				NodeGroupId:     aws.String("0001"),
+				PrimaryEndpoint: &elasticachetypes.Endpoint{Address: aws.String("master.primary-only.cache.amazonaws.com"), Port: aws.Int32(6379)},
+			}},
+		},
+		{
+			ReplicationGroupId: aws.String("empty"),
+		},
+	}}
+
+	tests := []struct {
+		name         string
+		client       *fakeElastiCache
+		cluster      string
+		endpointType string
+		wantHost     string
+		wantPort     int32
+		wantErr      string
+	}{
+		{name: "primary", client: &fakeElastiCache{pages: groups}, cluster: "sessions", endpointType: RedisEndpointPrimary, wantHost: "master.sessions.cache.amazonaws.com", wantPort: 6379},
+		{name: "reader", client: &fakeElastiCache{pages: groups}, cluster: "sessions", endpointType: RedisEndpointReader, wantHost: "replica.sessions.cache.amazonaws.com", wantPort: 6379},
+		{name: "not found", client: &fakeElastiCache{pages: groups}, cluster: "missing", endpointType: RedisEndpointPrimary, wantErr: "redis cluster 'missing' not found"},
+		{name: "no reader endpoint", client: &fakeElastiCache{pages: groups}, cluster: "primary-only", endpointType: RedisEndpointReader, wantErr: "does not have a reader endpoint"},
+		{name: "no node groups", client: &fakeElastiCache{pages: groups}, cluster: "empty", endpointType: RedisEndpointPrimary, wantErr: "has no node groups"},
+		{name: "API error", client: &fakeElastiCache{err: errors.New("AccessDenied")}, cluster: "sessions", endpointType: RedisEndpointPrimary, wantErr: "failed to describe Redis cluster 'sessions': AccessDenied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, err := RedisResolver{Client: tt.client, EndpointType: tt.endpointType}.Resolve(context.Background(), tt.cluster)
+			checkResolved(t, host, port, err, tt.wantHost, tt.wantPort, tt.wantErr)
+		})
+	}
+}
+
+// checkResolved compares what a resolver returned with the expected endpoint, or error when wantErr is set
+func checkResolved(t *testing.T, host string, port int32, err error, wantHost string, wantPort int32, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("got error %v, want one containing %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if host != wantHost || port != wantPort {
+		t.Errorf("got %s:%d, want %s:%d", host, port, wantHost, wantPort)
+	}
+}
+
+func TestResolveTargetSpecs(t *testing.T) {
+	cfg := aws.Config{Region: "eu-west-1"}
+	tests := []struct {
+		name      string
+		specs     []config.TargetSpec
+		wantPorts []string
+		wantErr   string
+	}{
+		{
+			name: "several",
+			specs: []config.TargetSpec{
+				{ServiceType: "custom", Port: "9090", ResourceName: "prometheus.internal:9090"},
+				{ServiceType: "keyspaces", Port: "9142"},
+			},
+			wantPorts: []string{"9090", "9142"},
+		},
+		{
+			name:      "lone target without a port",
+			specs:     []config.TargetSpec{{ServiceType: "custom", ResourceName: "prometheus.internal:9090"}},
+			wantPorts: []string{""},
+		},
+		{
+			name: "several without a port",
+			specs: []config.TargetSpec{
+				{ServiceType: "custom", Port: "9090", ResourceName: "prometheus.internal:9090"},
+				{ServiceType: "custom", ResourceName: "grafana.internal:3000"},
+			},
+			wantErr: "target 2 (grafana.internal:3000)",
+		},
+		{
+			name: "shared port",
+			specs: []config.TargetSpec{
+				{ServiceType: "custom", Port: "9090", ResourceName: "prometheus.internal:9090"},
+				{ServiceType: "custom", Port: "9090", ResourceName: "grafana.internal:3000"},
+			},
+			wantErr: "port 9090 is used by another target",
+		},
+		{
+			name:    "unknown service",
+			specs:   []config.TargetSpec{{ServiceType: "mongodb", Port: "27017", ResourceName: "docs"}},
+			wantErr: "invalid service type 'mongodb'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := ResolveTargetSpecs(context.Background(), cfg, nil, tt.specs, RedisEndpointPrimary)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTargetSpecs: %v", err)
+			}
+			var ports []string
+			for _, target := range targets {
+				ports = append(ports, target.LocalPort)
+			}
+			if !slices.Equal(ports, tt.wantPorts) {
+				t.Errorf("got local ports %v, want %v", ports, tt.wantPorts)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package connect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// SecretsManagerGetSecretValueAPIClient is the Secrets Manager call FetchDBCredentials makes
+type SecretsManagerGetSecretValueAPIClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// DBCredentials are the fields of a database secret, in the JSON layout RDS and the Secrets Manager
+// console use: {"username": ..., "password": ..., "engine": ..., "dbname": ...}
+type DBCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Engine   string `json:"engine"`
+	DBName   string `json:"dbname"`
+}
+
+// FetchDBCredentials reads the database credentials stored in a Secrets Manager secret
+func FetchDBCredentials(ctx context.Context, cfg aws.Config, secretARN string) (creds DBCredentials, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+	return getDBCredentials(ctx, secretsmanager.NewFromConfig(cfg), secretARN)
+}
+
+func getDBCredentials(ctx context.Context, svc SecretsManagerGetSecretValueAPIClient, secretARN string) (DBCredentials, error) {
+	result, err := svc.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretARN),
+	})
+	if err != nil {
+		return DBCredentials{}, fmt.Errorf("failed to get secret '%s': %w", secretARN, err)
+	}
+
+	var creds DBCredentials
+	if err := json.Unmarshal([]byte(aws.ToString(result.SecretString)), &creds); err != nil || creds.Username == "" {
+		return DBCredentials{}, fmt.Errorf("secret '%s' is not a database secret with a username", secretARN)
+	}
+	return creds, nil
+}
+
+// ClientCommand returns a command that connects to the database at host:port as the secret's user,
+// leaving the client to ask for the password. It is empty for engines without a known client.
+// engine is used when the secret doesn't name one, e.g. the engine RDS reports.
+func (c DBCredentials) ClientCommand(engine, host, port string) string {
+	if c.Engine != "" {
+		engine = c.Engine
+	}
+	switch {
+	case strings.Contains(engine, "postgres"):
+		command := fmt.Sprintf("psql -h %s -p %s -U %s", host, port, c.Username)
+		if c.DBName != "" {
+			command += " -d " + c.DBName
+		}
+		return command
+	case strings.Contains(engine, "mysql"), strings.Contains(engine, "mariadb"):
+		command := fmt.Sprintf("mysql -h %s -P %s -u %s -p", host, port, c.Username)
+		if c.DBName != "" {
+			command += " " + c.DBName
+		}
+		return command
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/b3nk3/bifrost/internal/process"
+	"github.com/b3nk3/bifrost/internal/sso"
+)
+
+// Default SSM document and the parameters it expects
+const (
+	DefaultSSMDocument           = "AWS-StartPortForwardingSessionToRemoteHost"
+	DefaultSSMParameterTemplate  = "host={{host}},portNumber={{port}},localPortNumber={{local_port}}"
+	ssmParameterHostPlaceholder  = "{{host}}"
+	ssmParameterPortPlaceholder  = "{{port}}"
+	ssmParameterLocalPlaceholder = "{{local_port}}"
+)
+
+// Build the `aws ssm start-session` command for port forwarding with the role credentials in its environment
+func NewSSMCommand(cfg aws.Config, instanceID, endpoint string, port int32, localPort string, workloadRegion string, opts TunnelOptions) (*exec.Cmd, error) {
+	ssmArgs := SSMSessionArgs(instanceID, endpoint, port, localPort, workloadRegion, opts)
+
+	// Create command
+	cmd := exec.Command("aws", ssmArgs...)
+	process.NewGroup(cmd)
+	slog.Debug("prepared SSM session command", "args", ssmArgs)
+
+	// Get AWS credentials from the config
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from config: %w", err)
+	}
+
+	// Set AWS credentials from the config
+	cmd.Env = append(os.Environ(),
+		"AWS_ACCESS_KEY_ID="+creds.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY="+creds.SecretAccessKey,
+		"AWS_SESSION_TOKEN="+creds.SessionToken,
+		"AWS_REGION="+workloadRegion,
+	)
+
+	return cmd, nil
+}
+
+// TunnelOptions configures how SSM port forwarding sessions are started and supervised
+type TunnelOptions struct {
+	KeepAlive         bool
+	KeepAliveInterval time.Duration
+	KeepAliveProbe    string
+	ServiceType       string
+	Reconnect         bool
+	MaxReconnects     int
+	SSMDocument       string
+	SSMParameters     string
+	BindAddress       string
+
+	// KeepAliveMaxFailures declares the tunnel dead after this many keep alive checks fail in a row,
+	// reconnecting it if Reconnect is set. Zero only logs failures.
+	KeepAliveMaxFailures int
+
+	// TraceProtocol relays client connections through bifrost and reports when each opens and
+	// closes, the bytes it moved and its Postgres or MySQL handshake, never the payload
+	TraceProtocol bool
+
+	// Watch shows the latency of each keep alive check with the recent average and success rate,
+	// flagging latency spikes. It needs KeepAlive.
+	Watch bool
+
+	// MaxDuration closes the session once it has been open this long, IdleTimeout once no client
+	// has sent anything through it for this long. Zero means no limit.
+	MaxDuration time.Duration
+	IdleTimeout time.Duration
+
+	// Where the session's standard streams go, nil discards output and gives no input
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Prepare, if set, adjusts each SSM session command before it starts, e.g. its process group
+	Prepare func(cmd *exec.Cmd)
+
+	// OnReady, if set, is called once every target of the session accepts connections
+	OnReady func()
+
+	// SSOClient, if set with KeepAlive, is the sign-in whose cached token is watched while the
+	// session is open, warning TokenWarning before it expires, see WatchSSOToken
+	SSOClient    *sso.Client
+	TokenWarning time.Duration
+
+	// counters is shared by the copies made for each target of a session
+	counters *sessionCounters
+	// tlsRequired is set on the copy for a Redis target with in-transit encryption, so its keep
+	// alive probe speaks TLS
+	tlsRequired bool
+}
+
+func (o TunnelOptions) ListenAddress() string {
+	if o.BindAddress == "" {
+		return DefaultBindAddress
+	}
+	return o.BindAddress
+}
+
+func (o TunnelOptions) Document() string {
+	if o.SSMDocument == "" {
+		return DefaultSSMDocument
+	}
+	return o.SSMDocument
+}
+
+func (o TunnelOptions) ParameterTemplate() string {
+	if o.SSMParameters == "" {
+		return DefaultSSMParameterTemplate
+	}
+	return o.SSMParameters
+}
+
+// Construct the AWS CLI arguments that start the SSM session
+func SSMSessionArgs(instanceID, endpoint string, port int32, localPort string, workloadRegion string, opts TunnelOptions) []string {
+	return []string{
+		"ssm", "start-session",
+		"--target", instanceID,
+		"--region", workloadRegion,
+		"--document-name", opts.Document(),
+		"--parameters", RenderSSMParameters(opts.ParameterTemplate(), endpoint, port, localPort),
+	}
+}
+
+// StartSessionRequest is the SSM StartSession request behind a tunnel, in the API's JSON shape
+type StartSessionRequest struct {
+	Target       string              `json:"Target"`
+	DocumentName string              `json:"DocumentName"`
+	Parameters   map[string][]string `json:"Parameters"`
+}
+
+// NewStartSessionRequest builds the request the AWS CLI sends for the arguments of SSMSessionArgs
+func NewStartSessionRequest(instanceID, endpoint string, port int32, localPort string, opts TunnelOptions) StartSessionRequest {
+	// The rendered template is in the CLI's shorthand: name=value,name=value
+	parameters := make(map[string][]string)
+	for _, pair := range strings.Split(RenderSSMParameters(opts.ParameterTemplate(), endpoint, port, localPort), ",") {
+		name, value, _ := strings.Cut(pair, "=")
+		parameters[name] = append(parameters[name], value)
+	}
+	return StartSessionRequest{
+		Target:       instanceID,
+		DocumentName: opts.Document(),
+		Parameters:   parameters,
+	}
+}
+
+// Fill in the endpoint and ports of an SSM parameter template
+func RenderSSMParameters(template, endpoint string, port int32, localPort string) string {
+	return strings.NewReplacer(
+		ssmParameterHostPlaceholder, endpoint,
+		ssmParameterPortPlaceholder, strconv.Itoa(int(port)),
+		ssmParameterLocalPlaceholder, localPort,
+	).Replace(template)
+}
+
+// Check a custom SSM document can be driven with the parameters bifrost will send
+func ValidateSSMDocument(ctx context.Context, cfg aws.Config, opts TunnelOptions) (err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	if opts.SSMParameters != "" {
+		for _, placeholder := range []string{ssmParameterHostPlaceholder, ssmParameterPortPlaceholder, ssmParameterLocalPlaceholder} {
+			if !strings.Contains(opts.SSMParameters, placeholder) {
+				return fmt.Errorf("SSM parameter template must include %s", placeholder)
+			}
+		}
+		return nil
+	}
+
+	if opts.Document() == DefaultSSMDocument {
+		return nil
+	}
+
+	// Without a template the default parameter names are sent, so the document must accept them
+	svc := ssm.NewFromConfig(cfg)
+	result, err := svc.DescribeDocument(ctx, &ssm.DescribeDocumentInput{
+		Name: aws.String(opts.SSMDocument),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe SSM document '%s': %w", opts.SSMDocument, err)
+	}
+
+	accepted := make(map[string]bool)
+	for _, param := range result.Document.Parameters {
+		if param.Name != nil {
+			accepted[*param.Name] = true
+		}
+	}
+	for _, name := range []string{"host", "portNumber", "localPortNumber"} {
+		if !accepted[name] {
+			return fmt.Errorf("SSM document '%s' does not accept the '%s' parameter, use --ssm-parameters to map the tunnel values to its parameters", opts.SSMDocument, name)
+		}
+	}
+
+	return nil
+}
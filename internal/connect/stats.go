@@ -0,0 +1,55 @@
+package connect
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts what happened to a session's tunnels while it ran
+type Stats struct {
+	Reconnects        int
+	KeepAliveFailures int
+}
+
+// sessionCounters collects Stats from the goroutines running a session's tunnels, nil counts nothing
+type sessionCounters struct {
+	reconnects        atomic.Int64
+	keepAliveFailures atomic.Int64
+	lastActivity      atomic.Int64 // Unix nanoseconds of the last client traffic
+}
+
+func (c *sessionCounters) addReconnect() {
+	if c != nil {
+		c.reconnects.Add(1)
+	}
+}
+
+func (c *sessionCounters) addKeepAliveFailure() {
+	if c != nil {
+		c.keepAliveFailures.Add(1)
+	}
+}
+
+func (c *sessionCounters) touch() {
+	if c != nil {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+}
+
+// idleFor is how long ago a client last sent traffic, or the session started if none has
+func (c *sessionCounters) idleFor() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return time.Since(time.Unix(0, c.lastActivity.Load()))
+}
+
+func (c *sessionCounters) stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{
+		Reconnects:        int(c.reconnects.Load()),
+		KeepAliveFailures: int(c.keepAliveFailures.Load()),
+	}
+}
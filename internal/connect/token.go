@@ -0,0 +1,53 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/sso"
+)
+
+// DefaultTokenWarning is how long before the SSO token expires a kept alive session warns about it
+const DefaultTokenWarning = 10 * time.Minute
+
+// tokenCheckInterval is how often WatchSSOToken looks at the cached token
+const tokenCheckInterval = time.Minute
+
+// WatchSSOToken checks the cached SSO token until ctx is done. Once less than warning is left it
+// renews the token in the background if it has a refresh token, and otherwise warns once that the
+// next reconnect will need a new login.
+func WatchSSOToken(ctx context.Context, client *sso.Client, warning time.Duration) {
+	ticker := time.NewTicker(tokenCheckInterval)
+	defer ticker.Stop()
+
+	var warned time.Time
+	for {
+		expiresAt, err := client.TokenExpiresAt()
+		if err != nil {
+			slog.Debug("failed to check SSO token expiry", "error", err)
+		} else if remaining := time.Until(expiresAt); remaining < warning && !expiresAt.Equal(warned) {
+			renewed, err := client.Refresh(ctx)
+			switch {
+			case err == nil:
+				report("🔄 Refreshed SSO token, valid until %s\n", renewed.Format(time.Kitchen))
+			case errors.Is(err, sso.ErrLoginRequired) && remaining > 0:
+				report("⚠️ SSO token expires in %v, run 'bifrost auth login' to stay signed in for the next reconnect\n", remaining.Round(time.Minute))
+				warned = expiresAt
+			case errors.Is(err, sso.ErrLoginRequired):
+				report("⚠️ SSO token has expired, run 'bifrost auth login' to stay signed in for the next reconnect\n")
+				warned = expiresAt
+			default:
+				report("⚠️ SSO token expires in %v and could not be refreshed: %v\n", max(remaining, 0).Round(time.Minute), err)
+				warned = expiresAt
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package connect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// tracedConnections numbers the connections traced with TunnelOptions.TraceProtocol
+var tracedConnections atomic.Int64
+
+// connTrace reports what happens on one relayed connection: when it opens and closes, how many bytes
+// flow each way and, for Postgres and MySQL, which handshake it starts with. Payloads, and so
+// credentials, are never shown. A nil trace reports nothing.
+type connTrace struct {
+	id       int64
+	port     string
+	started  time.Time
+	sent     atomic.Int64 // Client to tunnel
+	received atomic.Int64 // Tunnel to client
+}
+
+func newConnTrace(localPort, client string) *connTrace {
+	t := &connTrace{id: tracedConnections.Add(1), port: localPort, started: time.Now()}
+	t.printf("opened from %s", client)
+	return t
+}
+
+func (t *connTrace) printf(format string, a ...any) {
+	report("🔬 [%s #%d] %s\n", t.port, t.id, fmt.Sprintf(format, a...))
+}
+
+// wrap counts and inspects what is written through w, toTunnel tells the direction
+func (t *connTrace) wrap(w io.Writer, toTunnel bool) io.Writer {
+	if t == nil {
+		return w
+	}
+	return &traceWriter{w: w, trace: t, toTunnel: toTunnel}
+}
+
+// failed reports the connection couldn't be relayed at all
+func (t *connTrace) failed(err error) {
+	if t != nil {
+		t.printf("could not reach the tunnel: %v", err)
+	}
+}
+
+// closed reports the connection ended, closedBy naming the side that hung up first
+func (t *connTrace) closed(closedBy string) {
+	if t == nil {
+		return
+	}
+	sent, received := t.sent.Load(), t.received.Load()
+	t.printf("closed by the %s after %s: %d bytes sent, %d bytes received", closedBy, time.Since(t.started).Round(time.Millisecond), sent, received)
+	if sent > 0 && received == 0 {
+		t.printf("nothing came back through the tunnel")
+	}
+}
+
+// traceWriter is one direction of a traced connection
+type traceWriter struct {
+	w        io.Writer
+	trace    *connTrace
+	toTunnel bool
+	seen     bool
+}
+
+func (tw *traceWriter) Write(p []byte) (int, error) {
+	if !tw.seen && len(p) > 0 {
+		tw.seen = true
+		direction := "tunnel"
+		if tw.toTunnel {
+			direction = "client"
+		}
+		if handshake := describeHandshake(p, tw.toTunnel); handshake != "" {
+			tw.trace.printf("first bytes from the %s: %s", direction, handshake)
+		} else {
+			tw.trace.printf("first bytes from the %s (%d bytes)", direction, len(p))
+		}
+	}
+	n, err := tw.w.Write(p)
+	if tw.toTunnel {
+		tw.trace.sent.Add(int64(n))
+	} else {
+		tw.trace.received.Add(int64(n))
+	}
+	return n, err
+}
+
+// Postgres startup message codes, sent as the second int32 of the client's first message
+const (
+	postgresProtocol3  = 196608 // 3.0
+	postgresCancel     = 80877102
+	postgresSSLCode    = 80877103
+	postgresGSSENCCode = 80877104
+)
+
+// MySQL packets start with a 3 byte length and a sequence number, the greeting holds the protocol version
+const (
+	mysqlHeaderLength = 4
+	mysqlProtocol10   = 10
+)
+
+// describeHandshake names the protocol message a connection starts with, from the client (toTunnel)
+// or the server. Only the message type is looked at, never its contents.
+func describeHandshake(p []byte, toTunnel bool) string {
+	if toTunnel {
+		if len(p) < 8 || int(binary.BigEndian.Uint32(p[0:4])) > len(p) {
+			return ""
+		}
+		switch binary.BigEndian.Uint32(p[4:8]) {
+		case postgresProtocol3:
+			return "Postgres StartupMessage (protocol 3.0)"
+		case postgresSSLCode:
+			return "Postgres SSLRequest"
+		case postgresGSSENCCode:
+			return "Postgres GSSENCRequest"
+		case postgresCancel:
+			return "Postgres CancelRequest"
+		}
+		return ""
+	}
+
+	// Postgres answers an SSLRequest with a single byte
+	if len(p) == 1 {
+		switch p[0] {
+		case 'S':
+			return "Postgres accepted SSL"
+		case 'N':
+			return "Postgres refused SSL"
+		}
+	}
+	// MySQL servers speak first: a packet with a 3 byte length and sequence 0, holding protocol version 10
+	if len(p) > mysqlHeaderLength && p[3] == 0 && p[mysqlHeaderLength] == mysqlProtocol10 {
+		length := int(p[0]) | int(p[1])<<8 | int(p[2])<<16
+		if length > 0 && length <= len(p)-mysqlHeaderLength {
+			return "MySQL server greeting (protocol 10)"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/events"
+	"github.com/b3nk3/bifrost/internal/process"
+)
+
+// Run SSM sessions built by newCmd, restarting with exponential backoff when one drops unexpectedly
+func runSSMPortForwardingWithReconnect(ctx context.Context, newCmd func(pluginPort string) (*exec.Cmd, error), localPort string, opts TunnelOptions) error {
+	backoff := time.Second
+	const maxBackoff = 30 * time.Second
+
+	for attempt := 1; ; attempt++ {
+		pluginPort, err := sessionPluginPort(localPort, opts)
+		if err != nil {
+			return err
+		}
+		cmd, err := newCmd(pluginPort)
+		if err != nil {
+			return err
+		}
+
+		err = runSSMPortForwarding(ctx, cmd, localPort, pluginPort, opts)
+		if ctx.Err() != nil || exitedBySignal(err) {
+			return nil
+		}
+		if !opts.Reconnect || attempt > opts.MaxReconnects {
+			return err
+		}
+
+		report("🔁 SSM session dropped (%v), reconnecting in %v (attempt %d/%d)...\n", sessionExitReason(err), backoff, attempt, opts.MaxReconnects)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		opts.counters.addReconnect()
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// Report whether the session process was terminated by a signal rather than dropping on its own
+func exitedBySignal(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled()
+}
+
+// Describe why a session exited for log output
+func sessionExitReason(err error) string {
+	if err == nil {
+		return "session exited"
+	}
+	return err.Error()
+}
+
+// Run an SSM port forwarding command until it exits or the context is cancelled.
+// The plugin listens on pluginPort, which is only different from localPort when traffic is relayed.
+func runSSMPortForwarding(ctx context.Context, cmd *exec.Cmd, localPort, pluginPort string, opts TunnelOptions) error {
+	// Keep alive is scoped to this session so it never probes a tunnel that has gone away
+	keepAliveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start the SSM session in a goroutine
+	started := time.Now()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- cmd.Run()
+	}()
+	slog.Debug("SSM session started", "local_port", localPort)
+
+	// The plugin only listens on loopback, so relay other bind addresses to it. Idle tracking
+	// relays too, so bifrost sees the client traffic.
+	pluginAddress := net.JoinHostPort(DefaultBindAddress, pluginPort)
+	if opts.ListenAddress() != DefaultBindAddress || pluginPort != localPort {
+		go startLocalRelayWhenReady(keepAliveCtx, opts, localPort, pluginAddress)
+	} else if events.Enabled() {
+		go func() {
+			if waitForTunnel(keepAliveCtx, pluginAddress) {
+				emitTunnelReady(opts, localPort)
+			}
+		}()
+	}
+
+	// Start keep alive functionality if enabled (wait for SSM tunnel to be ready).
+	// It probes the plugin directly so its checks never count as client traffic.
+	deadChan := make(chan error, 1)
+	if opts.KeepAlive {
+		probe := keepAliveProbeFor(opts.ServiceType, opts.KeepAliveProbe, opts.tlsRequired)
+		var monitor *healthMonitor
+		if opts.Watch {
+			monitor = newHealthMonitor(net.JoinHostPort(opts.ListenAddress(), localPort))
+		}
+		go func() {
+			if err := startKeepAliveWhenReady(keepAliveCtx, pluginAddress, opts.KeepAliveInterval, opts.KeepAliveMaxFailures, probe, opts.counters, monitor); err != nil {
+				deadChan <- err
+			}
+		}()
+	}
+
+	// Wait for either the command to finish, an error, or cancellation
+	select {
+	case err := <-errChan:
+		slog.Debug("SSM session exited", "local_port", localPort, "duration", time.Since(started), "error", err)
+		emitTunnelClosed(opts, localPort, sessionExitReason(err))
+		return err
+	case err := <-deadChan:
+		// The session is still running but no longer forwards anything, stop it so it can be reconnected
+		_ = stopSSMSession(cmd, errChan)
+		emitTunnelClosed(opts, localPort, err.Error())
+		return err
+	case <-ctx.Done():
+		err := stopSSMSession(cmd, errChan)
+		emitTunnelClosed(opts, localPort, "stopped")
+		return err
+	}
+}
+
+// emitTunnelReady reports that a tunnel accepts connections on its local address
+func emitTunnelReady(opts TunnelOptions, localPort string) {
+	events.Emit(events.Event{Type: events.TunnelReady, Service: opts.ServiceType, LocalAddress: net.JoinHostPort(opts.ListenAddress(), localPort)})
+}
+
+// emitTunnelClosed reports that a tunnel's SSM session exited and why
+func emitTunnelClosed(opts TunnelOptions, localPort, reason string) {
+	events.Emit(events.Event{Type: events.TunnelClosed, Service: opts.ServiceType, LocalAddress: net.JoinHostPort(opts.ListenAddress(), localPort), Reason: reason})
+}
+
+// SessionShutdownTimeout is how long an SSM session gets to exit after being asked before it is killed
+const SessionShutdownTimeout = 5 * time.Second
+
+// stopSSMSession asks the session to exit (SIGTERM, or Ctrl+Break on Windows), killing it after SessionShutdownTimeout.
+// errChan receives the result of cmd.Run, so the child is always reaped and its real exit cause returned.
+func stopSSMSession(cmd *exec.Cmd, errChan <-chan error) error {
+	if cmd.Process == nil {
+		return <-errChan
+	}
+	return process.Stop(cmd.Process, errChan, SessionShutdownTimeout)
+}
+
+// Target is a resolved endpoint to forward to a local port
+type Target struct {
+	ServiceType  string
+	ResourceName string
+	Endpoint     string
+	Port         int32
+	LocalPort    string
+	Engine       string // Database engine of RDS targets, e.g. postgres or mysql
+	Status       string // Status of RDS targets, e.g. available or stopped
+	TLSRequired  bool   // Redis targets with in-transit encryption only accept TLS clients
+	AuthRequired bool   // Redis targets with an AUTH token reject clients that don't send it
+}
+
+func ValidatePort(input string) error {
+	inputPort, err := strconv.Atoi(input)
+	if err != nil {
+		return fmt.Errorf("invalid port number: %s", input)
+	}
+	if inputPort < 1 || inputPort > 65535 {
+		return fmt.Errorf("port number must be between 1 and 65535")
+	}
+	// Check if the port is already in use
+	if isPortInUse(inputPort) {
+		return fmt.Errorf("port %d is already in use", inputPort)
+	}
+	return nil
+}
+
+func isPortInUse(port int) bool {
+	conn, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return true
+	}
+	if err := conn.Close(); err != nil {
+		// Log the error but don't affect the port check result
+		slog.Warn("failed to close port check listener", "port", port, "error", err)
+	}
+	return false
+}
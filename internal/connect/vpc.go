@@ -0,0 +1,197 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// ResourceVPC looks up the VPC a resource runs in, so bastions in the same VPC can be suggested.
+// It returns an empty ID for resources outside any VPC, such as Keyspaces or a public OpenSearch domain,
+// and for custom hosts, whose VPC can't be looked up.
+func ResourceVPC(ctx context.Context, cfg aws.Config, serviceType, resourceName string) (vpcID string, err error) {
+	defer func() { err = AWSError(ctx, err) }()
+
+	switch serviceType {
+	case "rds":
+		return rdsVPC(ctx, cfg, resourceName)
+	case "redis":
+		return redisVPC(ctx, cfg, resourceName)
+	case "documentdb":
+		return documentDBVPC(ctx, cfg, resourceName)
+	case "neptune":
+		return neptuneVPC(ctx, cfg, resourceName)
+	case "opensearch":
+		return openSearchVPC(ctx, cfg, resourceName)
+	case "kafka":
+		return mskVPC(ctx, cfg, resourceName)
+	case "keyspaces", "custom":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported service type '%s'", serviceType)
+	}
+}
+
+// Get the VPC of an RDS instance from its subnet group
+func rdsVPC(ctx context.Context, cfg aws.Config, dbInstanceName string) (string, error) {
+	result, err := rds.NewFromConfig(cfg).DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: &dbInstanceName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe DB instance '%s': %w", dbInstanceName, err)
+	}
+	if len(result.DBInstances) == 0 || result.DBInstances[0].DBSubnetGroup == nil {
+		return "", fmt.Errorf("DB instance '%s' has no subnet group", dbInstanceName)
+	}
+	return aws.ToString(result.DBInstances[0].DBSubnetGroup.VpcId), nil
+}
+
+// Get the VPC of a Redis replication group from the subnet group of one of its nodes
+func redisVPC(ctx context.Context, cfg aws.Config, clusterName string) (string, error) {
+	svc := elasticache.NewFromConfig(cfg)
+
+	groups, err := svc.DescribeReplicationGroups(ctx, &elasticache.DescribeReplicationGroupsInput{
+		ReplicationGroupId: &clusterName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe Redis cluster '%s': %w", clusterName, err)
+	}
+	if len(groups.ReplicationGroups) == 0 || len(groups.ReplicationGroups[0].MemberClusters) == 0 {
+		return "", fmt.Errorf("redis cluster '%s' has no nodes", clusterName)
+	}
+
+	// Every node of a replication group shares its subnet group
+	nodes, err := svc.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{
+		CacheClusterId: &groups.ReplicationGroups[0].MemberClusters[0],
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe Redis cluster '%s' nodes: %w", clusterName, err)
+	}
+	if len(nodes.CacheClusters) == 0 || nodes.CacheClusters[0].CacheSubnetGroupName == nil {
+		return "", fmt.Errorf("redis cluster '%s' has no subnet group", clusterName)
+	}
+
+	subnetGroups, err := svc.DescribeCacheSubnetGroups(ctx, &elasticache.DescribeCacheSubnetGroupsInput{
+		CacheSubnetGroupName: nodes.CacheClusters[0].CacheSubnetGroupName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe Redis cluster '%s' subnet group: %w", clusterName, err)
+	}
+	if len(subnetGroups.CacheSubnetGroups) == 0 {
+		return "", fmt.Errorf("redis cluster '%s' has no subnet group", clusterName)
+	}
+	return aws.ToString(subnetGroups.CacheSubnetGroups[0].VpcId), nil
+}
+
+// Get the VPC of a DocumentDB cluster from its subnet group
+func documentDBVPC(ctx context.Context, cfg aws.Config, clusterID string) (string, error) {
+	svc := docdb.NewFromConfig(cfg)
+
+	clusters, err := svc.DescribeDBClusters(ctx, &docdb.DescribeDBClustersInput{
+		DBClusterIdentifier: &clusterID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe DocumentDB cluster '%s': %w", clusterID, err)
+	}
+	if len(clusters.DBClusters) == 0 || clusters.DBClusters[0].DBSubnetGroup == nil {
+		return "", fmt.Errorf("DocumentDB cluster '%s' has no subnet group", clusterID)
+	}
+
+	subnetGroups, err := svc.DescribeDBSubnetGroups(ctx, &docdb.DescribeDBSubnetGroupsInput{
+		DBSubnetGroupName: clusters.DBClusters[0].DBSubnetGroup,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe DocumentDB cluster '%s' subnet group: %w", clusterID, err)
+	}
+	if len(subnetGroups.DBSubnetGroups) == 0 {
+		return "", fmt.Errorf("DocumentDB cluster '%s' has no subnet group", clusterID)
+	}
+	return aws.ToString(subnetGroups.DBSubnetGroups[0].VpcId), nil
+}
+
+// Get the VPC of a Neptune cluster from its subnet group
+func neptuneVPC(ctx context.Context, cfg aws.Config, clusterID string) (string, error) {
+	svc := neptune.NewFromConfig(cfg)
+
+	clusters, err := svc.DescribeDBClusters(ctx, &neptune.DescribeDBClustersInput{
+		DBClusterIdentifier: &clusterID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe Neptune cluster '%s': %w", clusterID, err)
+	}
+	if len(clusters.DBClusters) == 0 || clusters.DBClusters[0].DBSubnetGroup == nil {
+		return "", fmt.Errorf("neptune cluster '%s' has no subnet group", clusterID)
+	}
+
+	subnetGroups, err := svc.DescribeDBSubnetGroups(ctx, &neptune.DescribeDBSubnetGroupsInput{
+		DBSubnetGroupName: clusters.DBClusters[0].DBSubnetGroup,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe Neptune cluster '%s' subnet group: %w", clusterID, err)
+	}
+	if len(subnetGroups.DBSubnetGroups) == 0 {
+		return "", fmt.Errorf("neptune cluster '%s' has no subnet group", clusterID)
+	}
+	return aws.ToString(subnetGroups.DBSubnetGroups[0].VpcId), nil
+}
+
+// Get the VPC of an OpenSearch domain, empty for public domains
+func openSearchVPC(ctx context.Context, cfg aws.Config, domainName string) (string, error) {
+	result, err := opensearch.NewFromConfig(cfg).DescribeDomain(ctx, &opensearch.DescribeDomainInput{
+		DomainName: &domainName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe OpenSearch domain '%s': %w", domainName, err)
+	}
+	if result.DomainStatus == nil || result.DomainStatus.VPCOptions == nil {
+		return "", nil
+	}
+	return aws.ToString(result.DomainStatus.VPCOptions.VPCId), nil
+}
+
+// Get the VPC of an MSK cluster from the subnet of its brokers
+func mskVPC(ctx context.Context, cfg aws.Config, clusterName string) (string, error) {
+	clusterARN, err := getMSKClusterARN(ctx, cfg, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := kafka.NewFromConfig(cfg).DescribeClusterV2(ctx, &kafka.DescribeClusterV2Input{
+		ClusterArn: &clusterARN,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe MSK cluster '%s': %w", clusterName, err)
+	}
+
+	var subnets []string
+	if cluster := result.ClusterInfo; cluster != nil {
+		switch {
+		case cluster.Provisioned != nil && cluster.Provisioned.BrokerNodeGroupInfo != nil:
+			subnets = cluster.Provisioned.BrokerNodeGroupInfo.ClientSubnets
+		case cluster.Serverless != nil && len(cluster.Serverless.VpcConfigs) > 0:
+			subnets = cluster.Serverless.VpcConfigs[0].SubnetIds
+		}
+	}
+	if len(subnets) == 0 {
+		return "", fmt.Errorf("MSK cluster '%s' has no subnets", clusterName)
+	}
+
+	described, err := ec2.NewFromConfig(cfg).DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		SubnetIds: subnets[:1],
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe MSK cluster '%s' subnets: %w", clusterName, err)
+	}
+	if len(described.Subnets) == 0 {
+		return "", fmt.Errorf("MSK cluster '%s' has no subnets", clusterName)
+	}
+	return aws.ToString(described.Subnets[0].VpcId), nil
+}
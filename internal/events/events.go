@@ -0,0 +1,80 @@
+// Package events reports bifrost's lifecycle as newline-delimited JSON, one object per line, so
+// programs wrapping bifrost (e.g. a TUI or GUI) can follow what it is doing. Nothing is written
+// until Enable is called.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Type names a lifecycle step
+type Type string
+
+// Lifecycle steps, in the order a connection usually goes through them
+const (
+	AuthStarted      Type = "auth_started"      // An SSO device login was started and waits for approval in the browser
+	TokenCached      Type = "token_cached"      // An SSO token is available, from the cache, a refresh or a finished login
+	EndpointResolved Type = "endpoint_resolved" // A resource was resolved to the endpoint a tunnel will forward to
+	TunnelReady      Type = "tunnel_ready"      // A tunnel accepts connections on its local address
+	KeepAliveOK      Type = "keepalive_ok"      // A keep alive check of a tunnel succeeded
+	TunnelClosed     Type = "tunnel_closed"     // A tunnel's SSM session exited
+)
+
+// Event is one lifecycle step. Only the fields that apply to its type are set.
+type Event struct {
+	Type            Type      `json:"type"`
+	Time            time.Time `json:"time"`
+	StartURL        string    `json:"start_url,omitempty"`
+	VerificationURL string    `json:"verification_url,omitempty"`
+	UserCode        string    `json:"user_code,omitempty"`
+	Source          string    `json:"source,omitempty"` // Where a cached token came from: cache, refresh or login
+	Service         string    `json:"service,omitempty"`
+	Resource        string    `json:"resource,omitempty"`
+	Endpoint        string    `json:"endpoint,omitempty"`
+	LocalAddress    string    `json:"local_address,omitempty"`
+	Reason          string    `json:"reason,omitempty"`
+}
+
+var (
+	mu     sync.Mutex
+	writer io.Writer
+)
+
+// Enable starts writing events to w, typically stderr
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	writer = w
+}
+
+// Enabled reports whether events are being written, for steps that cost something to observe
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return writer != nil
+}
+
+// Emit writes the event as a single JSON line, stamping it with the current time if it has none
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if writer == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		slog.Warn("failed to encode event", "type", e.Type, "error", err)
+		return
+	}
+	if _, err := writer.Write(append(data, '\n')); err != nil {
+		slog.Debug("failed to write event", "type", e.Type, "error", err)
+	}
+}
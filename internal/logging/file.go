@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logFile is the --log-file diagnostics are copied to, nil without one
+var logFile *redactingWriter
+
+// secretPatterns match credentials and tokens that must never reach a log file. The first group
+// of each is kept so the log still shows which value was there.
+var secretPatterns = []*regexp.Regexp{
+	// key=value, key: value and "key":"value" with a secret-looking key
+	regexp.MustCompile(`(?i)((?:access_?token|refresh_?token|session_?token|client_?secret|secret_?access_?key|password|X-Amz-Security-Token)"?\s*[:=]\s*"?)[^\s"',&}]+`),
+	// Access key IDs on their own, e.g. in an error message
+	regexp.MustCompile(`\b((?:AKIA|ASIA))[A-Z0-9]{16}\b`),
+	// Bearer tokens in headers
+	regexp.MustCompile(`(?i)(Bearer\s+)[A-Za-z0-9._~+/=-]+`),
+}
+
+// redactingWriter scrubs credentials and tokens from everything written to the file
+type redactingWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	text := string(p)
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, "${1}[REDACTED]")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := io.WriteString(r.file, text); err != nil {
+		return 0, err
+	}
+	// Report the original length, callers such as io.MultiWriter treat anything else as a short write
+	return len(p), nil
+}
+
+// OpenLogFile starts copying diagnostics to the file at path, appending to it if it exists.
+// Credentials and tokens are redacted before they reach the file.
+func OpenLogFile(path string, args []string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	logFile = &redactingWriter{file: file}
+
+	_, err = fmt.Fprintf(logFile, "=== %s: %s\n", time.Now().Format(time.RFC3339), strings.Join(args, " "))
+	return err
+}
+
+// LogFile returns the writer diagnostics are copied to, nil without --log-file
+func LogFile() io.Writer {
+	if logFile == nil {
+		return nil
+	}
+	return logFile
+}
+
+// Tee returns a writer that writes to w and to the log file, or w itself without one
+func Tee(w io.Writer) io.Writer {
+	if logFile == nil {
+		return w
+	}
+	return io.MultiWriter(w, logFile)
+}
+
+// CloseLogFile closes the log file, if one was opened
+func CloseLogFile() error {
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.file.Close()
+	logFile = nil
+	return err
+}
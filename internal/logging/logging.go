@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultLevel keeps diagnostics quiet unless something needs attention
+const DefaultLevel = "warn"
+
+// Setup installs a leveled logger writing to stderr, and to the log file if one is open, as the default slog logger
+func Setup(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return fmt.Errorf("invalid log level '%s' (must be debug, info, warn or error)", level)
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(Tee(os.Stderr), &slog.HandlerOptions{Level: l})))
+	return nil
+}
+
+// LogAWSCalls is an AWS SDK API option that logs every operation with its request ID and duration at debug level
+func LogAWSCalls(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("BifrostLogAWSCalls",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+
+			requestID, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+			attrs := []any{
+				"service", awsmiddleware.GetServiceID(ctx),
+				"operation", awsmiddleware.GetOperationName(ctx),
+				"request_id", requestID,
+				"duration", time.Since(start),
+			}
+			// Throttled or transient failures are retried inside the call, count the attempts it took
+			if attempts, ok := retry.GetAttemptResults(metadata); ok && len(attempts.Results) > 1 {
+				attrs = append(attrs, "attempts", len(attempts.Results))
+			}
+			if err != nil {
+				slog.Debug("AWS call failed", append(attrs, "error", err)...)
+			} else {
+				slog.Debug("AWS call", attrs...)
+			}
+
+			return out, metadata, err
+		}), middleware.After)
+}
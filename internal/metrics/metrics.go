@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+// Record is one finished tunnel session, stored as a line of ~/.bifrost/metrics.jsonl
+type Record struct {
+	StartedAt         time.Time `json:"started_at"`
+	DurationSeconds   float64   `json:"duration_seconds"`
+	ServiceType       string    `json:"service"`
+	Targets           int       `json:"targets"`
+	Reconnects        int       `json:"reconnects"`
+	KeepAliveFailures int       `json:"keep_alive_failures"`
+	Failed            bool      `json:"failed"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// Duration returns how long the session ran
+func (r Record) Duration() time.Duration {
+	return time.Duration(r.DurationSeconds * float64(time.Second))
+}
+
+func getMetricsPath() (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "metrics.jsonl"), nil
+}
+
+// Append adds a session to the metrics file
+func Append(r Record) error {
+	path, err := getMetricsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads the sessions that started at or after since, oldest first.
+// Lines that can't be parsed (e.g. cut short by a crash) are skipped.
+func Load(since time.Time) ([]Record, error) {
+	path, err := getMetricsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Record{}, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close() // Read only, nothing to flush
+	}()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			slog.Warn("skipping unreadable metrics line", "file", path, "line", line, "error", err)
+			continue
+		}
+		if !r.StartedAt.Before(since) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartedAt.Before(records[j].StartedAt)
+	})
+	return records, nil
+}
+
+// Summary totals a set of sessions
+type Summary struct {
+	Sessions          int            `json:"sessions"`
+	Failed            int            `json:"failed"`
+	TotalSeconds      float64        `json:"total_seconds"`
+	Reconnects        int            `json:"reconnects"`
+	KeepAliveFailures int            `json:"keep_alive_failures"`
+	ByService         map[string]int `json:"by_service"`
+}
+
+// Summarize totals the sessions
+func Summarize(records []Record) Summary {
+	summary := Summary{ByService: make(map[string]int)}
+	for _, r := range records {
+		summary.Sessions++
+		if r.Failed {
+			summary.Failed++
+		}
+		summary.TotalSeconds += r.DurationSeconds
+		summary.Reconnects += r.Reconnects
+		summary.KeepAliveFailures += r.KeepAliveFailures
+		summary.ByService[r.ServiceType]++
+	}
+	return summary
+}
+
+// TotalDuration returns the combined length of the sessions
+func (s Summary) TotalDuration() time.Duration {
+	return time.Duration(s.TotalSeconds * float64(time.Second))
+}
+
+// FailureRate returns the share of sessions that ended with an error, between 0 and 1
+func (s Summary) FailureRate() float64 {
+	if s.Sessions == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Sessions)
+}
@@ -0,0 +1,31 @@
+// Package process stops and inspects child processes the same way on Unix and Windows, where
+// signals other than Kill can't be sent to a process
+package process
+
+import (
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Stop asks the process to exit with Terminate, killing it if it hasn't exited within timeout.
+// exited receives the result of the process's Wait, so it is always reaped and its real exit
+// cause returned.
+func Stop(p *os.Process, exited <-chan error, timeout time.Duration) error {
+	if err := Terminate(p); err != nil {
+		slog.Warn("failed to ask process to exit", "pid", p.Pid, "error", err)
+	}
+
+	select {
+	case err := <-exited:
+		slog.Debug("process stopped", "pid", p.Pid, "error", err)
+		return err
+	case <-time.After(timeout):
+	}
+
+	slog.Warn("process did not exit in time, killing it", "pid", p.Pid, "timeout", timeout)
+	if err := Kill(p); err != nil {
+		slog.Warn("failed to kill process", "pid", p.Pid, "error", err)
+	}
+	return <-exited
+}
@@ -0,0 +1,129 @@
+//go:build !windows
+
+package process
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is not a real test: it is the long-running child the tests below stop. It says
+// it's ready on stdout once its signal handling is set up, then sleeps.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("BIFROST_HELPER_PROCESS")
+	if mode == "" {
+		return
+	}
+	if mode == "ignore-term" {
+		signal.Ignore(syscall.SIGTERM)
+	}
+	os.Stdout.WriteString("ready\n")
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// startHelper starts TestHelperProcess in mode, returning it with the channel its Wait result goes to
+func startHelper(t *testing.T, mode string) (*exec.Cmd, <-chan error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "BIFROST_HELPER_PROCESS="+mode)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bufio.NewReader(stdout).ReadString('\n'); err != nil {
+		t.Fatalf("helper process didn't start: %v", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	return cmd, exited
+}
+
+// exitSignal returns the signal that ended the process, from the error of its Wait
+func exitSignal(err error) syscall.Signal {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 0
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0
+	}
+	return status.Signal()
+}
+
+func TestStopExitsWithinGracePeriod(t *testing.T) {
+	cmd, exited := startHelper(t, "sleep")
+
+	timeout := 5 * time.Second
+	started := time.Now()
+	err := Stop(cmd.Process, exited, timeout)
+	if elapsed := time.Since(started); elapsed >= timeout {
+		t.Errorf("process took %v to stop, want less than the %v grace period", elapsed, timeout)
+	}
+	if sig := exitSignal(err); sig != syscall.SIGTERM {
+		t.Errorf("process ended with %v (%v), want SIGTERM", sig, err)
+	}
+}
+
+func TestStopKillsProcessIgnoringTerminate(t *testing.T) {
+	cmd, exited := startHelper(t, "ignore-term")
+
+	timeout := 200 * time.Millisecond
+	started := time.Now()
+	err := Stop(cmd.Process, exited, timeout)
+	if elapsed := time.Since(started); elapsed < timeout {
+		t.Errorf("process was killed after %v, before the %v grace period", elapsed, timeout)
+	}
+	if sig := exitSignal(err); sig != syscall.SIGKILL {
+		t.Errorf("process ended with %v (%v), want SIGKILL", sig, err)
+	}
+}
+
+func TestParseElapsed(t *testing.T) {
+	tests := []struct {
+		etime string
+		want  time.Duration
+	}{
+		{etime: "00:07", want: 7 * time.Second},
+		{etime: "12:34", want: 12*time.Minute + 34*time.Second},
+		{etime: "01:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{etime: "2-01:02:03", want: 49*time.Hour + 2*time.Minute + 3*time.Second},
+	}
+	for _, tt := range tests {
+		got, err := parseElapsed(tt.etime)
+		if err != nil || got != tt.want {
+			t.Errorf("parseElapsed(%q) = %v, %v, want %v", tt.etime, got, err, tt.want)
+		}
+	}
+	if _, err := parseElapsed("soon"); err == nil {
+		t.Error("parseElapsed accepted a malformed time")
+	}
+}
+
+func TestStartTime(t *testing.T) {
+	cmd, exited := startHelper(t, "sleep")
+	defer func() {
+		_ = Stop(cmd.Process, exited, time.Second)
+	}()
+
+	started, err := StartTime(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("StartTime: %v", err)
+	}
+	if age := time.Since(started); age < 0 || age > time.Minute {
+		t.Errorf("helper process started %v ago, want just now", age)
+	}
+}
@@ -0,0 +1,86 @@
+//go:build !windows
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NewGroup prepares cmd so Terminate can reach it. Unix signals reach any process, so there is
+// nothing to do.
+func NewGroup(cmd *exec.Cmd) {}
+
+// Terminate asks the process to exit with SIGTERM, giving it the chance to clean up. A process
+// leading its own group, like a detached session, gets it sent to the whole group, so the
+// Session Manager plugin the AWS CLI runs as its child stops with it.
+func Terminate(p *os.Process) error {
+	return signalGroup(p, syscall.SIGTERM)
+}
+
+// Kill kills the process, along with its group when it leads one
+func Kill(p *os.Process) error {
+	return signalGroup(p, syscall.SIGKILL)
+}
+
+// signalGroup sends sig to the process group p leads, or to p alone when it's in someone else's group,
+// e.g. a foreground tunnel sharing bifrost's
+func signalGroup(p *os.Process, sig syscall.Signal) error {
+	if pgid, err := syscall.Getpgid(p.Pid); err == nil && pgid == p.Pid {
+		return syscall.Kill(-p.Pid, sig)
+	}
+	return p.Signal(sig)
+}
+
+// Alive reports whether a process with the PID is still running
+func Alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// StartTime returns when the process with the PID started, to the second, from the time ps says it
+// has been running. etime's [[dd-]hh:]mm:ss format is the same on Linux and macOS.
+func StartTime(pid int) (time.Time, error) {
+	out, err := exec.Command("ps", "-o", "etime=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the start time of PID %d: %w", pid, err)
+	}
+	elapsed, err := parseElapsed(strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the start time of PID %d: %w", pid, err)
+	}
+	return time.Now().Add(-elapsed).Truncate(time.Second), nil
+}
+
+// parseElapsed parses ps's etime, [[dd-]hh:]mm:ss
+func parseElapsed(etime string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(etime, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected elapsed time %q", etime)
+		}
+		days, etime = n, rest
+	}
+	parts := strings.Split(etime, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("unexpected elapsed time %q", etime)
+	}
+	var seconds int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected elapsed time %q", etime)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second, nil
+}
@@ -0,0 +1,81 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+var generateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+// NewGroup starts cmd in its own process group, the only way Terminate can send it a Ctrl+Break
+// without it also reaching bifrost
+func NewGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// Terminate asks the process to exit with a Ctrl+Break to its process group, which the AWS CLI and
+// the Session Manager plugin both handle. Processes that aren't in a group of their own on this
+// console, like a session started from another one, can't be asked, so they are killed instead.
+func Terminate(p *os.Process) error {
+	if ok, _, _ := generateConsoleCtrlEvent.Call(syscall.CTRL_BREAK_EVENT, uintptr(p.Pid)); ok != 0 {
+		return nil
+	}
+	return Kill(p)
+}
+
+// Kill kills the process and its children, so the Session Manager plugin the AWS CLI runs doesn't
+// outlive it. Windows has no process groups to signal, so it goes through taskkill's tree kill.
+func Kill(p *os.Process) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
+
+// Alive reports whether a process with the PID is still running
+func Alive(pid int) bool {
+	const processQueryLimitedInformation = 0x1000
+	const stillActive = 259
+
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = syscall.CloseHandle(handle)
+	}()
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// StartTime returns when the process with the PID started
+func StartTime(pid int) (time.Time, error) {
+	const processQueryLimitedInformation = 0x1000
+
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the start time of PID %d: %w", pid, err)
+	}
+	defer func() {
+		_ = syscall.CloseHandle(handle)
+	}()
+
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the start time of PID %d: %w", pid, err)
+	}
+	return time.Unix(0, creation.Nanoseconds()), nil
+}
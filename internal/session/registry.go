@@ -0,0 +1,124 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+	"github.com/b3nk3/bifrost/internal/process"
+)
+
+// Session represents a port forwarding tunnel running in the background
+type Session struct {
+	PID         int       `json:"pid"`
+	LocalPort   string    `json:"local_port"`
+	Target      string    `json:"target"`
+	Endpoint    string    `json:"endpoint"`
+	ServiceType string    `json:"service"`
+	Profile     string    `json:"profile,omitempty"`
+	LogFile     string    `json:"log_file,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// startTolerance is how far apart a process's start and the session's StartedAt, recorded once the
+// tunnel is ready, can be for the process to still be the session's
+const startTolerance = time.Minute
+
+// IsRunning reports whether the recorded process is still alive and still the session's, not an
+// unrelated one its PID has since been reused for. When the start time can't be read, the local
+// port still accepting connections has to do as proof.
+func (s Session) IsRunning() bool {
+	if !process.Alive(s.PID) {
+		return false
+	}
+	started, err := process.StartTime(s.PID)
+	if err != nil {
+		slog.Debug("can't tell whether the process is the session's from its start time", "pid", s.PID, "error", err)
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", s.LocalPort), time.Second)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}
+	return started.Sub(s.StartedAt).Abs() <= startTolerance
+}
+
+func getRegistryPath() (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions.json"), nil
+}
+
+// LoadSessions reads all recorded background sessions
+func LoadSessions() ([]Session, error) {
+	path, err := getRegistryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Session{}, nil
+		}
+		return nil, err
+	}
+
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse session registry: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// SaveSessions overwrites the registry with the given sessions
+func SaveSessions(sessions []Session) error {
+	path, err := getRegistryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// AddSession records a new background session
+func AddSession(s Session) error {
+	sessions, err := LoadSessions()
+	if err != nil {
+		return err
+	}
+
+	sessions = append(sessions, s)
+	return SaveSessions(sessions)
+}
+
+// RemoveSession drops the session with the given PID from the registry
+func RemoveSession(pid int) error {
+	sessions, err := LoadSessions()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		if s.PID != pid {
+			remaining = append(remaining, s)
+		}
+	}
+
+	return SaveSessions(remaining)
+}
@@ -6,27 +6,68 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 )
 
 type TokenCache struct {
-	AccessToken  string    `json:"accessToken"`
-	ExpiresAt    time.Time `json:"expiresAt"`
-	RefreshToken string    `json:"refreshToken"`
-	ClientId     string    `json:"clientId"`
-	ClientSecret string    `json:"clientSecret"`
-	StartUrl     string    `json:"startUrl"`
-	Region       string    `json:"region"`
+	AccessToken           string    `json:"accessToken"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	ClientId              string    `json:"clientId"`
+	ClientSecret          string    `json:"clientSecret"`
+	RegistrationExpiresAt time.Time `json:"registrationExpiresAt"`
+	StartUrl              string    `json:"startUrl"`
+	Region                string    `json:"region"`
+	ClientName            string    `json:"clientName,omitempty"`
+	Scopes                []string  `json:"scopes,omitempty"`
 }
 
-func getTokenCachePath(startURL string) (string, error) {
+// Valid reports whether the cached access token can still be used, treating it as expired skew early
+func (t *TokenCache) Valid(skew time.Duration) bool {
+	return t != nil && time.Now().Add(skew).Before(t.ExpiresAt)
+}
+
+// HasValidRegistration reports whether the cached OIDC client registration can be reused in the region
+// for a client with the given name and scopes
+func (t *TokenCache) HasValidRegistration(region, clientName string, scopes []string) bool {
+	if t == nil {
+		return false
+	}
+	// Caches written before client names were configurable were all registered as the default
+	cachedName := t.ClientName
+	if cachedName == "" {
+		cachedName = DefaultClientName
+	}
+	return t.ClientId != "" &&
+		t.ClientSecret != "" &&
+		t.Region == region &&
+		cachedName == clientName &&
+		slices.Equal(t.Scopes, scopes) &&
+		time.Now().Before(t.RegistrationExpiresAt)
+}
+
+// CacheDirEnv overrides the directory SSO tokens are cached in
+const CacheDirEnv = "BIFROST_SSO_CACHE_DIR"
+
+// tokenCacheDir returns the SSO token cache directory, by default the one the AWS CLI uses
+func tokenCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
+	return filepath.Join(homeDir, ".aws", "sso", "cache"), nil
+}
 
-	// Use the same cache directory as AWS CLI
-	cacheDir := filepath.Join(homeDir, ".aws", "sso", "cache")
+func getTokenCachePath(startURL string) (string, error) {
+	cacheDir, err := tokenCacheDir()
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(cacheDir, 0700); err != nil {
 		return "", err
 	}
@@ -72,13 +113,24 @@ func SaveTokenCache(token *TokenCache) error {
 	return os.WriteFile(path, data, 0600)
 }
 
-func ClearTokenCache() error {
-	homeDir, err := os.UserHomeDir()
+// RemoveTokenCache deletes the cached token for a single Start URL, leaving other cached tokens in place
+func RemoveTokenCache(startURL string) error {
+	path, err := getTokenCachePath(startURL)
 	if err != nil {
 		return err
 	}
 
-	cacheDir := filepath.Join(homeDir, ".aws", "sso", "cache")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func ClearTokenCache() error {
+	cacheDir, err := tokenCacheDir()
+	if err != nil {
+		return err
+	}
 	
 	// Check if cache directory exists
 	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
@@ -102,3 +154,56 @@ func ClearTokenCache() error {
 
 	return nil
 }
+
+// Refreshable reports whether an expired token can still be renewed without a new device login
+func (t *TokenCache) Refreshable() bool {
+	return t.RefreshToken != "" && t.ClientId != "" && t.ClientSecret != "" && time.Now().Before(t.RegistrationExpiresAt)
+}
+
+// PruneExpiredTokenCache deletes the cached tokens that have expired and can't be refreshed, leaving
+// the others in place. Files that aren't SSO tokens are left alone. It returns how many tokens were
+// removed, how many kept are still valid and how many kept have expired but can be refreshed.
+func PruneExpiredTokenCache() (removed, valid, refreshable int, err error) {
+	cacheDir, err := tokenCacheDir()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, 0, nil
+		}
+		return 0, 0, 0, err
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		cachePath := filepath.Join(cacheDir, entry.Name())
+		data, err := os.ReadFile(cachePath)
+		if err != nil {
+			return removed, valid, refreshable, err
+		}
+		var token TokenCache
+		if err := json.Unmarshal(data, &token); err != nil || token.ExpiresAt.IsZero() {
+			continue
+		}
+
+		switch {
+		case now.Before(token.ExpiresAt):
+			valid++
+			continue
+		case token.Refreshable():
+			refreshable++
+			continue
+		}
+		if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+			return removed, valid, refreshable, err
+		}
+		removed++
+	}
+	return removed, valid, refreshable, nil
+}
@@ -2,28 +2,254 @@ package sso
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/b3nk3/bifrost/internal/awsretry"
+	"github.com/b3nk3/bifrost/internal/events"
+	"github.com/b3nk3/bifrost/internal/logging"
+	"github.com/b3nk3/bifrost/internal/ui/output"
 	"github.com/pkg/browser"
 )
 
+// DefaultAuthTimeout is how long Authenticate waits for the device login to be approved
+const DefaultAuthTimeout = 5 * time.Minute
+
+// DefaultTokenLifetime is how long a new SSO token is assumed to last when CreateToken doesn't say
+const DefaultTokenLifetime = 8 * time.Hour
+
+// DefaultClientName is the name the OIDC client is registered under unless WithClientName sets another
+const DefaultClientName = "bifrost"
+
+// DefaultScope is the scope the OIDC client is registered with unless WithScopes sets others. IAM
+// Identity Center only hands out refresh tokens to clients registered with it.
+const DefaultScope = "sso:account:access"
+
 // Client represents an SSO client that handles authentication and token management
 type Client struct {
-	region   string
-	startURL string
+	region      string
+	startURL    string
+	authTimeout time.Duration
+	clientName  string
+	scopes      []string
+	// tokenLifetime overrides the lifetime CreateToken reports, expirySkew treats tokens as expired early
+	tokenLifetime time.Duration
+	expirySkew    time.Duration
+	authHandler   AuthHandler
+}
+
+// AuthHandler presents the progress of Authenticate, e.g. in an embedding application's own UI.
+// Unless WithAuthHandler sets another, the browser is opened and the progress printed.
+type AuthHandler interface {
+	// OnCachedToken is called when a cached token is used instead of a login, refreshed tells
+	// whether it had to be renewed first
+	OnCachedToken(refreshed bool)
+	// OnVerificationURL is called once a device login starts, with the URL to approve it at and
+	// the code the approval page shows
+	OnVerificationURL(url, code string)
+	// OnPolling is called before each check whether the login was approved, attempt counting from
+	// 1 up to maxAttempts, checking every interval
+	OnPolling(attempt, maxAttempts int, interval time.Duration)
+}
+
+// printingAuthHandler is the default AuthHandler of the bifrost CLI
+type printingAuthHandler struct {
+	authTimeout time.Duration
+}
+
+func (h printingAuthHandler) OnCachedToken(refreshed bool) {
+	if refreshed {
+		output.Println("🔄 Refreshed SSO token...")
+		return
+	}
+	output.Println("🔄 Using cached SSO token...")
+}
+
+func (h printingAuthHandler) OnVerificationURL(url, code string) {
+	// Open the URL in the default browser
+	if err := browser.OpenURL(url); err != nil {
+		output.Println("❌ Error opening browser:", err)
+	}
+
+	output.Println("\n🔐 Please complete the AWS SSO login in your browser")
+	output.Printf("🔑 Code: %s\n", code)
+	output.Printf("🌐 URL: %s\n", url)
+}
+
+func (h printingAuthHandler) OnPolling(attempt, maxAttempts int, interval time.Duration) {
+	switch {
+	case attempt == 1:
+		output.Printf("🔄 Polling every %v (timeout after %v)\n\n", interval, h.authTimeout)
+	case (attempt-1)%10 == 0:
+		output.Printf("⏳ Still waiting for authentication... (%d/%d attempts)\n", attempt-1, maxAttempts)
+	}
+}
+
+// Option configures optional Client behaviour
+type Option func(*Client)
+
+// WithAuthTimeout sets how long Authenticate polls for the device login to be approved
+func WithAuthTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.authTimeout = timeout
+		}
+	}
+}
+
+// WithClientName sets the name the OIDC client is registered under, which IAM Identity Center
+// shows in its audit logs
+func WithClientName(name string) Option {
+	return func(c *Client) {
+		if name != "" {
+			c.clientName = name
+		}
+	}
+}
+
+// WithScopes sets the scopes requested when registering the OIDC client instead of DefaultScope.
+// Without sso:account:access among them, logins get no refresh token and expire for good.
+func WithScopes(scopes ...string) Option {
+	return func(c *Client) {
+		if len(scopes) > 0 {
+			c.scopes = scopes
+		}
+	}
+}
+
+// WithTokenLifetime sets how long new SSO tokens are cached for, overriding the lifetime CreateToken
+// reports, for organisations whose session duration differs from what the token says
+func WithTokenLifetime(lifetime time.Duration) Option {
+	return func(c *Client) {
+		if lifetime > 0 {
+			c.tokenLifetime = lifetime
+		}
+	}
+}
+
+// WithExpirySkew treats cached tokens as expired this long before they do, so a token isn't
+// handed out moments before it stops working (or on a machine whose clock runs behind)
+func WithExpirySkew(skew time.Duration) Option {
+	return func(c *Client) {
+		if skew > 0 {
+			c.expirySkew = skew
+		}
+	}
+}
+
+// WithAuthHandler presents the progress of Authenticate through handler instead of printing it and
+// opening the browser
+func WithAuthHandler(handler AuthHandler) Option {
+	return func(c *Client) {
+		if handler != nil {
+			c.authHandler = handler
+		}
+	}
 }
 
 // NewClient creates a new SSO client
-func NewClient(region, startURL string) *Client {
-	return &Client{
-		region:   region,
-		startURL: startURL,
+func NewClient(region, startURL string, opts ...Option) *Client {
+	c := &Client{
+		region:      region,
+		startURL:    startURL,
+		authTimeout: DefaultAuthTimeout,
+		clientName:  DefaultClientName,
+		scopes:      []string{DefaultScope},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.authHandler == nil {
+		c.authHandler = printingAuthHandler{authTimeout: c.authTimeout}
+	}
+	return c
+}
+
+// awsConfig returns the SDK config for calls to the SSO region
+func (c *Client) awsConfig() aws.Config {
+	return aws.Config{
+		Region:     c.region,
+		Retryer:    awsretry.NewRetryer,
+		APIOptions: []func(*middleware.Stack) error{logging.LogAWSCalls},
+	}
+}
+
+// tokenExpiresAt returns when a token CreateToken issued with expiresIn seconds to live expires: after the
+// configured lifetime if there is one, otherwise after expiresIn, falling back to DefaultTokenLifetime
+func (c *Client) tokenExpiresAt(expiresIn int32) time.Time {
+	switch {
+	case c.tokenLifetime > 0:
+		return time.Now().Add(c.tokenLifetime)
+	case expiresIn > 0:
+		return time.Now().Add(time.Duration(expiresIn) * time.Second)
+	default:
+		return time.Now().Add(DefaultTokenLifetime)
+	}
+}
+
+// ErrLoginRequired is returned by CachedToken when there is no usable cached token
+var ErrLoginRequired = errors.New("SSO login required")
+
+// CachedToken returns the cached SSO token, silently refreshing it if it has expired.
+// It never starts a device login, so it is safe for non-interactive callers.
+func (c *Client) CachedToken(ctx context.Context) (*ssooidc.CreateTokenOutput, error) {
+	cachedToken, err := LoadTokenCache(c.startURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cached token: %w", err)
+	}
+	if cachedToken == nil {
+		return nil, ErrLoginRequired
+	}
+
+	if cachedToken.Valid(c.expirySkew) {
+		return &ssooidc.CreateTokenOutput{
+			AccessToken: aws.String(cachedToken.AccessToken),
+		}, nil
+	}
+
+	if cachedToken.RefreshToken != "" {
+		if token, err := c.RefreshWithToken(ctx, cachedToken); err == nil {
+			return token, nil
+		}
+	}
+
+	return nil, ErrLoginRequired
+}
+
+// TokenExpiresAt returns when the cached SSO token expires, ErrLoginRequired if there is none
+func (c *Client) TokenExpiresAt() (time.Time, error) {
+	cachedToken, err := LoadTokenCache(c.startURL)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load cached token: %w", err)
+	}
+	if cachedToken == nil {
+		return time.Time{}, ErrLoginRequired
 	}
+	return cachedToken.ExpiresAt, nil
+}
+
+// Refresh renews the cached SSO token with its refresh token and returns when the new one expires.
+// It fails with ErrLoginRequired when the token can't be renewed without a device login.
+func (c *Client) Refresh(ctx context.Context) (time.Time, error) {
+	cachedToken, err := LoadTokenCache(c.startURL)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load cached token: %w", err)
+	}
+	if cachedToken == nil || !cachedToken.Refreshable() {
+		return time.Time{}, ErrLoginRequired
+	}
+	if _, err := c.RefreshWithToken(ctx, cachedToken); err != nil {
+		return time.Time{}, err
+	}
+	return c.TokenExpiresAt()
 }
 
 // Authenticate handles the SSO authentication flow
@@ -31,30 +257,43 @@ func (c *Client) Authenticate(ctx context.Context) (*ssooidc.CreateTokenOutput,
 	// Check for cached token
 	cachedToken, err := LoadTokenCache(c.startURL)
 	if err != nil {
-		log.Printf("⚠️ Warning: Failed to load cached token: %v", err)
+		slog.Warn("failed to load cached SSO token", "error", err)
 	}
 
-	if cachedToken != nil && time.Now().Before(cachedToken.ExpiresAt) {
-		fmt.Println("🔄 Using cached SSO token...")
+	if cachedToken != nil {
+		slog.Debug("found cached SSO token", "start_url", c.startURL, "expires_at", cachedToken.ExpiresAt, "has_refresh_token", cachedToken.RefreshToken != "")
+	}
+
+	if cachedToken.Valid(c.expirySkew) {
+		c.authHandler.OnCachedToken(false)
+		events.Emit(events.Event{Type: events.TokenCached, StartURL: c.startURL, Source: "cache"})
 		return &ssooidc.CreateTokenOutput{
 			AccessToken: aws.String(cachedToken.AccessToken),
 		}, nil
 	}
 
+	// Try to silently renew an expired token before falling back to the device flow
+	if cachedToken != nil && cachedToken.RefreshToken != "" {
+		token, err := c.RefreshWithToken(ctx, cachedToken)
+		if err == nil {
+			c.authHandler.OnCachedToken(true)
+			events.Emit(events.Event{Type: events.TokenCached, StartURL: c.startURL, Source: "refresh"})
+			return token, nil
+		}
+		slog.Warn("failed to refresh SSO token, falling back to device login", "error", err)
+	}
+
 	// Step 1: Begin device authorization
-	ssoOidc := ssooidc.NewFromConfig(aws.Config{Region: c.region})
+	ssoOidc := ssooidc.NewFromConfig(c.awsConfig())
 
-	register, err := ssoOidc.RegisterClient(ctx, &ssooidc.RegisterClientInput{
-		ClientName: aws.String("bifrost"),
-		ClientType: aws.String("public"),
-	})
+	register, err := c.registration(ctx, ssoOidc, cachedToken)
 	if err != nil {
-		return nil, fmt.Errorf("RegisterClient: %w", err)
+		return nil, err
 	}
 
 	deviceAuth, err := ssoOidc.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
-		ClientId:     register.ClientId,
-		ClientSecret: register.ClientSecret,
+		ClientId:     aws.String(register.ClientId),
+		ClientSecret: aws.String(register.ClientSecret),
 		StartUrl:     aws.String(c.startURL),
 	})
 	if err != nil {
@@ -62,41 +301,38 @@ func (c *Client) Authenticate(ctx context.Context) (*ssooidc.CreateTokenOutput,
 	}
 
 	verificationURL := *deviceAuth.VerificationUriComplete
-
-	// Open the URL in the default browser
-	if err := browser.OpenURL(verificationURL); err != nil {
-		fmt.Println("❌ Error opening browser:", err)
-	}
-
-	fmt.Println("\n🔐 Please complete the AWS SSO login in your browser")
-	fmt.Printf("🔑 Code: %s\n", *deviceAuth.UserCode)
-	fmt.Printf("🌐 URL: %s\n", verificationURL)
+	events.Emit(events.Event{Type: events.AuthStarted, StartURL: c.startURL, VerificationURL: verificationURL, UserCode: aws.ToString(deviceAuth.UserCode)})
+	c.authHandler.OnVerificationURL(verificationURL, aws.ToString(deviceAuth.UserCode))
 
 	// Step 2: Poll for token
 	var token *ssooidc.CreateTokenOutput
-	maxRetries := 300 // Maximum number of retries (5 minutes with 1-second interval from AWS)
+	pollInterval := time.Duration(deviceAuth.Interval) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	maxRetries := max(int(c.authTimeout/pollInterval), 1)
 	retryCount := 0
 
-	fmt.Printf("🔄 Polling every %d seconds (timeout after %d attempts)\n\n", deviceAuth.Interval, maxRetries)
+	loginStarted := time.Now()
 
 	for {
-		// Check for context cancellation
+		// Check if we've exceeded the maximum retry count
+		if retryCount >= maxRetries {
+			return nil, fmt.Errorf("timed out after %v waiting for SSO login approval", c.authTimeout)
+		}
+
+		c.authHandler.OnPolling(retryCount+1, maxRetries, pollInterval)
+
+		// Wait for the next poll, bailing out as soon as the context is cancelled
 		select {
 		case <-ctx.Done():
 			return nil, fmt.Errorf("context cancelled while waiting for token: %w", ctx.Err())
-		default:
-			// Continue with polling
-		}
-
-		// Check if we've exceeded the maximum retry count
-		if retryCount >= maxRetries {
-			return nil, fmt.Errorf("maximum retry count exceeded while waiting for token")
+		case <-time.After(pollInterval):
 		}
 
-		time.Sleep(time.Duration(deviceAuth.Interval) * time.Second)
 		token, err = ssoOidc.CreateToken(ctx, &ssooidc.CreateTokenInput{
-			ClientId:     register.ClientId,
-			ClientSecret: register.ClientSecret,
+			ClientId:     aws.String(register.ClientId),
+			ClientSecret: aws.String(register.ClientSecret),
 			DeviceCode:   deviceAuth.DeviceCode,
 			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
 		})
@@ -105,38 +341,163 @@ func (c *Client) Authenticate(ctx context.Context) (*ssooidc.CreateTokenOutput,
 		}
 
 		retryCount++
-		if retryCount%10 == 0 {
-			fmt.Printf("⏳ Still waiting for authentication... (%d/%d attempts)\n", retryCount, maxRetries)
-		}
+		slog.Debug("SSO login not approved yet", "attempt", retryCount, "max_attempts", maxRetries, "error", err)
 	}
 
+	slog.Debug("SSO login approved", "attempts", retryCount+1, "duration", time.Since(loginStarted), "has_refresh_token", aws.ToString(token.RefreshToken) != "")
+
 	// Cache the new token
 	cacheToken := &TokenCache{
-		AccessToken:  *token.AccessToken,
-		ExpiresAt:    time.Now().Add(8 * time.Hour), // SSO tokens typically expire in 8 hours
-		ClientId:     *register.ClientId,
-		ClientSecret: *register.ClientSecret,
-		StartUrl:     c.startURL,
-		Region:       c.region,
+		AccessToken:           *token.AccessToken,
+		ExpiresAt:             c.tokenExpiresAt(token.ExpiresIn),
+		RefreshToken:          aws.ToString(token.RefreshToken),
+		ClientId:              register.ClientId,
+		ClientSecret:          register.ClientSecret,
+		RegistrationExpiresAt: register.ExpiresAt,
+		StartUrl:              c.startURL,
+		Region:                c.region,
+		ClientName:            c.clientName,
+		Scopes:                c.scopes,
+	}
+	if err := SaveTokenCache(cacheToken); err != nil {
+		slog.Warn("failed to cache SSO token", "error", err)
+	} else {
+		events.Emit(events.Event{Type: events.TokenCached, StartURL: c.startURL, Source: "login"})
+	}
+
+	return token, nil
+}
+
+// clientRegistration is an OIDC client registered for device logins
+type clientRegistration struct {
+	ClientId     string
+	ClientSecret string
+	ExpiresAt    time.Time
+}
+
+// registration reuses the unexpired OIDC client from the token cache, registering a new one otherwise.
+// A cached client registered under another name or scopes is replaced.
+func (c *Client) registration(ctx context.Context, ssoOidc *ssooidc.Client, cached *TokenCache) (*clientRegistration, error) {
+	if cached.HasValidRegistration(c.region, c.clientName, c.scopes) {
+		slog.Debug("reusing cached OIDC client registration", "expires_at", cached.RegistrationExpiresAt)
+		return &clientRegistration{
+			ClientId:     cached.ClientId,
+			ClientSecret: cached.ClientSecret,
+			ExpiresAt:    cached.RegistrationExpiresAt,
+		}, nil
+	}
+
+	register, err := ssoOidc.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String(c.clientName),
+		ClientType: aws.String("public"),
+		Scopes:     c.scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RegisterClient: %w", err)
+	}
+	slog.Debug("registered OIDC client", "name", c.clientName, "scopes", c.scopes, "expires_at", time.Unix(register.ClientSecretExpiresAt, 0))
+
+	return &clientRegistration{
+		ClientId:     aws.ToString(register.ClientId),
+		ClientSecret: aws.ToString(register.ClientSecret),
+		ExpiresAt:    time.Unix(register.ClientSecretExpiresAt, 0),
+	}, nil
+}
+
+// RefreshWithToken exchanges the refresh token held in the cache for a new access token
+func (c *Client) RefreshWithToken(ctx context.Context, cache *TokenCache) (*ssooidc.CreateTokenOutput, error) {
+	if cache == nil || cache.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token available")
+	}
+
+	ssoOidc := ssooidc.NewFromConfig(c.awsConfig())
+	token, err := ssoOidc.CreateToken(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(cache.ClientId),
+		ClientSecret: aws.String(cache.ClientSecret),
+		RefreshToken: aws.String(cache.RefreshToken),
+		GrantType:    aws.String("refresh_token"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CreateToken: %w", err)
+	}
+
+	// Some refresh responses don't rotate the refresh token, so keep the old one
+	refreshToken := cache.RefreshToken
+	if token.RefreshToken != nil && *token.RefreshToken != "" {
+		refreshToken = *token.RefreshToken
+	}
+
+	cacheToken := &TokenCache{
+		AccessToken:           *token.AccessToken,
+		ExpiresAt:             c.tokenExpiresAt(token.ExpiresIn),
+		RefreshToken:          refreshToken,
+		ClientId:              cache.ClientId,
+		ClientSecret:          cache.ClientSecret,
+		RegistrationExpiresAt: cache.RegistrationExpiresAt,
+		StartUrl:              c.startURL,
+		Region:                c.region,
+		ClientName:            cache.ClientName,
+		Scopes:                cache.Scopes,
 	}
 	if err := SaveTokenCache(cacheToken); err != nil {
-		log.Printf("⚠️ Warning: Failed to cache token: %v", err)
+		slog.Warn("failed to cache SSO token", "error", err)
 	}
 
 	return token, nil
 }
 
-// ListAccounts returns a list of available AWS accounts
-func (c *Client) ListAccounts(ctx context.Context, token *ssooidc.CreateTokenOutput) (*sso.ListAccountsOutput, error) {
-	ssoClient := sso.NewFromConfig(aws.Config{Region: c.region})
-	return ssoClient.ListAccounts(ctx, &sso.ListAccountsInput{
+// ListAccounts returns every AWS account the token can access, going through all pages
+func (c *Client) ListAccounts(ctx context.Context, token *ssooidc.CreateTokenOutput) ([]types.AccountInfo, error) {
+	var accounts []types.AccountInfo
+	paginator := sso.NewListAccountsPaginator(sso.NewFromConfig(c.awsConfig()), &sso.ListAccountsInput{
 		AccessToken: token.AccessToken,
 	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, page.AccountList...)
+	}
+	return accounts, nil
+}
+
+// maxListedAccounts is how many accounts AccountIDByName names when none matches; past that the
+// list would bury the error, so it only gives the count
+const maxListedAccounts = 10
+
+// AccountIDByName returns the ID of the account whose name matches, ignoring case. It fails when no
+// account or more than one has the name, listing the candidates.
+func AccountIDByName(accounts []types.AccountInfo, name string) (string, error) {
+	var matchIDs, matches []string
+	for _, acc := range accounts {
+		display := fmt.Sprintf("%s (%s)", aws.ToString(acc.AccountName), aws.ToString(acc.AccountId))
+		if strings.EqualFold(aws.ToString(acc.AccountName), name) {
+			matchIDs = append(matchIDs, aws.ToString(acc.AccountId))
+			matches = append(matches, display)
+		}
+	}
+
+	switch len(matchIDs) {
+	case 0:
+		if len(accounts) > maxListedAccounts {
+			return "", fmt.Errorf("no account named '%s' among the %d available accounts", name, len(accounts))
+		}
+		all := make([]string, 0, len(accounts))
+		for _, acc := range accounts {
+			all = append(all, fmt.Sprintf("%s (%s)", aws.ToString(acc.AccountName), aws.ToString(acc.AccountId)))
+		}
+		return "", fmt.Errorf("no account named '%s', available accounts: %s", name, strings.Join(all, ", "))
+	case 1:
+		return matchIDs[0], nil
+	default:
+		return "", fmt.Errorf("account name '%s' is ambiguous, it matches %s; use the account ID instead", name, strings.Join(matches, ", "))
+	}
 }
 
 // ListAccountRoles returns a list of available roles for an account
 func (c *Client) ListAccountRoles(ctx context.Context, token *ssooidc.CreateTokenOutput, accountId string) (*sso.ListAccountRolesOutput, error) {
-	ssoClient := sso.NewFromConfig(aws.Config{Region: c.region})
+	ssoClient := sso.NewFromConfig(c.awsConfig())
 	return ssoClient.ListAccountRoles(ctx, &sso.ListAccountRolesInput{
 		AccountId:   aws.String(accountId),
 		AccessToken: token.AccessToken,
@@ -145,7 +506,7 @@ func (c *Client) ListAccountRoles(ctx context.Context, token *ssooidc.CreateToke
 
 // GetRoleCredentials returns credentials for a specific role
 func (c *Client) GetRoleCredentials(ctx context.Context, token *ssooidc.CreateTokenOutput, accountId, roleName string) (*sso.GetRoleCredentialsOutput, error) {
-	ssoClient := sso.NewFromConfig(aws.Config{Region: c.region})
+	ssoClient := sso.NewFromConfig(c.awsConfig())
 	return ssoClient.GetRoleCredentials(ctx, &sso.GetRoleCredentialsInput{
 		AccessToken: token.AccessToken,
 		AccountId:   aws.String(accountId),
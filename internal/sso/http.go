@@ -0,0 +1,45 @@
+package sso
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// CABundleEnv names a PEM file of extra certificate authorities to trust, the same variable the AWS CLI reads
+const CABundleEnv = "AWS_CA_BUNDLE"
+
+// CABundle returns the CA bundle to trust: AWS_CA_BUNDLE if set, otherwise the configured path (may be empty)
+func CABundle(configured string) string {
+	if env := os.Getenv(CABundleEnv); env != "" {
+		return env
+	}
+	return configured
+}
+
+// NewHTTPClient returns a client that goes through HTTP(S)_PROXY and trusts the CAs in caBundle
+// on top of the system ones, for networks that intercept TLS
+func NewHTTPClient(caBundle string, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
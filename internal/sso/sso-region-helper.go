@@ -4,38 +4,90 @@ Copyright © 2025 Ben Szabo me@benszabo.co.uk
 package sso
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/b3nk3/bifrost/internal/logging"
+)
+
+// Each region lookup attempt gives up after regionLookupTimeout, failed attempts are retried
+// up to regionLookupAttempts times in total
+const (
+	regionLookupTimeout  = 5 * time.Second
+	regionLookupAttempts = 3
 )
 
 // extractRegionFromSSO makes an HTTP request to the AWS SSO start URL
-// and extracts the region from the Content-Security-Policy header
-func ExtractRegionFromSSO(startURL string) (string, error) {
+// and extracts the region from the Content-Security-Policy header.
+// If the header doesn't name it, candidate regions are probed with SSO OIDC instead.
+// The request goes through HTTP(S)_PROXY and trusts the CAs in caBundle, see NewHTTPClient.
+func ExtractRegionFromSSO(ctx context.Context, startURL, caBundle string) (string, error) {
 	// Create HTTP client
-	client := &http.Client{
-		// Don't follow redirects automatically
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+	client, err := NewHTTPClient(caBundle, regionLookupTimeout)
+	if err != nil {
+		return "", err
+	}
+	// Don't follow redirects automatically
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
 
-	// Make HEAD request to get headers without body
-	resp, err := client.Head(startURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+	// Network errors and server errors are often transient on proxied networks, so retry those
+	var resp *http.Response
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		resp, err = headStartURL(ctx, client, startURL)
+		if err == nil && resp.StatusCode < 500 {
+			break
+		}
+		if err == nil {
+			_ = resp.Body.Close() // Retried, the body is never read
+			err = fmt.Errorf("start URL returned %s", resp.Status)
+		}
+		if attempt == regionLookupAttempts || ctx.Err() != nil {
+			return "", err
+		}
+
+		slog.Debug("SSO region lookup failed, retrying", "attempt", attempt, "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
 	defer func() {
 		_ = resp.Body.Close() // Ignore error - this is cleanup
 	}()
 
-	// Get the Content-Security-Policy header
-	csp := resp.Header.Get("Content-Security-Policy")
+	region, cspErr := regionFromCSP(resp.Header.Get("Content-Security-Policy"))
+	if cspErr == nil {
+		return region, nil
+	}
+
+	// AWS may change the portal's headers, so fall back to asking SSO OIDC which region knows the start URL
+	slog.Debug("SSO region not found in CSP header, probing candidate regions", "error", cspErr)
+	region, err = probeSSORegions(ctx, client, startURL)
+	if err != nil {
+		return "", fmt.Errorf("%v, and probing regions failed: %w", cspErr, err)
+	}
+	return region, nil
+}
+
+// Extract region from the report-uri in the Content-Security-Policy header
+func regionFromCSP(csp string) (string, error) {
 	if csp == "" {
 		return "", fmt.Errorf("no Content-Security-Policy header found")
 	}
 
-	// Extract region from the report-uri in CSP
 	// Looking for pattern: https://log.sso-portal.REGION.amazonaws.com/log
 	re := regexp.MustCompile(`https://log\.sso-portal\.([a-z0-9-]+)\.amazonaws\.com/log`)
 	matches := re.FindStringSubmatch(csp)
@@ -46,3 +98,93 @@ func ExtractRegionFromSSO(startURL string) (string, error) {
 
 	return matches[1], nil
 }
+
+// candidateSSORegions are probed when the start URL's headers don't name its region,
+// roughly in order of how commonly IAM Identity Center is set up in them
+var candidateSSORegions = []string{
+	"us-east-1",
+	"eu-west-1",
+	"us-west-2",
+	"eu-central-1",
+	"us-east-2",
+	"eu-west-2",
+	"ap-southeast-2",
+	"ap-southeast-1",
+	"ap-northeast-1",
+	"ca-central-1",
+	"eu-north-1",
+	"ap-south-1",
+	"sa-east-1",
+}
+
+// probeSSORegions starts a device authorization for startURL in every candidate region at once.
+// Only the region hosting the start URL accepts it, so the first in candidate order to succeed wins.
+// Nothing is approved, the authorization simply expires.
+func probeSSORegions(ctx context.Context, client *http.Client, startURL string) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	accepted := make([]chan bool, len(candidateSSORegions))
+	for i, region := range candidateSSORegions {
+		accepted[i] = make(chan bool, 1)
+		go func() {
+			err := startDeviceAuthorization(ctx, client, region, startURL)
+			if err != nil {
+				slog.Debug("SSO region probe rejected", "region", region, "error", err)
+			}
+			accepted[i] <- err == nil
+		}()
+	}
+
+	for i, region := range candidateSSORegions {
+		select {
+		case ok := <-accepted[i]:
+			if ok {
+				return region, nil
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return "", fmt.Errorf("no candidate region (%s) accepted the start URL", strings.Join(candidateSSORegions, ", "))
+}
+
+// Register a throwaway OIDC client in region and start a device authorization for startURL with it
+func startDeviceAuthorization(ctx context.Context, client *http.Client, region, startURL string) error {
+	ssoOidc := ssooidc.NewFromConfig(aws.Config{
+		Region:     region,
+		HTTPClient: client,
+		APIOptions: []func(*middleware.Stack) error{logging.LogAWSCalls},
+	})
+
+	register, err := ssoOidc.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("bifrost"),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return fmt.Errorf("RegisterClient: %w", err)
+	}
+
+	_, err = ssoOidc.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     register.ClientId,
+		ClientSecret: register.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	if err != nil {
+		return fmt.Errorf("StartDeviceAuthorization: %w", err)
+	}
+	return nil
+}
+
+// Make a HEAD request to get headers without body
+func headStartURL(ctx context.Context, client *http.Client, startURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, startURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	return resp, nil
+}
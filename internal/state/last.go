@@ -0,0 +1,110 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+// LastConnection is the fully resolved connection of the last successful connect, replayed by connect --last
+type LastConnection struct {
+	Profile           string    `json:"profile,omitempty"`
+	AWSProfile        string    `json:"aws_profile,omitempty"`
+	SSOProfile        string    `json:"sso_profile,omitempty"`
+	AccountID         string    `json:"account_id,omitempty"`
+	RoleName          string    `json:"role_name,omitempty"`
+	Region            string    `json:"region,omitempty"`
+	AssumeRoleARN     string    `json:"assume_role_arn,omitempty"`
+	ExternalID        string    `json:"external_id,omitempty"`
+	ServiceType       string    `json:"service,omitempty"`
+	ResourceName      string    `json:"resource_name,omitempty"`
+	Port              string    `json:"port,omitempty"`
+	RemotePort        int       `json:"remote_port,omitempty"`
+	EndpointType      string    `json:"endpoint_type,omitempty"`
+	BastionInstanceID string    `json:"bastion_instance_id,omitempty"`
+	SSMDocument       string    `json:"ssm_document,omitempty"`
+	SSMParameters     string    `json:"ssm_parameters,omitempty"`
+	ConnectedAt       time.Time `json:"connected_at"`
+}
+
+// Missing lists the values a replay would still have to prompt for, empty if the connection is complete.
+// A connection profile supplies the rest itself, so only the profile name is needed then.
+func (c LastConnection) Missing() []string {
+	if c.Profile != "" {
+		return nil
+	}
+
+	var missing []string
+	if c.AWSProfile == "" {
+		for _, field := range []struct{ name, value string }{
+			{"SSO profile", c.SSOProfile},
+			{"account ID", c.AccountID},
+			{"role name", c.RoleName},
+		} {
+			if field.value == "" {
+				missing = append(missing, field.name)
+			}
+		}
+	}
+	for _, field := range []struct{ name, value string }{
+		{"region", c.Region},
+		{"service type", c.ServiceType},
+		{"resource name", c.ResourceName},
+		{"port", c.Port},
+		{"bastion instance ID", c.BastionInstanceID},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
+}
+
+func getLastConnectionPath() (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last.json"), nil
+}
+
+// LoadLastConnection reads the last successful connection, nil if none has been recorded yet
+func LoadLastConnection() (*LastConnection, error) {
+	path, err := getLastConnectionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var last LastConnection
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("failed to parse last connection: %w", err)
+	}
+	return &last, nil
+}
+
+// SaveLastConnection records c as the connection for connect --last
+func SaveLastConnection(c LastConnection) error {
+	path, err := getLastConnectionPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
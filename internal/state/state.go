@@ -0,0 +1,137 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/b3nk3/bifrost/internal/config"
+)
+
+// State holds values bifrost remembers between runs
+type State struct {
+	AccountRoles map[string]AccountRole `json:"account_roles,omitempty"`
+	Bastions     map[string]string      `json:"bastions,omitempty"`
+}
+
+// AccountRole is an account and role pair resolved during a previous connect
+type AccountRole struct {
+	AccountID string `json:"account_id"`
+	RoleName  string `json:"role_name"`
+}
+
+func getStatePath() (string, error) {
+	dir, err := config.GetBifrostDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// Load reads the remembered state, returning an empty state if none exists yet
+func Load() (*State, error) {
+	s := &State{AccountRoles: make(map[string]AccountRole), Bastions: make(map[string]string)}
+
+	path, err := getStatePath()
+	if err != nil {
+		return s, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if s.AccountRoles == nil {
+		s.AccountRoles = make(map[string]AccountRole)
+	}
+	if s.Bastions == nil {
+		s.Bastions = make(map[string]string)
+	}
+
+	return s, nil
+}
+
+// Save writes the state to disk
+func Save(s *State) error {
+	path, err := getStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// RememberAccountRole records the account and role last used with an SSO profile
+func RememberAccountRole(ssoProfile, accountID, roleName string) error {
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+
+	s.AccountRoles[ssoProfile] = AccountRole{AccountID: accountID, RoleName: roleName}
+	return Save(s)
+}
+
+// bastionKey identifies where a bastion was picked: an account's region, or one VPC in it
+func bastionKey(accountID, region, vpc string) string {
+	return strings.Join([]string{accountID, region, vpc}, "/")
+}
+
+// LastBastion returns the bastion last picked in the VPC, or in the region if none was picked in the VPC yet
+func LastBastion(accountID, region, vpc string) (string, error) {
+	s, err := Load()
+	if err != nil {
+		return "", err
+	}
+
+	if vpc != "" {
+		if id := s.Bastions[bastionKey(accountID, region, vpc)]; id != "" {
+			return id, nil
+		}
+	}
+	return s.Bastions[bastionKey(accountID, region, "")], nil
+}
+
+// RememberBastion records the bastion picked in a region and, if known, the VPC it is in
+func RememberBastion(accountID, region, vpc, instanceID string) error {
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+
+	s.Bastions[bastionKey(accountID, region, "")] = instanceID
+	if vpc != "" {
+		s.Bastions[bastionKey(accountID, region, vpc)] = instanceID
+	}
+	return Save(s)
+}
+
+// ForgetBastion drops a remembered bastion from every VPC of the region, e.g. once it is no longer SSM-managed
+func ForgetBastion(accountID, region, instanceID string) error {
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+
+	prefix := bastionKey(accountID, region, "")
+	for key, id := range s.Bastions {
+		if id == instanceID && strings.HasPrefix(key, prefix) {
+			delete(s.Bastions, key)
+		}
+	}
+	return Save(s)
+}
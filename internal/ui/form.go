@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/charmbracelet/huh"
+)
+
+// FormPrompter asks with interactive huh forms, the default in a terminal
+type FormPrompter struct{}
+
+func (FormPrompter) Select(label string, items []string, filtering bool, defaultValue string) (string, error) {
+	selected := defaultValue
+	field := huh.NewSelect[string]().
+		Title(label).
+		Options(huh.NewOptions(items...)...).
+		Value(&selected)
+
+	// Start in filter mode so typing narrows the options straight away
+	if filtering {
+		field = field.Filtering(true).Height(15)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(field),
+	)
+
+	if err := form.WithOutput(output.Status()).Run(); err != nil {
+		return "", fmt.Errorf("select failed: %w", err)
+	}
+	return selected, nil
+}
+
+func (FormPrompter) MultiSelect(label string, items []string, defaults []string) ([]string, error) {
+	selected := defaults
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(label).
+				Options(huh.NewOptions(items...)...).
+				Value(&selected),
+		),
+	)
+
+	if err := form.WithOutput(output.Status()).Run(); err != nil {
+		return nil, fmt.Errorf("select failed: %w", err)
+	}
+	return selected, nil
+}
+
+func (FormPrompter) Input(label string, validate func(string) error, defaultValue string) (string, error) {
+	result := defaultValue
+	input := huh.NewInput().
+		Title(label).
+		Validate(validate).
+		Value(&result)
+
+	form := huh.NewForm(
+		huh.NewGroup(input),
+	)
+
+	if err := form.WithOutput(output.Status()).Run(); err != nil {
+		return "", fmt.Errorf("input failed: %w", err)
+	}
+	return result, nil
+}
+
+func (FormPrompter) Confirm(label string) (bool, error) {
+	var confirm bool
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(label).
+				Affirmative("Yes!").
+				Negative("No.").
+				Value(&confirm),
+		),
+	)
+
+	if err := form.WithOutput(output.Status()).Run(); err != nil {
+		return false, fmt.Errorf("confirmation failed: %w", err)
+	}
+	return confirm, nil
+}
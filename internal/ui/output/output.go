@@ -0,0 +1,208 @@
+// Package output prints bifrost's status lines. A line starting with an icon (e.g. "✅ Connected")
+// gets the icon styled by what it means, or replaced with an ASCII prefix when emoji are off.
+// Styling is dropped when NO_COLOR is set or the output isn't a terminal.
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+)
+
+// Styles of the kinds of status line
+var (
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	hintStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	infoStyle    = lipgloss.NewStyle().Bold(true)
+)
+
+// icon is how a status icon is shown with emoji off and which style it takes
+type icon struct {
+	ascii string
+	style lipgloss.Style
+}
+
+// icons lists the icons with their own meaning, any other leading emoji is shown as defaultIcon
+var icons = map[string]icon{
+	"✅":  {"[ok]", successStyle},
+	"❌":  {"[error]", errorStyle},
+	"⚠️": {"[warn]", warningStyle},
+	"🛑":  {"[stop]", errorStyle},
+	"💡":  {"[hint]", hintStyle},
+	"ℹ️": {"[info]", hintStyle},
+	"🔁":  {"[retry]", warningStyle},
+	"•":  {"-", lipgloss.NewStyle()},
+}
+
+var defaultIcon = icon{"*", infoStyle}
+
+// Prefixes of status lines without an icon that are styled like one
+var wordStyles = []struct {
+	word  string
+	style lipgloss.Style
+}{
+	{"Error", errorStyle},
+	{"Warning", warningStyle},
+}
+
+var (
+	emoji = !nonUTF8Locale()
+	color = os.Getenv("NO_COLOR") == ""
+	// logCopy gets an unstyled copy of every status line, nil unless a log file is open
+	logCopy io.Writer
+	// status is where Printf and Println write, stdout unless StatusTo moved it
+	status io.Writer = os.Stdout
+)
+
+// Configure turns emoji off when noEmoji is set. It is called once the command line is parsed.
+func Configure(noEmoji bool) {
+	if noEmoji {
+		emoji = false
+	}
+}
+
+// CopyTo also writes every status line, unstyled, to w (e.g. a --log-file)
+func CopyTo(w io.Writer) {
+	logCopy = w
+}
+
+// StatusTo sends the status lines of Printf and Println, and the prompts, to w instead of stdout,
+// e.g. stderr when stdout carries a command's JSON result
+func StatusTo(w io.Writer) {
+	status = w
+}
+
+// Status returns where status lines go, stdout unless StatusTo moved them
+func Status() io.Writer {
+	return status
+}
+
+// Emoji reports whether emoji are shown, false with --no-emoji or a locale without UTF-8
+func Emoji() bool {
+	return emoji
+}
+
+// Printf formats a status line to stdout, or where StatusTo moved status lines
+func Printf(format string, a ...any) {
+	Fprintf(status, format, a...)
+}
+
+// Println prints a status line like Printf, spacing the values like fmt.Println
+func Println(a ...any) {
+	plain := a
+	if len(a) > 0 {
+		if first, ok := a[0].(string); ok {
+			plain = append([]any{decorate(first, false)}, a[1:]...)
+			a[0] = decorate(first, styled(status))
+		}
+	}
+	if logCopy != nil {
+		fmt.Fprintln(logCopy, plain...)
+	}
+	fmt.Fprintln(status, a...)
+}
+
+// Fprintf formats a status line to w
+func Fprintf(w io.Writer, format string, a ...any) {
+	if logCopy != nil {
+		fmt.Fprintf(logCopy, decorate(format, false), a...)
+	}
+	fmt.Fprintf(w, decorate(format, styled(w)), a...)
+}
+
+// Icon returns a status icon (e.g. "✅") as it should be shown with the status lines
+func Icon(emojiIcon string) string {
+	return renderIcon(emojiIcon, styled(status))
+}
+
+// decorate swaps and styles the icon or word a status line starts with, keeping leading newlines and indentation
+func decorate(line string, style bool) string {
+	body := strings.TrimLeft(line, "\n ")
+	lead := line[:len(line)-len(body)]
+
+	for _, word := range wordStyles {
+		if strings.HasPrefix(body, word.word) {
+			if !style {
+				return line
+			}
+			return lead + word.style.Render(word.word) + body[len(word.word):]
+		}
+	}
+
+	prefix, rest := splitIcon(body)
+	if prefix == "" {
+		return line
+	}
+	return lead + renderIcon(prefix, style) + rest
+}
+
+// splitIcon separates a leading icon from the rest of the line
+func splitIcon(line string) (string, string) {
+	for prefix := range icons {
+		if strings.HasPrefix(line, prefix) {
+			return prefix, line[len(prefix):]
+		}
+	}
+
+	// Any other non-ASCII symbol up to the first space counts as an icon (emoji may carry a variation selector)
+	end := strings.IndexByte(line, ' ')
+	if end <= 0 {
+		return "", line
+	}
+	for _, r := range line[:end] {
+		if r < 0x80 {
+			return "", line
+		}
+	}
+	return line[:end], line[end:]
+}
+
+// renderIcon shows an icon as its emoji or ASCII prefix, in its style when styling is on
+func renderIcon(emojiIcon string, style bool) string {
+	known, ok := icons[emojiIcon]
+	if !ok {
+		known = defaultIcon
+	}
+
+	text := emojiIcon
+	if !emoji {
+		text = known.ascii
+	}
+	if !style {
+		return text
+	}
+	return known.style.Render(text)
+}
+
+// styled reports whether colors should be written to w: only to terminals, and never with NO_COLOR
+func styled(w io.Writer) bool {
+	if !color {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// nonUTF8Locale reports whether the locale says the terminal can't show UTF-8, so emoji would be garbled
+func nonUTF8Locale() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return !strings.Contains(value, "utf-8") && !strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PromptEnv selects the prompt backend; set it to PromptPlain to answer prompts line by line,
+// e.g. by piping answers into bifrost
+const PromptEnv = "BIFROST_PROMPT"
+
+// PromptPlain is the PromptEnv value that selects the LinePrompter
+const PromptPlain = "plain"
+
+// LinePrompter asks by writing numbered options to out and reading one answer per line from in.
+// An empty answer accepts the default, and running out of input fails like a missing value.
+type LinePrompter struct {
+	in        *bufio.Reader
+	out       io.Writer
+	exhausted bool
+}
+
+// NewLinePrompter creates a prompter reading answers from in and writing questions to out
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine returns the next answer without surrounding whitespace, or io.EOF once input ran out
+func (l *LinePrompter) readLine() (string, error) {
+	line, err := l.in.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		l.exhausted = true
+	}
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// ask writes the question and reads the answer, using defaultValue for an empty answer
+func (l *LinePrompter) ask(label, question, defaultValue string) (string, error) {
+	fmt.Fprint(l.out, question)
+	answer, err := l.readLine()
+	if errors.Is(err, io.EOF) {
+		if defaultValue != "" {
+			return defaultValue, nil
+		}
+		return "", missingValue(label)
+	}
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// choose maps an answer to an item, by its number in the list or by its exact text
+func choose(items []string, answer string) (string, bool) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(items) {
+		return items[n-1], true
+	}
+	for _, item := range items {
+		if item == answer {
+			return item, true
+		}
+	}
+	return "", false
+}
+
+func (l *LinePrompter) Select(label string, items []string, filtering bool, defaultValue string) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("select failed: no options for %s", label)
+	}
+
+	fmt.Fprintln(l.out, label)
+	for i, item := range items {
+		fmt.Fprintf(l.out, "  %d) %s\n", i+1, item)
+	}
+
+	question := "Enter a number: "
+	if defaultValue != "" {
+		question = fmt.Sprintf("Enter a number [%s]: ", defaultValue)
+	}
+	for {
+		answer, err := l.ask(label, question, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if selected, ok := choose(items, answer); ok {
+			return selected, nil
+		}
+		if l.exhausted {
+			return "", missingValue(label)
+		}
+		fmt.Fprintf(l.out, "'%s' is not one of the options\n", answer)
+	}
+}
+
+func (l *LinePrompter) MultiSelect(label string, items []string, defaults []string) ([]string, error) {
+	fmt.Fprintln(l.out, label)
+	for i, item := range items {
+		fmt.Fprintf(l.out, "  %d) %s\n", i+1, item)
+	}
+
+	question := "Enter numbers separated by commas: "
+	if len(defaults) > 0 {
+		question = fmt.Sprintf("Enter numbers separated by commas [%s]: ", strings.Join(defaults, ", "))
+	}
+	for {
+		fmt.Fprint(l.out, question)
+		answer, err := l.readLine()
+		if errors.Is(err, io.EOF) || (err == nil && answer == "") {
+			return defaults, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var selected []string
+		valid := true
+		for _, part := range strings.Split(answer, ",") {
+			item, ok := choose(items, strings.TrimSpace(part))
+			if !ok {
+				fmt.Fprintf(l.out, "'%s' is not one of the options\n", strings.TrimSpace(part))
+				valid = false
+				break
+			}
+			selected = append(selected, item)
+		}
+		if valid {
+			return selected, nil
+		}
+		if l.exhausted {
+			return nil, missingValue(label)
+		}
+	}
+}
+
+func (l *LinePrompter) Input(label string, validate func(string) error, defaultValue string) (string, error) {
+	question := label + ": "
+	if defaultValue != "" {
+		question = fmt.Sprintf("%s [%s]: ", label, defaultValue)
+	}
+	for {
+		answer, err := l.ask(label, question, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(answer); err != nil {
+			if l.exhausted {
+				return "", err
+			}
+			fmt.Fprintf(l.out, "%v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+func (l *LinePrompter) Confirm(label string) (bool, error) {
+	for {
+		answer, err := l.ask(label, label+" [y/N]: ", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			return false, nil
+		}
+		if l.exhausted {
+			return false, missingValue(label)
+		}
+		fmt.Fprintln(l.out, "Please answer y or n")
+	}
+}
@@ -1,79 +1,152 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/sso"
-	"github.com/charmbracelet/huh"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
 )
 
+// ErrMissingValue is returned instead of prompting when running non-interactively
+var ErrMissingValue = errors.New("missing required value")
+
+// interactive controls whether new prompts may render forms, see SetInteractive
+var interactive = true
+
+// SetInteractive enables or disables prompting for prompt handlers created afterwards
+func SetInteractive(enabled bool) {
+	interactive = enabled
+}
+
+// Prompter asks the user for values. FormPrompter draws forms in a terminal, LinePrompter reads
+// plain lines, so answers can be piped in or scripted in tests.
+type Prompter interface {
+	Select(label string, items []string, filtering bool, defaultValue string) (string, error)
+	MultiSelect(label string, items []string, defaults []string) ([]string, error)
+	Input(label string, validate func(string) error, defaultValue string) (string, error)
+	Confirm(label string) (bool, error)
+}
+
+// prompter is the backend of new prompt handlers, see SetPrompter
+var prompter Prompter = FormPrompter{}
+
+// SetPrompter sets the backend used by prompt handlers created afterwards
+func SetPrompter(p Prompter) {
+	prompter = p
+}
+
 // Prompt handles user interactions
-type Prompt struct{}
+type Prompt struct {
+	interactive bool
+	prompter    Prompter
+}
 
 // NewPrompt creates a new prompt handler
 func NewPrompt() *Prompt {
-	return &Prompt{}
+	return &Prompt{interactive: interactive, prompter: prompter}
+}
+
+// NewPromptWith creates an interactive prompt handler asking through p, e.g. a LinePrompter
+// reading scripted answers
+func NewPromptWith(p Prompter) *Prompt {
+	return &Prompt{interactive: true, prompter: p}
+}
+
+// Interactive reports whether the prompt may ask the user for input
+func (p *Prompt) Interactive() bool {
+	return p.interactive
+}
+
+func missingValue(label string) error {
+	return fmt.Errorf("%w: %s", ErrMissingValue, label)
 }
 
-// Select prompts the user to select from a list of items
-func (p *Prompt) Select(label string, items []string) (string, error) {
+// Select prompts the user to select from a list of items, optionally preselecting a default
+func (p *Prompt) Select(label string, items []string, defaultValue ...string) (string, error) {
+	return p.runSelect(label, items, false, defaultValue...)
+}
+
+// SelectFilterable prompts the user to select from a list of items with type-to-filter enabled
+func (p *Prompt) SelectFilterable(label string, items []string, defaultValue ...string) (string, error) {
+	return p.runSelect(label, items, true, defaultValue...)
+}
+
+func (p *Prompt) runSelect(label string, items []string, filtering bool, defaultValue ...string) (string, error) {
 	var selected string
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title(label).
-				Options(huh.NewOptions(items...)...).
-				Value(&selected),
-		),
-	)
-
-	if err := form.Run(); err != nil {
-		return "", fmt.Errorf("select failed: %w", err)
+
+	// Preselect the default value if provided
+	if len(defaultValue) > 0 {
+		selected = defaultValue[0]
+	}
+
+	// Without a terminal only a valid default can be used
+	if !p.interactive {
+		for _, item := range items {
+			if selected != "" && item == selected {
+				return selected, nil
+			}
+		}
+		return "", missingValue(label)
 	}
-	return selected, nil
+
+	return p.prompter.Select(label, items, filtering, selected)
+}
+
+// MultiSelect prompts the user to pick any number of items, starting with the defaults picked.
+// Without a terminal the defaults are returned as if the user accepted them.
+func (p *Prompt) MultiSelect(label string, items []string, defaults ...string) ([]string, error) {
+	selected := append([]string(nil), defaults...)
+	if !p.interactive {
+		return selected, nil
+	}
+	return p.prompter.MultiSelect(label, items, selected)
 }
 
 // Input prompts the user for input
 func (p *Prompt) Input(label string, validate func(string) error, defaultValue ...string) (string, error) {
 	var result string
-	
+
 	// Set default value if provided
 	if len(defaultValue) > 0 && defaultValue[0] != "" {
 		result = defaultValue[0]
 	}
-	
-	input := huh.NewInput().
-		Title(label).
-		Validate(func(s string) error {
-			if validate != nil {
-				return validate(s)
-			}
-			return nil
-		}).
-		Value(&result)
 
-	form := huh.NewForm(
-		huh.NewGroup(input),
-	)
+	// Without a terminal the default is used as if the user accepted it
+	if !p.interactive {
+		if result == "" {
+			return "", missingValue(label)
+		}
+		if validate != nil {
+			if err := validate(result); err != nil {
+				return "", err
+			}
+		}
+		return result, nil
+	}
 
-	if err := form.Run(); err != nil {
-		return "", fmt.Errorf("input failed: %w", err)
+	if validate == nil {
+		validate = func(string) error { return nil }
 	}
-	return result, nil
+	return p.prompter.Input(label, validate, result)
 }
 
-// SelectAccount prompts the user to select an AWS account
-func (p *Prompt) SelectAccount(accounts *sso.ListAccountsOutput) (string, string, error) {
+// SelectAccount prompts the user to select an AWS account, optionally preselecting a default account ID
+func (p *Prompt) SelectAccount(accounts []ssotypes.AccountInfo, defaultAccountID ...string) (string, string, error) {
 	accountMap := make(map[string]string)
-	accountNames := make([]string, 0, len(accounts.AccountList))
+	accountNames := make([]string, 0, len(accounts))
+	var defaultDisplay string
 
-	for _, acc := range accounts.AccountList {
+	for _, acc := range accounts {
 		display := fmt.Sprintf("%s (%s)", *acc.AccountName, *acc.AccountId)
 		accountNames = append(accountNames, display)
 		accountMap[display] = *acc.AccountId
+		if len(defaultAccountID) > 0 && *acc.AccountId == defaultAccountID[0] {
+			defaultDisplay = display
+		}
 	}
 
-	selected, err := p.Select("Select an AWS account", accountNames)
+	selected, err := p.SelectFilterable("Select an AWS account", accountNames, defaultDisplay)
 	if err != nil {
 		return "", "", err
 	}
@@ -81,30 +154,19 @@ func (p *Prompt) SelectAccount(accounts *sso.ListAccountsOutput) (string, string
 	return selected, accountMap[selected], nil
 }
 
-// SelectRole prompts the user to select a role
-func (p *Prompt) SelectRole(roles *sso.ListAccountRolesOutput) (string, error) {
+// SelectRole prompts the user to select a role, optionally preselecting a default role
+func (p *Prompt) SelectRole(roles *sso.ListAccountRolesOutput, defaultRole ...string) (string, error) {
 	roleNames := make([]string, 0, len(roles.RoleList))
 	for _, role := range roles.RoleList {
 		roleNames = append(roleNames, *role.RoleName)
 	}
-	return p.Select("Select a role", roleNames)
+	return p.SelectFilterable("Select a role", roleNames, defaultRole...)
 }
 
 // Confirm prompts the user for a yes/no confirmation
 func (p *Prompt) Confirm(label string) (bool, error) {
-	var confirm bool
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewConfirm().
-				Title(label).
-				Affirmative("Yes!").
-				Negative("No.").
-				Value(&confirm),
-		),
-	)
-
-	if err := form.Run(); err != nil {
-		return false, fmt.Errorf("confirmation failed: %w", err)
+	if !p.interactive {
+		return false, missingValue(label)
 	}
-	return confirm, nil
+	return p.prompter.Confirm(label)
 }
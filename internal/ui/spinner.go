@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/b3nk3/bifrost/internal/ui/output"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/mattn/go-isatty"
+)
+
+// WithSpinner runs fn while a spinner labelled label (e.g. "Listing SSM instances…") animates,
+// clearing it once fn returns. It is drawn on stderr so piped output stays clean, and without an
+// interactive terminal fn simply runs.
+// fn must not print, its output would be drawn over.
+func WithSpinner(label string, fn func() error) error {
+	if !interactive || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return fn()
+	}
+
+	// Braille dots need the same UTF-8 support as emoji
+	frames := spinner.MiniDot
+	if !output.Emoji() {
+		frames = spinner.Line
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(frames.FPS)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s", frames.Frames[i%len(frames.Frames)], label)
+			select {
+			case <-done:
+				// Return to the start of the line and clear it for whatever prints next
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	err := fn()
+	close(done)
+	<-stopped
+	return err
+}